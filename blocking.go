@@ -0,0 +1,148 @@
+package csort
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultPollInterval poll 参数非法时使用的默认轮询间隔
+	defaultPollInterval = time.Millisecond
+	// maxPollBackoff 轮询间隔相对初始 poll 的最大退避倍数
+	maxPollBackoff = 16
+)
+
+// ==================== ZPopMinWait ====================
+
+// ZPopMinWait 以轮询方式弹出分数最低的成员，最多等待 maxWait
+// 每次轮询失败后间隔翻倍（最多为 poll 的 16 倍），超时返回 ok=false；
+// 适用于可以接受轮询延迟、不需要通知机制的简单阻塞场景
+func (c *CacheZSort) ZPopMinWait(key string, maxWait time.Duration, poll time.Duration) (ScoreMember, bool) {
+	if poll <= 0 {
+		poll = defaultPollInterval
+	}
+	deadline := time.Now().Add(maxWait)
+	interval := poll
+
+	for {
+		if popped := c.ZPopMin(key, 1); len(popped) > 0 {
+			return popped[0], true
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return ScoreMember{}, false
+		}
+		time.Sleep(min(interval, remaining))
+
+		if interval < poll*maxPollBackoff {
+			interval *= 2
+		}
+	}
+}
+
+// ==================== BZPopMin / BZPopMax ====================
+
+// keyNotifier 按 key 登记阻塞弹出的等待者，写入成员时唤醒对应 key 上的等待者
+// 每个等待者持有一个容量为 1 的通道，可同时登记在多个 key 上
+type keyNotifier struct {
+	mu      sync.Mutex
+	waiting atomic.Int64 // 当前登记的等待者数量，为 0 时写入方跳过唤醒
+	waiters map[string]map[chan struct{}]struct{}
+}
+
+// register 在 keys 上登记一个等待者，返回其唤醒通道
+func (n *keyNotifier) register(keys []string) chan struct{} {
+	ch := make(chan struct{}, 1)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.waiters == nil {
+		n.waiters = make(map[string]map[chan struct{}]struct{})
+	}
+	for _, key := range keys {
+		if n.waiters[key] == nil {
+			n.waiters[key] = make(map[chan struct{}]struct{})
+		}
+		n.waiters[key][ch] = struct{}{}
+	}
+	n.waiting.Add(1)
+	return ch
+}
+
+// unregister 注销等待者
+func (n *keyNotifier) unregister(keys []string, ch chan struct{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, key := range keys {
+		delete(n.waiters[key], ch)
+		if len(n.waiters[key]) == 0 {
+			delete(n.waiters, key)
+		}
+	}
+	n.waiting.Add(-1)
+}
+
+// hasWaiters 判断是否存在等待者
+// 写入方须在释放集合写锁之前调用，保证与等待者"登记后再检查集合"的顺序不会错过唤醒
+func (n *keyNotifier) hasWaiters() bool {
+	return n.waiting.Load() > 0
+}
+
+// notify 唤醒 key 上的所有等待者（wake 为 false 时直接返回）
+func (n *keyNotifier) notify(key string, wake bool) {
+	if !wake {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.waiters[key] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// BZPopMin 从 keys 中第一个非空的集合弹出分数最低的成员
+// 所有集合都为空时阻塞，直到 ZAdd、ZIncrBy 等写入使其中之一非空、超过 timeout 或 ctx 被取消；
+// timeout <= 0 表示不设超时（仅受 ctx 控制）。超时或取消时返回 ok=false
+func (c *CacheZSort) BZPopMin(ctx context.Context, keys []string, timeout time.Duration) (key string, sm ScoreMember, ok bool) {
+	return c.bzpop(ctx, keys, timeout, c.ZPopMin)
+}
+
+// BZPopMax 从 keys 中第一个非空的集合弹出分数最高的成员，阻塞语义同 BZPopMin
+func (c *CacheZSort) BZPopMax(ctx context.Context, keys []string, timeout time.Duration) (key string, sm ScoreMember, ok bool) {
+	return c.bzpop(ctx, keys, timeout, c.ZPopMax)
+}
+
+// bzpop 阻塞弹出的公共实现
+func (c *CacheZSort) bzpop(ctx context.Context, keys []string, timeout time.Duration, pop func(string, int) []ScoreMember) (string, ScoreMember, bool) {
+	// 先登记再检查集合，避免检查与等待之间的写入被错过
+	wakeup := c.notifier.register(keys)
+	defer c.notifier.unregister(keys, wakeup)
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		for _, key := range keys {
+			if popped := pop(key, 1); len(popped) > 0 {
+				return key, popped[0], true
+			}
+		}
+
+		select {
+		case <-wakeup:
+		case <-expired:
+			return "", ScoreMember{}, false
+		case <-ctx.Done():
+			return "", ScoreMember{}, false
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package csort
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestZPopMinWait 测试轮询等待弹出
+func TestZPopMinWait(t *testing.T) {
+	cache := New()
+
+	// 已有数据时立即返回
+	cache.ZAddInt64("queue", "ready", 1)
+	sm, ok := cache.ZPopMinWait("queue", time.Second, time.Millisecond)
+	if !ok || sm.Member != "ready" {
+		t.Fatalf("ZPopMinWait = %v, %v, want ready, true", sm.Member, ok)
+	}
+
+	// 等待过程中出现元素
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cache.ZAddInt64("queue", "late", 2)
+	}()
+	sm, ok = cache.ZPopMinWait("queue", 2*time.Second, time.Millisecond)
+	if !ok || sm.Member != "late" {
+		t.Fatalf("ZPopMinWait = %v, %v, want late, true", sm.Member, ok)
+	}
+
+	// 超时
+	start := time.Now()
+	_, ok = cache.ZPopMinWait("queue", 30*time.Millisecond, 5*time.Millisecond)
+	if ok {
+		t.Error("ZPopMinWait should time out on an empty key")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("ZPopMinWait returned after %v, want at least 30ms", elapsed)
+	}
+}
+
+// TestBZPopMin 测试阻塞弹出：立即返回、被唤醒、超时与取消
+func TestBZPopMin(t *testing.T) {
+	cache := New()
+	ctx := context.Background()
+
+	// 已有数据时立即返回，跳过空 key
+	cache.ZAddInt64("q2", "b", 2)
+	cache.ZAddInt64("q2", "a", 1)
+	key, sm, ok := cache.BZPopMin(ctx, []string{"q1", "q2"}, time.Second)
+	if !ok || key != "q2" || sm.Member != "a" {
+		t.Fatalf("BZPopMin = %s, %s, %v, want q2, a, true", key, sm.Member, ok)
+	}
+	key, sm, ok = cache.BZPopMax(ctx, []string{"q1", "q2"}, time.Second)
+	if !ok || key != "q2" || sm.Member != "b" {
+		t.Fatalf("BZPopMax = %s, %s, %v, want q2, b, true", key, sm.Member, ok)
+	}
+
+	// 其他协程写入后被唤醒
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cache.ZAddInt64("q1", "late", 5)
+	}()
+	key, sm, ok = cache.BZPopMin(ctx, []string{"q1", "q2"}, 0)
+	if !ok || key != "q1" || sm.Member != "late" {
+		t.Fatalf("BZPopMin = %s, %s, %v, want q1, late, true", key, sm.Member, ok)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cache.ZIncrBy("q2", "incr", big.NewRat(3, 1))
+	}()
+	key, sm, ok = cache.BZPopMax(ctx, []string{"q1", "q2"}, 2*time.Second)
+	if !ok || key != "q2" || sm.Member != "incr" {
+		t.Fatalf("BZPopMax = %s, %s, %v, want q2, incr, true", key, sm.Member, ok)
+	}
+
+	// 超时
+	start := time.Now()
+	if _, _, ok := cache.BZPopMin(ctx, []string{"q1"}, 30*time.Millisecond); ok {
+		t.Error("BZPopMin should time out on an empty key")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("BZPopMin returned after %v, want at least 30ms", elapsed)
+	}
+
+	// 取消
+	cctx, cancel := context.WithCancel(ctx)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if _, _, ok := cache.BZPopMax(cctx, []string{"q1"}, 0); ok {
+		t.Error("BZPopMax should return false after cancellation")
+	}
+	if cache.notifier.hasWaiters() {
+		t.Error("waiters should be unregistered after returning")
+	}
+}
+
+// TestBZPopMinConcurrent 测试多个消费者各自取到不同的成员
+func TestBZPopMinConcurrent(t *testing.T) {
+	cache := New()
+	const n = 50
+
+	results := make(chan string, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, sm, ok := cache.BZPopMin(context.Background(), []string{"jobs"}, 5*time.Second)
+			if !ok {
+				results <- ""
+				return
+			}
+			results <- sm.Member
+		}()
+	}
+	for i := 0; i < n; i++ {
+		cache.ZAddInt64("jobs", string(rune('A'+i)), int64(i))
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		member := <-results
+		if member == "" {
+			t.Fatal("consumer timed out")
+		}
+		if seen[member] {
+			t.Fatalf("member %s popped twice", member)
+		}
+		seen[member] = true
+	}
+}
+
+// TestBZPopMinWokenByStore 测试整体替换 key 的操作会唤醒阻塞的弹出方
+func TestBZPopMinWokenByStore(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("src", "a", 1)
+
+	stores := map[string]func(){
+		"ZUnionStore": func() { cache.ZUnionStore("q", []string{"src"}, nil, "") },
+		"ZCopy":       func() { cache.ZCopy("src", "q", true) },
+		"ZRangeStore": func() { cache.ZRangeStore("q", "src", 0, -1, false) },
+		"ImportJSON":  func() { cache.ImportJSON("q", []byte(`[{"member":"a","score":"1"}]`)) },
+	}
+	for name, store := range stores {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			store()
+		}()
+		if _, sm, ok := cache.BZPopMin(context.Background(), []string{"q"}, 2*time.Second); !ok || sm.Member != "a" {
+			t.Errorf("BZPopMin after %s = %s, %v, want a, true", name, sm.Member, ok)
+		}
+	}
+}
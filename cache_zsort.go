@@ -1,81 +1,508 @@
 package csort
 
 import (
+	"bytes"
+	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // ZSet 表示一个有序集合
+// 每个集合只有一把锁，即跳表自身的 sl.mu：单步读写直接调用跳表的公开方法，
+// 需要多步保持原子性的操作持有 sl.mu 后调用跳表的内部（无锁）方法；下列字段同样由 sl.mu 保护
 type ZSet struct {
 	sl *SkipList
-	mu sync.RWMutex
+	id uint64 // 创建顺序编号，同时锁定多个集合时一律按编号升序加锁（见 lockZSets）
+
+	removed    bool                // 已被移出 sets（自动清理、Del、ZDrainSnapshot 等），读写方需重新获取集合
+	tombstones map[string]*big.Rat // 软删除的成员及其删除前的分数
+	expireAt   atomic.Int64        // 过期时间（UnixNano），0 表示永不过期
 }
 
-// newZSet 创建新的有序集合
-func newZSet() *ZSet {
+// newZSet 按实例配置创建新的有序集合
+func (c *CacheZSort) newZSet() *ZSet {
+	sl := NewSkipListWithTieBreak(c.maxLevel, c.p, c.tieBreak)
+	sl.now = c.now
+	sl.fold = c.memberFold()
 	return &ZSet{
-		sl: NewSkipList(),
+		sl: sl,
+		id: c.nextSetID.Add(1),
+	}
+}
+
+// memberFold 返回集合使用的成员名归一化函数，区分大小写时为 nil（见 WithCaseInsensitiveMembers）
+func (c *CacheZSort) memberFold() func(string) string {
+	if c.caseInsensitive {
+		return strings.ToLower
+	}
+	return nil
+}
+
+// memberKey 返回成员的归一化名称，与集合 memberMap 的键一致，供跨集合比较成员使用
+func (c *CacheZSort) memberKey(member string) string {
+	if c.caseInsensitive {
+		return strings.ToLower(member)
+	}
+	return member
+}
+
+// memberLess 按集合内的规则比较分数相同的两个成员：先归一化，再按 WithTieBreak 或成员名升序
+func (c *CacheZSort) memberLess(a, b string) bool {
+	a, b = c.memberKey(a), c.memberKey(b)
+	if c.tieBreak == nil {
+		return a < b
 	}
+	return c.tieBreak(a, b)
 }
 
 // CacheZSort 内存排序组件主结构
 type CacheZSort struct {
-	sets map[string]*ZSet
-	mu   sync.RWMutex
+	sets      map[string]*ZSet
+	mu        sync.RWMutex
+	nextSetID atomic.Uint64 // 最近分配的集合编号，见 ZSet.id
+
+	losslessStrings bool             // 字符串分数使用 RatString 输出
+	scorePrecision  atomic.Int32     // 字符串分数保留的小数位数，见 SetScorePrecision
+	now             func() time.Time // 时钟，可通过 WithClock 注入
+
+	maxLevel int                    // 新建集合的跳表最大层级，见 NewWithOptions
+	p        float64                // 新建集合的跳表节点晋升概率
+	tieBreak func(a, b string) bool // 新建集合中分数相同时成员的排序规则，见 WithTieBreak
+
+	caseInsensitive bool // 成员名不区分大小写，见 WithCaseInsensitiveMembers
+
+	// 读多写少模式：sets 的只读副本，写入 key 时整体复制后原子替换
+	readMostly bool
+
+	maxKeys     int  // key 数量上限，0 表示不限制
+	autoCleanup bool // 集合变空时自动删除 key
+	softDelete  bool // ZRem 仅标记删除，可通过 ZUndelete 恢复
+
+	immutableScores bool // 已存在成员的分数不可修改
+
+	writeThrough atomic.Pointer[func(key, member string, score *big.Rat) error] // 写穿透回调
+	loader       atomic.Pointer[func(key string) ([]ScoreMember, bool)]         // 读穿透加载回调
+
+	notifier keyNotifier // 阻塞弹出的等待者登记与唤醒
+	events   eventHub    // 成员变更事件的订阅者，见 Subscribe
+
+	stopSweeper chan struct{} // 关闭时停止后台过期清理，见 NewWithExpiry
+	closeOnce   sync.Once
+
+	detectDuplicates bool        // 范围查询时检测重复成员
+	errorHook        func(error) // 内部异常上报回调
+	setsView         atomic.Pointer[map[string]*ZSet]
 }
 
 // New 创建新的 CacheZSort 实例
-func New() *CacheZSort {
-	return &CacheZSort{
-		sets: make(map[string]*ZSet),
+func New(opts ...Option) *CacheZSort {
+	c := &CacheZSort{
+		sets:     make(map[string]*ZSet),
+		now:      time.Now,
+		maxLevel: defaultMaxLevel,
+		p:        defaultP,
+	}
+	c.scorePrecision.Store(defaultScorePrecision)
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.publishSetsLocked()
+	return c
+}
+
+// publishSetsLocked 在读多写少模式下发布 sets 的新副本（调用者必须持有写锁或独占实例）
+func (c *CacheZSort) publishSetsLocked() {
+	if !c.readMostly {
+		return
 	}
+	view := make(map[string]*ZSet, len(c.sets))
+	for key, set := range c.sets {
+		view[key] = set
+	}
+	c.setsView.Store(&view)
 }
 
 // getOrCreateZSet 获取或创建指定的 ZSet
-func (c *CacheZSort) getOrCreateZSet(key string) *ZSet {
-	c.mu.RLock()
-	if set, ok := c.sets[key]; ok {
-		c.mu.RUnlock()
-		return set
+// 配置了 WithMaxKeysStrict 且 key 数量已达上限时返回 ErrTooManyKeys
+func (c *CacheZSort) getOrCreateZSet(key string) (*ZSet, error) {
+	if set := c.getZSet(key); set != nil {
+		return set, nil
 	}
-	c.mu.RUnlock()
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// 双重检查
+	// 双重检查；已过期的集合视为不存在，由新集合替换
 	if set, ok := c.sets[key]; ok {
-		return set
+		if !c.expired(set) {
+			return set, nil
+		}
+		c.dropSetLocked(key, set)
 	}
 
-	set := newZSet()
+	if c.maxKeys > 0 && len(c.sets) >= c.maxKeys {
+		return nil, ErrTooManyKeys
+	}
+
+	set := c.newZSet()
 	c.sets[key] = set
-	return set
+	c.publishSetsLocked()
+	return set, nil
+}
+
+// lockZSetForWrite 获取或创建指定的 ZSet 并持有其写锁返回
+// 若拿到锁时集合已被自动清理移除，则重新获取，避免写入已脱离 sets 的集合
+func (c *CacheZSort) lockZSetForWrite(key string) (*ZSet, error) {
+	for {
+		set, err := c.getOrCreateZSet(key)
+		if err != nil {
+			return nil, err
+		}
+		set.sl.mu.Lock()
+		if !set.removed && !c.expired(set) {
+			return set, nil
+		}
+		set.sl.mu.Unlock()
+	}
+}
+
+// lockExistingZSet 获取并写锁定已存在的集合，key 不存在或已过期时返回 nil
+// 加锁后发现集合已被移出 sets（自动清理、Del、ZDrainSnapshot 等）时重新获取，
+// 避免删除、弹出等操作作用于已脱离 key 的集合而静默丢失
+func (c *CacheZSort) lockExistingZSet(key string) *ZSet {
+	for {
+		set := c.getZSet(key)
+		if set == nil {
+			return nil
+		}
+		set.sl.mu.Lock()
+		if !set.removed && !c.expired(set) {
+			return set
+		}
+		set.sl.mu.Unlock()
+	}
+}
+
+// markRemoved 在集合写锁内标记集合已移出 sets，使持有旧指针的读写方重新获取集合
+func (set *ZSet) markRemoved() {
+	set.sl.mu.Lock()
+	set.removed = true
+	set.sl.mu.Unlock()
+}
+
+// removeIfEmpty 开启自动清理时删除已变空的集合（调用者不得持有该集合的锁）
+func (c *CacheZSort) removeIfEmpty(key string, set *ZSet) {
+	if !c.autoCleanup {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sets[key] != set {
+		return
+	}
+
+	set.sl.mu.Lock()
+	defer set.sl.mu.Unlock()
+	if set.sl.Len() > 0 || len(set.tombstones) > 0 {
+		return
+	}
+	set.removed = true
+	delete(c.sets, key)
+	c.publishSetsLocked()
 }
 
 // getZSet 获取指定的 ZSet，如果不存在返回 nil
+// 读多写少模式下直接读取只读副本，无需加锁；已过期的集合视为不存在。
+// 设置了 SetLoader 时，不存在的 key 会先尝试通过加载回调填充
 func (c *CacheZSort) getZSet(key string) *ZSet {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.sets[key]
+	var set *ZSet
+	if c.readMostly {
+		set = (*c.setsView.Load())[key]
+	} else {
+		c.mu.RLock()
+		set = c.sets[key]
+		c.mu.RUnlock()
+	}
+	if set != nil && c.expired(set) {
+		set = nil
+	}
+	if set == nil {
+		return c.loadZSet(key)
+	}
+	return set
 }
 
 // delZSet 删除指定的 ZSet
 func (c *CacheZSort) delZSet(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.sets, key)
+	if set, ok := c.sets[key]; ok {
+		set.markRemoved()
+		delete(c.sets, key)
+	}
+	c.publishSetsLocked()
+}
+
+// reportError 通过错误回调上报内部异常，未设置回调时忽略
+func (c *CacheZSort) reportError(err error) {
+	if c.errorHook != nil {
+		c.errorHook(err)
+	}
+}
+
+// checkDuplicates 在开启重复检测时检查范围查询结果中是否有重复成员
+func (c *CacheZSort) checkDuplicates(key string, result []ScoreMember) {
+	if !c.detectDuplicates {
+		return
+	}
+	seen := make(map[string]struct{}, len(result))
+	for _, sm := range result {
+		if _, ok := seen[sm.Member]; ok {
+			c.reportError(fmt.Errorf("%w: key %q member %q", ErrDuplicateMember, key, sm.Member))
+			continue
+		}
+		seen[sm.Member] = struct{}{}
+	}
 }
 
 // ==================== ZAdd ====================
 
 // ZAdd 添加成员到有序集合
+// 因 key 数量达到上限而无法创建集合时返回 false
 func (c *CacheZSort) ZAdd(key, member string, score *big.Rat) bool {
-	set := c.getOrCreateZSet(key)
-	set.mu.Lock()
-	defer set.mu.Unlock()
-	set.sl.insertInternal(member, score)
-	return true
+	return c.zadd(key, member, score) == nil
+}
+
+// zadd 添加成员到有序集合，返回创建集合时的错误
+func (c *CacheZSort) zadd(key, member string, score *big.Rat) error {
+	return c.zaddNode(key, member, score, nil, nil)
+}
+
+// zaddNode 添加成员，持有写锁时在写入前调用 before、写入后对成员所在节点调用 update（均可为 nil）
+// before 返回 false 时放弃写入（不视为错误），用于实现条件写入
+func (c *CacheZSort) zaddNode(key, member string, score *big.Rat, before func(*SkipList) bool, update func(*skipNode)) error {
+	set, err := c.lockZSetForWrite(key)
+	if err != nil {
+		return err
+	}
+	if before != nil && !before(set.sl) {
+		set.sl.mu.Unlock()
+		c.removeIfEmpty(key, set)
+		return nil
+	}
+	return c.zaddLocked(key, set, member, score, update)
+}
+
+// zaddLocked 在已持有集合写锁时写入成员，并负责释放写锁、唤醒阻塞弹出、写穿透及发布事件
+func (c *CacheZSort) zaddLocked(key string, set *ZSet, member string, score *big.Rat, update func(*skipNode)) error {
+	if err := c.checkImmutable(set, member, score); err != nil {
+		set.sl.mu.Unlock()
+		return err
+	}
+	old := set.scoreForRollback(member, c.hasWriteThrough())
+	prev := set.sl.memberMap[set.sl.key(member)]
+	node := set.sl.insertInternal(member, score)
+	if update != nil {
+		update(node)
+	}
+	old.wrote(node)
+	var events []Event
+	if c.events.active() {
+		if ev, ok := memberEvent(key, member, prev, node); ok {
+			events = append(events, ev)
+		}
+	}
+	wake := c.notifier.hasWaiters()
+	set.sl.mu.Unlock()
+
+	// 写穿透失败时本次写入会被回滚，确认成功后才唤醒阻塞弹出并发布事件
+	if err := c.applyWriteThrough(key, member, score, set, old); err != nil {
+		return err
+	}
+	c.notifier.notify(key, wake)
+	c.events.publish(events)
+	return nil
+}
+
+// checkImmutable 只追加模式下检查写入是否会修改已存在成员的分数（调用者必须持有集合写锁）
+func (c *CacheZSort) checkImmutable(set *ZSet, member string, score *big.Rat) error {
+	if !c.immutableScores {
+		return nil
+	}
+	if node, exists := set.sl.memberMap[set.sl.key(member)]; exists && compare(node.score, score) != 0 {
+		return fmt.Errorf("%w: member %q", ErrScoreImmutable, member)
+	}
+	return nil
+}
+
+// checkImmutableBatch 在写入前检查整批成员是否违反只追加约束（调用者必须持有集合写锁）
+// 同一批次中先出现的新成员视为已写入，之后以不同分数再次出现同样违反约束
+func (c *CacheZSort) checkImmutableBatch(set *ZSet, members []ScoreMember) error {
+	if !c.immutableScores {
+		return nil
+	}
+	pending := make(map[string]*big.Rat, len(members))
+	for _, sm := range members {
+		if err := c.checkImmutable(set, sm.Member, sm.Score); err != nil {
+			return err
+		}
+		key := set.sl.key(sm.Member)
+		if first, ok := pending[key]; ok && compare(first, sm.Score) != 0 {
+			return fmt.Errorf("%w: member %q", ErrScoreImmutable, sm.Member)
+		} else if !ok {
+			pending[key] = sm.Score
+		}
+	}
+	return nil
+}
+
+// ZAddWithValue 添加成员并为其附带一段数据（如显示名称、时间戳）
+// value 会被复制保存；之后仅更新分数的 ZAdd、ZIncrBy 会保留该数据，成员被删除时数据一并清除
+func (c *CacheZSort) ZAddWithValue(key, member string, score *big.Rat, value []byte) bool {
+	value = bytes.Clone(value)
+	return c.zaddNode(key, member, score, nil, func(node *skipNode) {
+		node.value = value
+	}) == nil
+}
+
+// ZAddRankChanged 添加或更新成员，并报告该成员的排名是否因此改变
+// newRank 为写入后的正序排名（从0开始）；新增成员视为排名改变，分数未变或移动后排名不变时返回 false。
+// 写入失败（如超出 key 数量限制）时返回 false, -1
+func (c *CacheZSort) ZAddRankChanged(key, member string, score *big.Rat) (rankChanged bool, newRank int) {
+	var sl *SkipList
+	oldRank := -1
+	err := c.zaddNode(key, member, score, func(list *SkipList) bool {
+		sl = list
+		if node, exists := sl.memberMap[sl.key(member)]; exists {
+			oldRank = sl.getRankInternal(member, node.score) - 1
+		}
+		return true
+	}, func(node *skipNode) {
+		newRank = sl.getRankInternal(member, node.score) - 1
+	})
+	if err != nil {
+		return false, -1
+	}
+	return newRank != oldRank, newRank
+}
+
+// ZGetValue 获取成员附带数据的副本；成员不存在时返回 false，存在但没有附带数据时返回 nil, true
+func (c *CacheZSort) ZGetValue(key, member string) ([]byte, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil, false
+	}
+	return set.sl.GetValue(member)
+}
+
+// ZAddGT 成员不存在时添加，存在时仅当新分数严格大于当前分数才更新
+// 比较与写入在同一次加锁内完成；返回是否发生了写入
+func (c *CacheZSort) ZAddGT(key, member string, score *big.Rat) bool {
+	return c.zaddCompare(key, member, score, 1)
+}
+
+// ZAddLT 成员不存在时添加，存在时仅当新分数严格小于当前分数才更新
+// 比较与写入在同一次加锁内完成；返回是否发生了写入
+func (c *CacheZSort) ZAddLT(key, member string, score *big.Rat) bool {
+	return c.zaddCompare(key, member, score, -1)
+}
+
+// ZAddNX 仅当成员不存在时添加，已存在的成员（无论分数是否相同）保持不变
+// 检查与写入在同一次加锁内完成；返回是否添加了成员
+func (c *CacheZSort) ZAddNX(key, member string, score *big.Rat) bool {
+	written := false
+	err := c.zaddNode(key, member, score, func(sl *SkipList) bool {
+		_, exists := sl.memberMap[sl.key(member)]
+		written = !exists
+		return written
+	}, nil)
+	return err == nil && written
+}
+
+// ZAddXX 仅当成员已存在时更新分数，不会添加新成员，也不会创建不存在的 key
+// 检查与写入在同一次加锁内完成；成员存在时返回 true（新分数与原分数相同也视为生效）
+func (c *CacheZSort) ZAddXX(key, member string, score *big.Rat) bool {
+	if c.getZSet(key) == nil {
+		return false
+	}
+	written := false
+	err := c.zaddNode(key, member, score, func(sl *SkipList) bool {
+		_, written = sl.memberMap[sl.key(member)]
+		return written
+	}, nil)
+	return err == nil && written
+}
+
+// zaddCompare 仅当成员不存在或 compare(score, 当前分数) == want 时写入
+func (c *CacheZSort) zaddCompare(key, member string, score *big.Rat, want int) bool {
+	written := false
+	err := c.zaddNode(key, member, score, func(sl *SkipList) bool {
+		node, exists := sl.memberMap[sl.key(member)]
+		written = !exists || compare(score, node.score) == want
+		return written
+	}, nil)
+	return err == nil && written
+}
+
+// ZUpdateScore 在集合写锁内原子地读取并改写成员的分数
+// fn 接收成员当前分数的副本（不存在时为 nil）及是否存在，返回新分数以及是否应用：
+// 返回 false 时集合保持不变；返回 nil 分数且为 true 时删除该成员。
+// 返回值为调用后成员的分数及是否存在。fn 在持有写锁时执行，不得再访问同一个 key。
+// key 不存在时先以 (nil, false) 调用 fn，只有确实要写入时才创建集合；
+// 若加锁前该成员恰好被其他协程写入，fn 会按最新状态再调用一次
+func (c *CacheZSort) ZUpdateScore(key, member string, fn func(old *big.Rat, exists bool) (*big.Rat, bool)) (*big.Rat, bool) {
+	var (
+		score   *big.Rat
+		apply   bool
+		decided bool
+	)
+	if c.getZSet(key) == nil {
+		if score, apply = fn(nil, false); !apply || score == nil {
+			return nil, false
+		}
+		decided = true
+	}
+
+	set, err := c.lockZSetForWrite(key)
+	if err != nil {
+		return nil, false
+	}
+
+	var old *big.Rat
+	prev, exists := set.sl.memberMap[set.sl.key(member)]
+	if exists {
+		old = new(big.Rat).Set(prev.score)
+	}
+	if !decided || exists {
+		score, apply = fn(old, exists)
+	}
+	if !apply || (score == nil && !exists) {
+		set.sl.mu.Unlock()
+		c.removeIfEmpty(key, set)
+		return old, exists
+	}
+
+	if score == nil {
+		set.deleteMember(member, c.softDelete)
+		set.sl.mu.Unlock()
+		c.removeIfEmpty(key, set)
+		if c.events.active() {
+			c.events.publish(removeEvents(key, []ScoreMember{{Score: prev.score, Member: member}}))
+		}
+		return nil, false
+	}
+
+	score = new(big.Rat).Set(score)
+	if err := c.zaddLocked(key, set, member, score, nil); err != nil {
+		return old, exists
+	}
+	return score, true
 }
 
 // ZAddString 添加成员（分数为字符串格式）
@@ -84,7 +511,10 @@ func (c *CacheZSort) ZAddString(key, member, scoreStr string) (bool, error) {
 	if _, ok := score.SetString(scoreStr); !ok {
 		return false, ErrInvalidScore
 	}
-	return c.ZAdd(key, member, score), nil
+	if err := c.zadd(key, member, score); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // ZAddFloat64 添加成员（分数为 float64）
@@ -99,15 +529,146 @@ func (c *CacheZSort) ZAddInt64(key, member string, score int64) bool {
 
 // ZAddMultiple 添加多个成员
 func (c *CacheZSort) ZAddMultiple(key string, members map[string]*big.Rat) int {
-	set := c.getOrCreateZSet(key)
-	set.mu.Lock()
-	defer set.mu.Unlock()
+	res, _ := c.zaddMultiple(key, scoreMembersOf(members))
+	return res.written
+}
+
+// ZAddCH 批量添加成员，返回发生变化的成员数（新增或分数被修改），与 Redis ZADD CH 一致
+// 分数与已有分数相同的成员不计入
+func (c *CacheZSort) ZAddCH(key string, members map[string]*big.Rat) int {
+	res, _ := c.zaddMultiple(key, scoreMembersOf(members))
+	return res.changed
+}
+
+// ZAddBatch 按顺序批量添加成员，同一成员出现多次时以最后一次为准，返回新增的成员数
+// 可直接写入 All、Range 等返回的结果；Value 非 nil 的条目会同时设置成员附带的数据。
+// 开启 WithImmutableScores 时任一条目会修改已有成员的分数，或同一新成员在批内以不同分数出现时，整批不写入
+func (c *CacheZSort) ZAddBatch(key string, members []ScoreMember) int {
+	res, _ := c.zaddMultiple(key, members)
+	return res.added
+}
+
+// batchResult 批量写入的统计
+type batchResult struct {
+	written int // 写入的条目数
+	changed int // 新增或分数被修改的次数
+	added   int // 新增的成员数
+}
+
+// scoreMembersOf 将成员到分数的映射转换为 ScoreMember 切片
+func scoreMembersOf(members map[string]*big.Rat) []ScoreMember {
+	result := make([]ScoreMember, 0, len(members))
+	for member, score := range members {
+		result = append(result, ScoreMember{Score: score, Member: member})
+	}
+	return result
+}
+
+// zaddMultiple 按顺序添加多个成员，返回写入统计以及创建集合时的错误
+func (c *CacheZSort) zaddMultiple(key string, members []ScoreMember) (batchResult, error) {
+	var res batchResult
+	set, err := c.lockZSetForWrite(key)
+	if err != nil {
+		return res, err
+	}
+	if err := c.checkImmutableBatch(set, members); err != nil {
+		set.sl.mu.Unlock()
+		return res, err
+	}
+
+	observe := c.events.active()
+	var events []Event
+	for _, sm := range members {
+		// 分数相同时 insertInternal 直接返回原节点
+		prev := set.sl.memberMap[set.sl.key(sm.Member)]
+		node := set.sl.insertInternal(sm.Member, sm.Score)
+		if sm.Value != nil {
+			node.value = bytes.Clone(sm.Value)
+		}
+		if prev == nil {
+			res.added++
+		}
+		if node != prev {
+			res.changed++
+		}
+		if observe {
+			if ev, ok := memberEvent(key, sm.Member, prev, node); ok {
+				events = append(events, ev)
+			}
+		}
+		res.written++
+	}
+	wake := c.notifier.hasWaiters()
+	set.sl.mu.Unlock()
+
+	c.notifier.notify(key, wake && res.changed > 0)
+	c.events.publish(events)
+	return res, nil
+}
 
+// ZAddMultipleString 批量添加成员（分数为字符串格式）
+// 先解析全部分数，任一分数非法时返回包含该成员名的 ErrInvalidScore，且不写入任何成员
+func (c *CacheZSort) ZAddMultipleString(key string, members map[string]string) (int, error) {
+	parsed := make([]ScoreMember, 0, len(members))
+	for member, scoreStr := range members {
+		score := new(big.Rat)
+		if _, ok := score.SetString(scoreStr); !ok {
+			return 0, fmt.Errorf("%w: member %q has score %q", ErrInvalidScore, member, scoreStr)
+		}
+		parsed = append(parsed, ScoreMember{Score: score, Member: member})
+	}
+	res, err := c.zaddMultiple(key, parsed)
+	return res.written, err
+}
+
+// ==================== ZMergeMax / ZMergeMin ====================
+
+// ZMergeMax 批量合并成员，每个成员保留已有分数与传入分数中较大的一个
+// 不存在的成员直接创建；整个批次在同一把写锁内完成，返回新建或分数被更新的成员数。
+// 与 ZAddMultiple 等批量写入一样会发布订阅事件、唤醒阻塞弹出，但不调用写穿透回调；
+// 开启 WithImmutableScores 时已存在的成员保持不变，也不计入返回值
+func (c *CacheZSort) ZMergeMax(key string, members map[string]*big.Rat) int {
+	return c.zmerge(key, members, 1)
+}
+
+// ZMergeMin 批量合并成员，每个成员保留已有分数与传入分数中较小的一个
+// 不存在的成员直接创建；整个批次在同一把写锁内完成，返回新建或分数被更新的成员数。
+// 事件、阻塞弹出、写穿透及 WithImmutableScores 的处理与 ZMergeMax 相同
+func (c *CacheZSort) ZMergeMin(key string, members map[string]*big.Rat) int {
+	return c.zmerge(key, members, -1)
+}
+
+// zmerge 批量合并成员，仅当传入分数与已有分数的比较结果等于 want 时才更新
+func (c *CacheZSort) zmerge(key string, members map[string]*big.Rat, want int) int {
+	set, err := c.lockZSetForWrite(key)
+	if err != nil {
+		return 0
+	}
+
+	observe := c.events.active()
+	var events []Event
 	count := 0
 	for member, score := range members {
-		set.sl.insertInternal(member, score)
+		prev := set.sl.memberMap[set.sl.key(member)]
+		if prev != nil && (c.immutableScores || compare(score, prev.score) != want) {
+			continue
+		}
+		node := set.sl.insertInternal(member, score)
+		if observe {
+			if ev, ok := memberEvent(key, member, prev, node); ok {
+				events = append(events, ev)
+			}
+		}
 		count++
 	}
+	wake := c.notifier.hasWaiters()
+	set.sl.mu.Unlock()
+
+	c.notifier.notify(key, wake && count > 0)
+	c.events.publish(events)
+	if count == 0 {
+		c.removeIfEmpty(key, set)
+	}
 	return count
 }
 
@@ -115,32 +676,49 @@ func (c *CacheZSort) ZAddMultiple(key string, members map[string]*big.Rat) int {
 
 // ZRem 删除成员
 func (c *CacheZSort) ZRem(key, member string) bool {
-	set := c.getZSet(key)
+	set := c.lockExistingZSet(key)
 	if set == nil {
 		return false
 	}
 
-	set.mu.Lock()
-	defer set.mu.Unlock()
-	return set.sl.DeleteByMember(member)
+	prev := set.sl.memberMap[set.sl.key(member)]
+	ok := set.deleteMember(member, c.softDelete)
+	set.sl.mu.Unlock()
+
+	if ok {
+		c.removeIfEmpty(key, set)
+		if c.events.active() {
+			c.events.publish(removeEvents(key, []ScoreMember{{Score: prev.score, Member: member}}))
+		}
+	}
+	return ok
 }
 
 // ZRemMultiple 删除多个成员
 func (c *CacheZSort) ZRemMultiple(key string, members []string) int {
-	set := c.getZSet(key)
+	set := c.lockExistingZSet(key)
 	if set == nil {
 		return 0
 	}
 
-	set.mu.Lock()
-	defer set.mu.Unlock()
-
+	observe := c.events.active()
+	var removed []ScoreMember
 	count := 0
 	for _, member := range members {
-		if set.sl.DeleteByMember(member) {
+		prev := set.sl.memberMap[set.sl.key(member)]
+		if set.deleteMember(member, c.softDelete) {
 			count++
+			if observe {
+				removed = append(removed, ScoreMember{Score: prev.score, Member: member})
+			}
 		}
 	}
+	set.sl.mu.Unlock()
+
+	if count > 0 {
+		c.removeIfEmpty(key, set)
+		c.events.publish(removeEvents(key, removed))
+	}
 	return count
 }
 
@@ -161,7 +739,70 @@ func (c *CacheZSort) ZScoreString(key, member string) (string, bool) {
 	if !ok {
 		return "", false
 	}
-	return score.FloatString(20), true // 默认返回20位小数
+	return c.formatScore(score), true // 默认返回20位小数，见 SetScorePrecision 与 WithLosslessStrings
+}
+
+// ZScoreExact 获取成员分数的精确字符串，不受精度设置影响
+// 有限小数返回最短的十进制形式（如 "2.5"、"3"），无限小数返回 "分子/分母" 形式（如 "1/3"）
+func (c *CacheZSort) ZScoreExact(key, member string) (string, bool) {
+	score, ok := c.ZScore(key, member)
+	if !ok {
+		return "", false
+	}
+	return exactScoreString(score), true
+}
+
+// ZScoreRat 获取成员分数的精确有理数字符串（"p/q" 形式，分母为 1 时只返回整数部分）
+// 结果可通过 big.Rat.SetString 无损还原；ZScoreString 仍按小数位数输出，可能被舍入
+func (c *CacheZSort) ZScoreRat(key, member string) (string, bool) {
+	score, ok := c.ZScore(key, member)
+	if !ok {
+		return "", false
+	}
+	return score.RatString(), true
+}
+
+// ZMScore 批量获取成员的分数
+// 返回切片与 members 一一对应，不存在的成员（或 key 不存在）对应位置为 nil
+func (c *CacheZSort) ZMScore(key string, members ...string) []*big.Rat {
+	set := c.getZSet(key)
+	if set == nil {
+		return make([]*big.Rat, len(members))
+	}
+	return set.sl.GetScores(members)
+}
+
+// ZMScoreString 批量获取成员的分数（字符串格式，保留 precision 位小数）
+// 不存在的成员对应位置为空字符串
+func (c *CacheZSort) ZMScoreString(key string, precision int, members ...string) []string {
+	scores := c.ZMScore(key, members...)
+	result := make([]string, len(scores))
+	for i, score := range scores {
+		if score != nil {
+			result[i] = score.FloatString(precision)
+		}
+	}
+	return result
+}
+
+// ZScoreAt 获取成员的分数及其最近一次变更时间
+// 变更时间由 ZAdd（分数发生变化时）、ZIncrBy 等写操作更新，读操作不影响
+func (c *CacheZSort) ZScoreAt(key, member string) (*big.Rat, time.Time, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil, time.Time{}, false
+	}
+	return set.sl.GetScoreAt(member)
+}
+
+// ZTrend 按给定顺序读取同一成员在多个 key（如按时间排列的快照）中的分数
+// 返回切片与 keys 一一对应，key 或成员不存在的位置为 nil
+func (c *CacheZSort) ZTrend(keys []string, member string) []*big.Rat {
+	result := make([]*big.Rat, len(keys))
+	for i, key := range keys {
+		result[i], _ = c.ZScore(key, member)
+	}
+	return result
 }
 
 // ==================== ZRank ====================
@@ -196,6 +837,62 @@ func (c *CacheZSort) ZRevRank(key, member string) (int, bool) {
 	return card - 1 - rank, true
 }
 
+// ZSubsetRanked 获取指定成员子集，按其在整个集合中的排名升序返回
+// 每个成员附带从0开始的正序排名，不存在的成员会被忽略；适用于"好友排行"等场景
+func (c *CacheZSort) ZSubsetRanked(key string, members []string) []RankedMember {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+	return set.sl.RankMembers(members)
+}
+
+// ZRangeAround 获取成员及其排名前后各 before/after 个相邻成员（正序，包含成员本身）
+// 同时返回该成员从0开始的排名；到达集合两端时自动截断。成员或 key 不存在时返回 nil, -1, false。
+// before/after 小于 0 时按 0 处理
+func (c *CacheZSort) ZRangeAround(key, member string, before, after int, withScores bool) ([]interface{}, int, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil, -1, false
+	}
+
+	result, rank, ok := set.sl.RangeAround(member, max(before, 0), max(after, 0))
+	if !ok {
+		return nil, -1, false
+	}
+
+	if withScores {
+		output := make([]interface{}, 0, len(result)*2)
+		for _, sm := range result {
+			output = append(output, sm.Member, c.formatScore(sm.Score))
+		}
+		return output, rank - 1, true
+	}
+
+	output := make([]interface{}, 0, len(result))
+	for _, sm := range result {
+		output = append(output, sm.Member)
+	}
+	return output, rank - 1, true
+}
+
+// ZRankMulti 批量获取成员的正序排名（从0开始），只获取一次读锁，每个排名通过 span 在 O(log n) 内求出
+// 不存在的成员不会出现在结果中；key 不存在时返回 nil。
+// 结果以集合中保存的成员名为键，WithCaseInsensitiveMembers 模式下可能与参数的大小写不同
+func (c *CacheZSort) ZRankMulti(key string, members []string) map[string]int {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+
+	ranked := set.sl.RankMembers(members)
+	ranks := make(map[string]int, len(ranked))
+	for _, rm := range ranked {
+		ranks[rm.Member] = rm.Rank
+	}
+	return ranks
+}
+
 // GetMemberRank 根据 member 查询排名（从1开始）
 // 这是 ZRank 的别名，返回 1-based 排名
 func (c *CacheZSort) GetMemberRank(key, member string) (int, bool) {
@@ -213,17 +910,37 @@ func (c *CacheZSort) GetMemberRank(key, member string) (int, bool) {
 	return rank, rank > 0
 }
 
-// GetPrevMember 根据 member 查询前一位成员
-// 返回: prevMember, prevScore, exists
-func (c *CacheZSort) GetPrevMember(key, member string) (string, *big.Rat, bool) {
+// ZRankStandard 获取成员的标准竞赛排名（从1开始，"1224"）：分数相同的成员共享较小排名，之后的排名跳过
+func (c *CacheZSort) ZRankStandard(key, member string) (int, bool) {
 	set := c.getZSet(key)
 	if set == nil {
-		return "", nil, false
+		return 0, false
 	}
-	return set.sl.GetPrevMember(member)
+	standard, _, ok := set.sl.CompetitionRanks(member)
+	return standard, ok
 }
 
-// GetNextMember 根据 member 查询后一位成员
+// ZRankDense 获取成员的密集排名（从1开始，"1223"）：分数相同的成员共享排名，排名之间不留空位
+func (c *CacheZSort) ZRankDense(key, member string) (int, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return 0, false
+	}
+	_, dense, ok := set.sl.CompetitionRanks(member)
+	return dense, ok
+}
+
+// GetPrevMember 根据 member 查询前一位成员
+// 返回: prevMember, prevScore, exists
+func (c *CacheZSort) GetPrevMember(key, member string) (string, *big.Rat, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return "", nil, false
+	}
+	return set.sl.GetPrevMember(member)
+}
+
+// GetNextMember 根据 member 查询后一位成员
 // 返回: nextMember, nextScore, exists
 func (c *CacheZSort) GetNextMember(key, member string) (string, *big.Rat, bool) {
 	set := c.getZSet(key)
@@ -240,7 +957,7 @@ func (c *CacheZSort) GetPrevMemberString(key, member string) (string, string, bo
 	if !ok {
 		return "", "", false
 	}
-	return prevMember, prevScore.FloatString(20), true
+	return prevMember, c.formatScore(prevScore), true
 }
 
 // GetNextMemberString 根据 member 查询后一位成员（分数为字符串格式）
@@ -250,47 +967,154 @@ func (c *CacheZSort) GetNextMemberString(key, member string) (string, string, bo
 	if !ok {
 		return "", "", false
 	}
-	return nextMember, nextScore.FloatString(20), true
+	return nextMember, c.formatScore(nextScore), true
 }
 
-// ==================== ZRange ====================
+// ==================== ZScanBackFrom ====================
 
-// ZRange 获取指定排名范围的成员（正序，从0开始，闭区间）
-func (c *CacheZSort) ZRange(key string, start, stop int, withScores bool) []interface{} {
+// ZScanBackFrom 以 member 为锚点向前（分数更小的方向）取最多 count 个成员
+// 适用于"向上加载更多"的分页场景，结果离锚点最近的在前，不包含锚点本身；
+// withScores 为 false 时返回结果中的 Score 为 nil
+func (c *CacheZSort) ZScanBackFrom(key, member string, count int, withScores bool) []ScoreMember {
 	set := c.getZSet(key)
-	if set == nil {
+	if set == nil || count <= 0 {
 		return nil
 	}
 
-	card := set.sl.Len()
-	if card == 0 {
+	result, ok := set.sl.ScanBackFrom(member, count)
+	if !ok {
 		return nil
 	}
 
-	// 处理负数索引
-	if start < 0 {
-		start = card + start
+	if !withScores {
+		for i := range result {
+			result[i].Score = nil
+		}
 	}
-	if stop < 0 {
-		stop = card + stop
+	return result
+}
+
+// ==================== ZScanRanked ====================
+
+// defaultScanCount 扫描类方法未指定 count 时每批返回的数量
+const defaultScanCount = 10
+
+// ZScanRanked 按排名分批遍历有序集合，每个成员附带其正序排名（从0开始）
+// cursor 为本批起始位置，首次调用传 0；返回的 next 为 0 表示遍历结束。
+// 游标基于位置，遍历期间插入或删除成员会使后续批次整体平移，
+// 可能重复或遗漏个别成员；需要稳定遍历时请使用快照
+func (c *CacheZSort) ZScanRanked(key string, cursor uint64, count int) (uint64, []RankedMember) {
+	set := c.getZSet(key)
+	if set == nil {
+		return 0, nil
 	}
-	if start < 0 {
-		start = 0
+	if count <= 0 {
+		count = defaultScanCount
 	}
-	if stop >= card {
-		stop = card - 1
+
+	start := int(cursor) + 1
+	result := set.sl.Range(start, start+count-1, false)
+
+	members := make([]RankedMember, len(result))
+	for i, sm := range result {
+		members[i] = RankedMember{Rank: int(cursor) + i, Score: sm.Score, Member: sm.Member}
 	}
-	if start > stop {
+
+	next := cursor + uint64(len(result))
+	if len(result) < count || next >= uint64(set.sl.Len()) {
+		next = 0
+	}
+	return next, members
+}
+
+// ==================== ZScan ====================
+
+// ZScan 以游标方式增量遍历有序集合，首次调用传 cursor 0，返回的 next 为 0 表示遍历结束
+// 成员按其哈希值（而非分数）排序，游标即下一批的起始哈希。每批先取至多约 count 个成员
+// （count <= 0 时为 10），再按 match 过滤，因此过滤后的批次可能为空而遍历尚未结束；
+// match 为空表示匹配全部，否则按 Redis 风格 glob 匹配成员名。
+//
+// 由于游标不依赖排名，遍历期间其他协程插入、删除或修改分数不会使后续批次平移：
+// 整个遍历期间始终存在的成员恰好被返回一次；遍历中途新增或删除的成员可能返回也可能不返回
+func (c *CacheZSort) ZScan(key string, cursor uint64, match string, count int) (uint64, []ScoreMember) {
+	set := c.getZSet(key)
+	if set == nil {
+		return 0, nil
+	}
+	if count <= 0 {
+		count = defaultScanCount
+	}
+
+	next, batch := set.sl.scanByHash(cursor, count)
+	if match == "" || match == "*" {
+		return next, batch
+	}
+
+	result := make([]ScoreMember, 0, len(batch))
+	for _, sm := range batch {
+		if globMatch(match, sm.Member) {
+			result = append(result, sm)
+		}
+	}
+	return next, result
+}
+
+// ==================== ZRandMember ====================
+
+// ZRandMember 随机返回成员
+// count > 0 时最多返回 count 个互不重复的成员；count < 0 时恰好返回 -count 个成员，允许重复；
+// count == 0 时返回空结果。withScores 为 true 时成员与分数交替排列
+func (c *CacheZSort) ZRandMember(key string, count int, withScores bool) []interface{} {
+	set := c.getZSet(key)
+	if set == nil {
 		return nil
 	}
 
-	// 转换为1-based索引
-	result := set.sl.Range(start+1, stop+1, false)
+	var result []ScoreMember
+	if count >= 0 {
+		result = set.sl.RandomMembers(count, true)
+	} else {
+		result = set.sl.RandomMembers(-count, false)
+	}
+
+	if withScores {
+		output := make([]interface{}, 0, len(result)*2)
+		for _, sm := range result {
+			output = append(output, sm.Member, c.formatScore(sm.Score))
+		}
+		return output
+	}
+
+	output := make([]interface{}, 0, len(result))
+	for _, sm := range result {
+		output = append(output, sm.Member)
+	}
+	return output
+}
+
+// ZWeightedSample 按分数加权、有放回地随机抽取 n 个成员，抽中概率为 分数/总分
+// 存在负分数时返回 ErrNegativeScore；键不存在或总分为 0 时返回 nil
+func (c *CacheZSort) ZWeightedSample(key string, n int) ([]string, error) {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil, nil
+	}
+	return set.sl.WeightedSample(n)
+}
+
+// ==================== ZRange ====================
+
+// ZRange 获取指定排名范围的成员（正序，从0开始，闭区间）
+func (c *CacheZSort) ZRange(key string, start, stop int, withScores bool) []interface{} {
+	result := c.zrange(key, start, stop, false)
+	if result == nil {
+		return nil
+	}
 
 	if withScores {
 		output := make([]interface{}, 0, len(result)*2)
 		for _, sm := range result {
-			output = append(output, sm.Member, sm.Score.FloatString(20))
+			output = append(output, sm.Member, c.formatScore(sm.Score))
 		}
 		return output
 	}
@@ -304,6 +1128,29 @@ func (c *CacheZSort) ZRange(key string, start, stop int, withScores bool) []inte
 
 // ZRevRange 获取指定排名范围的成员（倒序，从0开始，闭区间）
 func (c *CacheZSort) ZRevRange(key string, start, stop int, withScores bool) []interface{} {
+	result := c.zrange(key, start, stop, true)
+	if result == nil {
+		return nil
+	}
+
+	if withScores {
+		output := make([]interface{}, 0, len(result)*2)
+		for _, sm := range result {
+			output = append(output, sm.Member, c.formatScore(sm.Score))
+		}
+		return output
+	}
+
+	output := make([]interface{}, 0, len(result))
+	for _, sm := range result {
+		output = append(output, sm.Member)
+	}
+	return output
+}
+
+// zrange 获取指定排名范围的成员（从0开始，闭区间，支持负数索引），reverse 为 true 时按倒序排名
+// key 不存在或范围为空时返回 nil
+func (c *CacheZSort) zrange(key string, start, stop int, reverse bool) []ScoreMember {
 	set := c.getZSet(key)
 	if set == nil {
 		return nil
@@ -333,25 +1180,245 @@ func (c *CacheZSort) ZRevRange(key string, start, stop int, withScores bool) []i
 
 	// 转换倒序排名为正序排名（均为 0-based）
 	// 倒序排名 r → 正序排名 (card - 1 - r)
-	fwdStart := card - 1 - stop
-	fwdStop := card - 1 - start
+	if reverse {
+		start, stop = card-1-stop, card-1-start
+	}
+
+	// 转换为1-based索引
+	result := set.sl.Range(start+1, stop+1, reverse)
+	c.checkDuplicates(key, result)
+	return result
+}
+
+// ==================== ZTopK / ZBottomK ====================
+
+// ZTopK 返回分数最高的 k 个成员（倒序，同 ZRevRange(key, 0, k-1)），withScores 为 true 时成员与分数交替排列
+// 直接从尾节点向前遍历 k 个节点，不做索引换算，也不复制中间结果；k <= 0 或 key 不存在时返回 nil
+func (c *CacheZSort) ZTopK(key string, k int, withScores bool) []interface{} {
+	return c.zedge(key, k, true, withScores)
+}
+
+// ZBottomK 返回分数最低的 k 个成员（正序，同 ZRange(key, 0, k-1)），withScores 为 true 时成员与分数交替排列
+func (c *CacheZSort) ZBottomK(key string, k int, withScores bool) []interface{} {
+	return c.zedge(key, k, false, withScores)
+}
 
-	// 转换为1-based索引，用 reverse 遍历
-	result := set.sl.Range(fwdStart+1, fwdStop+1, true)
+// zedge 从集合的一端遍历至多 k 个节点
+func (c *CacheZSort) zedge(key string, k int, fromTail, withScores bool) []interface{} {
+	set := c.getZSet(key)
+	if set == nil || k <= 0 {
+		return nil
+	}
+
+	set.sl.mu.RLock()
+	defer set.sl.mu.RUnlock()
 
+	if k > set.sl.length {
+		k = set.sl.length
+	}
+	if k == 0 {
+		return nil
+	}
+	size := k
 	if withScores {
-		output := make([]interface{}, 0, len(result)*2)
-		for _, sm := range result {
-			output = append(output, sm.Member, sm.Score.FloatString(20))
+		size *= 2
+	}
+	output := make([]interface{}, 0, size)
+	node := set.sl.head.forward[0]
+	if fromTail {
+		node = set.sl.tail
+	}
+	for ; node != nil && k > 0; k-- {
+		output = append(output, node.member)
+		if withScores {
+			output = append(output, c.formatScore(node.score))
+		}
+		if fromTail {
+			node = node.backward
+		} else {
+			node = node.forward[0]
 		}
-		return output
 	}
+	return output
+}
 
-	output := make([]interface{}, 0, len(result))
-	for _, sm := range result {
-		output = append(output, sm.Member)
+// ==================== ZQuantile ====================
+
+// ZQuantile 返回第 q 分位的分数，即正序排名 ceil(q*基数)（从1开始，至少为1）处成员的分数
+// q 须在 [0, 1] 内；q 按其最短十进制表示精确计算，0.07*100 等乘积不会因浮点误差多进一位。
+// q 非法、key 不存在或集合为空时返回 false；通过 span 定位排名，无需线性遍历
+func (c *CacheZSort) ZQuantile(key string, q float64) (*big.Rat, bool) {
+	scores := c.ZQuantiles(key, []float64{q})
+	if scores == nil || scores[0] == nil {
+		return nil, false
 	}
-	return output
+	return scores[0], true
+}
+
+// ZQuantiles 在同一把读锁内计算多个分位数，结果与 qs 一一对应，非法的 q 对应 nil
+// key 不存在或集合为空时返回 nil
+func (c *CacheZSort) ZQuantiles(key string, qs []float64) []*big.Rat {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+
+	set.sl.mu.RLock()
+	defer set.sl.mu.RUnlock()
+
+	card := set.sl.length
+	if card == 0 {
+		return nil
+	}
+	scores := make([]*big.Rat, len(qs))
+	for i, q := range qs {
+		rank, ok := quantileRank(q, card)
+		if !ok {
+			continue
+		}
+		scores[i] = new(big.Rat).Set(set.sl.getNodeByRankInternal(rank).score)
+	}
+	return scores
+}
+
+// quantileRank 计算第 q 分位对应的正序排名（从1开始），q 不在 [0, 1] 内时返回 false
+func quantileRank(q float64, card int) (int, bool) {
+	if !(q >= 0 && q <= 1) {
+		return 0, false
+	}
+	exact, _ := new(big.Rat).SetString(strconv.FormatFloat(q, 'g', -1, 64))
+	exact.Mul(exact, big.NewRat(int64(card), 1))
+	// 向上取整
+	rank := new(big.Int).Quo(exact.Num(), exact.Denom())
+	if !exact.IsInt() {
+		rank.Add(rank, big.NewInt(1))
+	}
+	return max(int(rank.Int64()), 1), true
+}
+
+// ==================== ZHistogram ====================
+
+// ZHistogram 按 edges 划分的区间统计成员数量，返回 len(edges)+1 个计数
+// 第 0 个为分数 < edges[0] 的成员数，第 i 个为 edges[i-1] <= 分数 < edges[i] 的成员数，
+// 最后一个为分数 >= edges[len(edges)-1] 的成员数。edges 必须严格升序，否则返回 ErrUnsortedEdges；
+// 按分数升序遍历一次并同步推进区间，复杂度 O(n+b)。key 不存在时计数全为 0
+func (c *CacheZSort) ZHistogram(key string, edges []*big.Rat) ([]int, error) {
+	for i := 1; i < len(edges); i++ {
+		if compare(edges[i-1], edges[i]) >= 0 {
+			return nil, fmt.Errorf("%w: edge %d (%s) <= edge %d (%s)", ErrUnsortedEdges, i, edges[i].RatString(), i-1, edges[i-1].RatString())
+		}
+	}
+
+	counts := make([]int, len(edges)+1)
+	set := c.getZSet(key)
+	if set == nil {
+		return counts, nil
+	}
+
+	set.sl.mu.RLock()
+	defer set.sl.mu.RUnlock()
+
+	bucket := 0
+	for node := set.sl.head.forward[0]; node != nil; node = node.forward[0] {
+		for bucket < len(edges) && compare(node.score, edges[bucket]) >= 0 {
+			bucket++
+		}
+		counts[bucket]++
+	}
+	return counts, nil
+}
+
+// ==================== ZAggregate ====================
+
+// ZAggregate 在一次读锁内遍历集合，精确计算分数的总和、最小值、最大值、平均值（有理数）及成员数
+// 最小值与最大值直接取首尾节点；key 不存在或集合为空时 ok 为 false
+func (c *CacheZSort) ZAggregate(key string) (sum, min, max, mean *big.Rat, count int, ok bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil, nil, nil, nil, 0, false
+	}
+
+	set.sl.mu.RLock()
+	defer set.sl.mu.RUnlock()
+
+	count = set.sl.length
+	if count == 0 {
+		return nil, nil, nil, nil, 0, false
+	}
+	sum = new(big.Rat)
+	for node := set.sl.head.forward[0]; node != nil; node = node.forward[0] {
+		sum.Add(sum, node.score)
+	}
+	min = new(big.Rat).Set(set.sl.head.forward[0].score)
+	max = new(big.Rat).Set(set.sl.tail.score)
+	mean = new(big.Rat).Quo(sum, big.NewRat(int64(count), 1))
+	return sum, min, max, mean, count, true
+}
+
+// ==================== ForEach ====================
+
+// ForEach 按分数顺序遍历有序集合（reverse 为 true 时倒序），fn 返回 false 时提前结束
+// 与 ZRange 不同，不会一次性分配整个结果切片，适合流式处理大集合。
+// 传给 fn 的 score 是副本；遍历期间持有该集合的读锁，fn 中不能写入同一个 key
+func (c *CacheZSort) ForEach(key string, reverse bool, fn func(string, *big.Rat) bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return
+	}
+	set.sl.Iterate(reverse, fn)
+}
+
+// ==================== ZGroupBy ====================
+
+// ZGroupBy 按分数顺序遍历一次有序集合，用 keyFn 计算分组键并分桶
+// 每个分组内保持分数升序；key 不存在时返回 nil。
+// 遍历期间持有该集合的读锁，keyFn 中不能写入同一个 key
+func (c *CacheZSort) ZGroupBy(key string, keyFn func(m ScoreMember) string) map[string][]ScoreMember {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+
+	groups := make(map[string][]ScoreMember)
+	set.sl.Iterate(false, func(member string, score *big.Rat) bool {
+		sm := ScoreMember{Score: score, Member: member}
+		group := keyFn(sm)
+		groups[group] = append(groups[group], sm)
+		return true
+	})
+	return groups
+}
+
+// ==================== ZScoresByPrefix ====================
+
+// ZScoresByPrefix 单次遍历返回成员名以 prefix 开头的所有成员及其分数（分数为副本）
+// key 不存在时返回 nil，没有匹配成员时返回空 map
+func (c *CacheZSort) ZScoresByPrefix(key, prefix string) map[string]*big.Rat {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+
+	scores := make(map[string]*big.Rat)
+	set.sl.Iterate(false, func(member string, score *big.Rat) bool {
+		if strings.HasPrefix(member, prefix) {
+			scores[member] = score
+		}
+		return true
+	})
+	return scores
+}
+
+// ==================== ZScoreGroups ====================
+
+// ZScoreGroups 按分数升序返回每个不同的分数及持有该分数的成员数量，可用于计算并列排名
+// key 不存在时返回 nil
+func (c *CacheZSort) ZScoreGroups(key string) []ScoreGroup {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+	return set.sl.ScoreGroups()
 }
 
 // ==================== ZRangeByScore ====================
@@ -364,6 +1431,7 @@ func (c *CacheZSort) ZRangeByScore(key string, min, max *big.Rat, withScores boo
 	}
 
 	result := set.sl.RangeByScore(min, max, false)
+	c.checkDuplicates(key, result)
 
 	// 应用 offset 和 count
 	if offset >= len(result) {
@@ -378,7 +1446,7 @@ func (c *CacheZSort) ZRangeByScore(key string, min, max *big.Rat, withScores boo
 	if withScores {
 		output := make([]interface{}, 0, len(result)*2)
 		for _, sm := range result {
-			output = append(output, sm.Member, sm.Score.FloatString(20))
+			output = append(output, sm.Member, c.formatScore(sm.Score))
 		}
 		return output
 	}
@@ -398,6 +1466,7 @@ func (c *CacheZSort) ZRevRangeByScore(key string, max, min *big.Rat, withScores
 	}
 
 	result := set.sl.RangeByScore(min, max, true)
+	c.checkDuplicates(key, result)
 
 	// 应用 offset 和 count
 	if offset >= len(result) {
@@ -412,7 +1481,7 @@ func (c *CacheZSort) ZRevRangeByScore(key string, max, min *big.Rat, withScores
 	if withScores {
 		output := make([]interface{}, 0, len(result)*2)
 		for _, sm := range result {
-			output = append(output, sm.Member, sm.Score.FloatString(20))
+			output = append(output, sm.Member, c.formatScore(sm.Score))
 		}
 		return output
 	}
@@ -424,83 +1493,586 @@ func (c *CacheZSort) ZRevRangeByScore(key string, max, min *big.Rat, withScores
 	return output
 }
 
-// ==================== ZCard ====================
-
-// ZCard 获取有序集合的成员数量
-func (c *CacheZSort) ZCard(key string) (int, bool) {
+// ZRangeByScoreProfiled 根据分数范围获取成员（正序，闭区间），同时返回查询开销统计
+// 访问节点数远大于结果数量时说明查询接近全表扫描；key 不存在时返回 nil 与零值统计
+func (c *CacheZSort) ZRangeByScoreProfiled(key string, min, max *big.Rat) ([]ScoreMember, QueryStats) {
 	set := c.getZSet(key)
 	if set == nil {
-		return 0, false
+		return nil, QueryStats{}
 	}
-	return set.sl.Len(), true
+	return set.sl.RangeByScoreProfiled(min, max)
 }
 
-// ==================== ZCount ====================
+// parseScoreBound 解析 Redis 风格的分数边界
+// "-inf"、"+inf"（或 "inf"）表示无界，"(" 前缀表示开区间，其余为闭区间
+func parseScoreBound(s string) (scoreBound, error) {
+	switch strings.ToLower(s) {
+	case "-inf":
+		return scoreBound{inf: -1}, nil
+	case "+inf", "inf":
+		return scoreBound{inf: 1}, nil
+	}
 
-// ZCount 统计分数范围内的成员数量
-func (c *CacheZSort) ZCount(key string, min, max *big.Rat) int {
-	set := c.getZSet(key)
-	if set == nil {
-		return 0
+	b := scoreBound{incl: true}
+	if strings.HasPrefix(s, "(") {
+		b.incl = false
+		s = s[1:]
 	}
-	return set.sl.CountByScore(min, max)
+	score := new(big.Rat)
+	if _, ok := score.SetString(s); !ok {
+		return scoreBound{}, ErrInvalidScore
+	}
+	b.value = score
+	return b, nil
 }
 
-// ==================== ZRemRangeByRank ====================
+// ZRangeByScoreEx 根据分数范围获取成员（正序），边界使用 Redis 语法
+// minStr/maxStr 支持 "(10" 表示开区间、"-inf"/"+inf" 表示无界；边界格式非法时返回 ErrInvalidScore。
+// offset/count 语义同 ZRangeByScore
+func (c *CacheZSort) ZRangeByScoreEx(key, minStr, maxStr string, withScores bool, offset, count int) ([]interface{}, error) {
+	min, err := parseScoreBound(minStr)
+	if err != nil {
+		return nil, err
+	}
+	max, err := parseScoreBound(maxStr)
+	if err != nil {
+		return nil, err
+	}
 
-// ZRemRangeByRank 删除指定排名范围的成员
-func (c *CacheZSort) ZRemRangeByRank(key string, start, stop int) int {
 	set := c.getZSet(key)
 	if set == nil {
-		return 0
+		return nil, nil
 	}
 
-	card := set.sl.Len()
-	if card == 0 {
-		return 0
-	}
+	result := set.sl.rangeByScoreBound(min, max)
+	c.checkDuplicates(key, result)
 
-	// 处理负数索引
-	if start < 0 {
-		start = card + start
+	// 应用 offset 和 count
+	if offset >= len(result) {
+		return nil, nil
 	}
-	if stop < 0 {
-		stop = card + stop
+	end := offset + count
+	if count <= 0 || end > len(result) {
+		end = len(result)
 	}
-	if start < 0 {
-		start = 0
+	result = result[offset:end]
+
+	if withScores {
+		output := make([]interface{}, 0, len(result)*2)
+		for _, sm := range result {
+			output = append(output, sm.Member, c.formatScore(sm.Score))
+		}
+		return output, nil
+	}
+
+	output := make([]interface{}, 0, len(result))
+	for _, sm := range result {
+		output = append(output, sm.Member)
+	}
+	return output, nil
+}
+
+// ZRangeByScoreWithBounds 根据分数范围获取成员（正序，闭区间），同时返回结果中实际出现的最低和最高分数
+// 实际边界可能比请求的 min/max 更窄；没有成员落在范围内时三个返回值均为 nil
+func (c *CacheZSort) ZRangeByScoreWithBounds(key string, min, max *big.Rat) (members []ScoreMember, actualMin, actualMax *big.Rat) {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil, nil, nil
+	}
+
+	members = set.sl.RangeByScore(min, max, false)
+	c.checkDuplicates(key, members)
+	if len(members) == 0 {
+		return nil, nil, nil
+	}
+	actualMin = new(big.Rat).Set(members[0].Score)
+	actualMax = new(big.Rat).Set(members[len(members)-1].Score)
+	return members, actualMin, actualMax
+}
+
+// ==================== ZNearestByScore ====================
+
+// ZNearestByScore 获取分数与 target 最接近的 n 个成员，按距离从近到远返回
+// 距离为分数与 target 之差的绝对值，距离相同时分数较低的成员在前
+func (c *CacheZSort) ZNearestByScore(key string, target *big.Rat, n int) []ScoreMember {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+	return set.sl.NearestByScore(target, n)
+}
+
+// ==================== ZRangeByLex ====================
+
+// parseLexBound 解析 Redis 风格的字典序边界
+// "-" 和 "+" 分别表示负无穷和正无穷，"[x" 表示包含 x，"(x" 表示不包含 x
+func parseLexBound(s string, isMin bool) (lexBound, bool) {
+	switch {
+	case s == "-":
+		return lexBound{inf: isMin}, isMin
+	case s == "+":
+		return lexBound{inf: !isMin}, !isMin
+	case strings.HasPrefix(s, "["):
+		return lexBound{value: s[1:], incl: true}, true
+	case strings.HasPrefix(s, "("):
+		return lexBound{value: s[1:]}, true
+	default:
+		return lexBound{}, false
+	}
+}
+
+// ZRangeByLex 按成员字典序获取区间内的成员（正序）
+// min/max 使用 Redis 语法："-"、"+" 表示无界，"[" 前缀表示闭区间，"(" 前缀表示开区间；
+// 边界格式非法时返回 nil。仅当所有成员分数相同时结果才有意义。
+// offset/count 语义同 ZRangeByScore，count <= 0 表示不限制数量
+func (c *CacheZSort) ZRangeByLex(key, min, max string, offset, count int) []string {
+	minBound, ok := parseLexBound(min, true)
+	if !ok {
+		return nil
+	}
+	maxBound, ok := parseLexBound(max, false)
+	if !ok {
+		return nil
+	}
+
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+
+	result := set.sl.rangeByLex(minBound, maxBound)
+
+	// 应用 offset 和 count
+	if offset >= len(result) {
+		return nil
+	}
+	end := offset + count
+	if count <= 0 || end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end]
+}
+
+// ZRevRangeByLex 按成员字典序倒序获取区间内的成员
+// 与 Redis 一致先传 max 再传 min，边界语法与 ZRangeByLex 相同；边界格式非法时返回 nil。
+// offset/count 作用于倒序后的结果，count <= 0 表示不限制数量
+func (c *CacheZSort) ZRevRangeByLex(key, max, min string, offset, count int) []string {
+	minBound, ok := parseLexBound(min, true)
+	if !ok {
+		return nil
+	}
+	maxBound, ok := parseLexBound(max, false)
+	if !ok {
+		return nil
+	}
+
+	set := c.getZSet(key)
+	if set == nil {
+		return nil
+	}
+
+	result := set.sl.revRangeByLex(minBound, maxBound)
+
+	// 应用 offset 和 count
+	if offset >= len(result) {
+		return nil
+	}
+	end := offset + count
+	if count <= 0 || end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end]
+}
+
+// ZLexRank 获取成员在字典序中的位置（从0开始），适用于所有成员分数相同的集合（如自动补全索引）
+// 只比较成员名，省去分数比较；分数不一致时结果未定义，请改用 ZRank
+func (c *CacheZSort) ZLexRank(key, member string) (int, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return -1, false
+	}
+	rank := set.sl.LexRank(member)
+	if rank == 0 {
+		return -1, false
+	}
+	return rank - 1, true
+}
+
+// ==================== ZCard ====================
+
+// ZCard 获取有序集合的成员数量
+func (c *CacheZSort) ZCard(key string) (int, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return 0, false
+	}
+	return set.sl.Len(), true
+}
+
+// ZCardMulti 批量获取多个集合的基数，结果只包含存在的 key
+// 只获取一次全局读锁，基数通过各集合的原子计数读取，不会阻塞正在写入的集合
+func (c *CacheZSort) ZCardMulti(keys []string) map[string]int {
+	sets := c.lookupZSets(keys)
+	cards := make(map[string]int, len(sets))
+	for key, set := range sets {
+		cards[key] = set.sl.Len()
+	}
+	return cards
+}
+
+// lookupZSets 在同一次全局读锁内查找多个 key 对应的未过期集合，不存在的 key 不出现在结果中
+func (c *CacheZSort) lookupZSets(keys []string) map[string]*ZSet {
+	sets := make(map[string]*ZSet, len(keys))
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, key := range keys {
+		if set, ok := c.sets[key]; ok && !c.expired(set) {
+			sets[key] = set
+		}
+	}
+	return sets
+}
+
+// ==================== ZOpCounts ====================
+
+// ZOpCounts 获取集合生命周期内累计新增与删除的成员数，可用于计算增删速率
+// 分数更新（ZAdd 已有成员、ZIncrBy）不计入任何一项；key 被删除后重新创建时计数从零开始
+func (c *CacheZSort) ZOpCounts(key string) (inserts, deletes int64) {
+	set := c.getZSet(key)
+	if set == nil {
+		return 0, 0
+	}
+	return set.sl.OpCounts()
+}
+
+// ==================== ZCount ====================
+
+// ZCount 统计分数范围内的成员数量
+func (c *CacheZSort) ZCount(key string, min, max *big.Rat) int {
+	set := c.getZSet(key)
+	if set == nil {
+		return 0
+	}
+	return set.sl.CountByScore(min, max)
+}
+
+// ==================== ZMemberAtCumulative ====================
+
+// ZMemberAtCumulative 查询累计分数首次达到或超过 threshold 的成员
+// 正序（reverse 为 false）从最低分开始累加，倒序从最高分开始累加；
+// 所有分数之和仍不足 threshold 时返回 false
+func (c *CacheZSort) ZMemberAtCumulative(key string, threshold *big.Rat, reverse bool) (ScoreMember, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return ScoreMember{}, false
+	}
+	return set.sl.MemberAtCumulative(threshold, reverse)
+}
+
+// ==================== ZRemRangeByRank ====================
+
+// ZRemRangeByRank 删除指定排名范围的成员
+func (c *CacheZSort) ZRemRangeByRank(key string, start, stop int) int {
+	set := c.lockExistingZSet(key)
+	if set == nil {
+		return 0
+	}
+
+	// 在同一把锁内换算负数索引并删除，避免基数在两步之间变化
+	card := set.sl.length
+
+	// 处理负数索引
+	if start < 0 {
+		start = card + start
+	}
+	if stop < 0 {
+		stop = card + stop
+	}
+	if start < 0 {
+		start = 0
 	}
 	if stop >= card {
 		stop = card - 1
 	}
-	if start > stop {
+	removed := 0
+	var events []Event
+	if start <= stop {
+		if c.events.active() {
+			events = removeEvents(key, set.sl.rangeInternal(start+1, stop+1, false))
+		}
+		removed = set.sl.removeByRankInternal(start+1, stop+1)
+	}
+	set.sl.mu.Unlock()
+
+	if removed > 0 {
+		c.removeIfEmpty(key, set)
+		c.events.publish(events)
+	}
+	return removed
+}
+
+// ==================== ZTrim ====================
+
+// ZTrim 只保留分数最高（keepHighest 为 true）或最低的 n 个成员，返回删除的成员数
+// 在同一把写锁内计算边界排名并一次删除；n >= 基数时不做修改，n <= 0 时清空集合
+func (c *CacheZSort) ZTrim(key string, n int, keepHighest bool) int {
+	set := c.lockExistingZSet(key)
+	if set == nil {
 		return 0
 	}
+	if n < 0 {
+		n = 0
+	}
 
-	return set.sl.RemoveByRank(start+1, stop+1)
+	card := set.sl.length
+	if n >= card {
+		set.sl.mu.Unlock()
+		return 0
+	}
+	// 待删除的排名范围（从1开始，闭区间）
+	start, stop := n+1, card
+	if keepHighest {
+		start, stop = 1, card-n
+	}
+	var events []Event
+	if c.events.active() {
+		events = removeEvents(key, set.sl.rangeInternal(start, stop, false))
+	}
+	removed := set.sl.removeByRankInternal(start, stop)
+	set.sl.mu.Unlock()
+
+	c.removeIfEmpty(key, set)
+	c.events.publish(events)
+	return removed
 }
 
 // ==================== ZRemRangeByScore ====================
 
 // ZRemRangeByScore 删除指定分数范围的成员
 func (c *CacheZSort) ZRemRangeByScore(key string, min, max *big.Rat) int {
-	set := c.getZSet(key)
+	set := c.lockExistingZSet(key)
 	if set == nil {
 		return 0
 	}
-	return set.sl.RemoveByScore(min, max)
+	var events []Event
+	if c.events.active() {
+		events = removeEvents(key, set.sl.rangeByScoreInternal(min, max, false))
+	}
+	removed := set.sl.removeByScoreInternal(min, max)
+	set.sl.mu.Unlock()
+
+	if removed > 0 {
+		c.removeIfEmpty(key, set)
+		c.events.publish(events)
+	}
+	return removed
 }
 
 // ==================== ZIncrBy ====================
 
 // ZIncrBy 增加成员的分数
 func (c *CacheZSort) ZIncrBy(key, member string, increment *big.Rat) (string, bool) {
-	set := c.getOrCreateZSet(key)
-	newScore, ok := set.sl.IncrementBy(member, increment)
+	newScore, ok := c.zincrBy(key, member, increment)
 	if !ok {
 		return "", false
 	}
-	return newScore.FloatString(20), true
+	return c.formatScore(newScore), true
+}
+
+// zincrBy 增加成员的分数，返回在同一把写锁内得到的新分数（副本）
+func (c *CacheZSort) zincrBy(key, member string, increment *big.Rat) (*big.Rat, bool) {
+	set, err := c.lockZSetForWrite(key)
+	if err != nil {
+		return nil, false
+	}
+	if _, exists := set.sl.memberMap[set.sl.key(member)]; exists && c.immutableScores && increment.Sign() != 0 {
+		set.sl.mu.Unlock()
+		return nil, false
+	}
+	old := set.scoreForRollback(member, c.hasWriteThrough())
+	prev := set.sl.memberMap[set.sl.key(member)]
+	newScore, ok := set.sl.incrementByInternal(member, increment)
+	node := set.sl.memberMap[set.sl.key(member)]
+	old.wrote(node)
+	var events []Event
+	if ok && c.events.active() {
+		if ev, changed := memberEvent(key, member, prev, node); changed {
+			events = append(events, ev)
+		}
+	}
+	wake := c.notifier.hasWaiters()
+	set.sl.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	if err := c.applyWriteThrough(key, member, newScore, set, old); err != nil {
+		return nil, false
+	}
+	c.notifier.notify(key, wake)
+	c.events.publish(events)
+	return newScore, true
+}
+
+// ZIncrByInt64 增加成员的分数（增量为 int64）
+func (c *CacheZSort) ZIncrByInt64(key, member string, delta int64) (string, bool) {
+	return c.ZIncrBy(key, member, new(big.Rat).SetInt64(delta))
+}
+
+// ZIncrByFloat64 增加成员的分数（增量为 float64，按 SetFloat64 精确转换）
+// delta 为 NaN 或无穷大时不做修改并返回 false
+func (c *CacheZSort) ZIncrByFloat64(key, member string, delta float64) (string, bool) {
+	increment := new(big.Rat).SetFloat64(delta)
+	if increment == nil {
+		return "", false
+	}
+	return c.ZIncrBy(key, member, increment)
+}
+
+// ZIncrByString 增加成员的分数（增量为字符串格式），增量非法时返回 ErrInvalidScore
+func (c *CacheZSort) ZIncrByString(key, member, deltaStr string) (string, bool, error) {
+	increment := new(big.Rat)
+	if _, ok := increment.SetString(deltaStr); !ok {
+		return "", false, ErrInvalidScore
+	}
+	score, ok := c.ZIncrBy(key, member, increment)
+	return score, ok, nil
+}
+
+// ZIncrByMembers 在同一把写锁内为多个成员增加相同的分数，不存在的成员以 inc 为分数创建
+// 重复列出的成员只增加一次，返回受影响的成员数；
+// 开启 WithImmutableScores 且存在需要修改分数的已有成员时整批不生效并返回 0
+func (c *CacheZSort) ZIncrByMembers(key string, members []string, inc *big.Rat) int {
+	if len(members) == 0 {
+		return 0
+	}
+	set, err := c.lockZSetForWrite(key)
+	if err != nil {
+		return 0
+	}
+	if c.immutableScores && inc.Sign() != 0 {
+		for _, member := range members {
+			if _, exists := set.sl.memberMap[set.sl.key(member)]; exists {
+				set.sl.mu.Unlock()
+				return 0
+			}
+		}
+	}
+
+	observe := c.events.active()
+	var events []Event
+	seen := make(map[string]struct{}, len(members))
+	for _, member := range members {
+		if _, dup := seen[member]; dup {
+			continue
+		}
+		seen[member] = struct{}{}
+		prev := set.sl.memberMap[set.sl.key(member)]
+		set.sl.incrementByInternal(member, inc)
+		if observe {
+			if ev, ok := memberEvent(key, member, prev, set.sl.memberMap[set.sl.key(member)]); ok {
+				events = append(events, ev)
+			}
+		}
+	}
+	wake := c.notifier.hasWaiters()
+	set.sl.mu.Unlock()
+
+	c.notifier.notify(key, wake)
+	c.events.publish(events)
+	return len(seen)
+}
+
+// ==================== ZRescale ====================
+
+// ZRescale 将集合当前的分数范围 [min, max] 线性映射到 [newMin, newMax]，使用 big.Rat 精确计算
+// 所有分数相等时全部映射为 newMin。newMin > newMax（会颠倒顺序）、key 不存在或开启了
+// WithImmutableScores 时不做修改并返回 false。映射保持成员之间的顺序，
+// 因此原地改写分数而无需移动节点；newMin == newMax 使不同分数并列时按成员名重新排列
+func (c *CacheZSort) ZRescale(key string, newMin, newMax *big.Rat) bool {
+	if newMin.Cmp(newMax) > 0 || c.getZSet(key) == nil {
+		return false
+	}
+	set, err := c.lockZSetForWrite(key)
+	if err != nil {
+		return false
+	}
+	if set.sl.length == 0 {
+		set.sl.mu.Unlock()
+		return true
+	}
+
+	lo, hi := set.sl.head.forward[0].score, set.sl.tail.score
+	if c.immutableScores && (lo.Cmp(newMin) != 0 || hi.Cmp(newMax) != 0) {
+		set.sl.mu.Unlock()
+		return false
+	}
+
+	// 缩放系数 factor = (newMax - newMin) / (max - min)
+	factor := new(big.Rat)
+	if width := new(big.Rat).Sub(hi, lo); width.Sign() != 0 {
+		factor.Sub(newMax, newMin)
+		factor.Quo(factor, width)
+	}
+	lo = new(big.Rat).Set(lo)
+	collapse := factor.Sign() == 0 && compare(lo, hi) != 0
+
+	observe := c.events.active()
+	var events []Event
+	now := set.sl.now()
+	var nodes []*skipNode
+	for node := set.sl.head.forward[0]; node != nil; node = node.forward[0] {
+		nodes = append(nodes, node)
+	}
+	for _, node := range nodes {
+		score := new(big.Rat).Sub(node.score, lo)
+		score.Mul(score, factor)
+		score.Add(score, newMin)
+		if compare(score, node.score) == 0 {
+			continue
+		}
+		if observe {
+			events = append(events, Event{Op: EventUpdate, Key: key, Member: node.member, OldScore: new(big.Rat).Set(node.score), NewScore: new(big.Rat).Set(score)})
+		}
+		if collapse {
+			// 分数并列后需按成员名排序，重新插入节点
+			set.sl.insertInternal(node.member, score)
+			continue
+		}
+		node.score = score
+		node.updatedAt = now
+	}
+	wake := c.notifier.hasWaiters()
+	set.sl.mu.Unlock()
+
+	c.notifier.notify(key, wake)
+	c.events.publish(events)
+	return true
+}
+
+// ==================== CanonicalString ====================
+
+// CanonicalString 返回有序集合稳定的文本表示，便于 golden 文件测试和差异比较
+// 每个成员一行，格式为 "member=score"，按分数升序（分数相同时按成员名）排列，每行以换行结尾；
+// 分数为精确形式（同 ZScoreExact），成员名为空或包含 '='、'"'、空白控制字符时以 Go 字符串字面量形式输出。
+// 内容相同的集合无论插入顺序与跳表层级如何，输出都逐字节相同；key 不存在时返回空字符串
+func (c *CacheZSort) CanonicalString(key string) string {
+	set := c.getZSet(key)
+	if set == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	set.sl.Iterate(false, func(member string, score *big.Rat) bool {
+		if member == "" || strings.ContainsAny(member, "=\"\n\r\t") {
+			member = strconv.Quote(member)
+		}
+		b.WriteString(member)
+		b.WriteByte('=')
+		b.WriteString(exactScoreString(score))
+		b.WriteByte('\n')
+		return true
+	})
+	return b.String()
 }
 
 // ==================== Del ====================
@@ -512,11 +2084,17 @@ func (c *CacheZSort) Del(keys ...string) int {
 
 	count := 0
 	for _, key := range keys {
-		if _, ok := c.sets[key]; ok {
+		if set, ok := c.sets[key]; ok {
+			set.markRemoved()
 			delete(c.sets, key)
-			count++
+			if !c.expired(set) {
+				count++
+			}
 		}
 	}
+	if count > 0 {
+		c.publishSetsLocked()
+	}
 	return count
 }
 
@@ -524,10 +2102,17 @@ func (c *CacheZSort) Del(keys ...string) int {
 
 // Exists 检查有序集合是否存在
 func (c *CacheZSort) Exists(key string) bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	_, ok := c.sets[key]
-	return ok
+	return c.getZSet(key) != nil
+}
+
+// ExistsMulti 批量检查多个 key 是否存在，每个传入的 key 在结果中都有对应项
+func (c *CacheZSort) ExistsMulti(keys []string) map[string]bool {
+	sets := c.lookupZSets(keys)
+	result := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		_, result[key] = sets[key]
+	}
+	return result
 }
 
 // ==================== Keys ====================
@@ -538,72 +2123,422 @@ func (c *CacheZSort) Keys() []string {
 	defer c.mu.RUnlock()
 
 	keys := make([]string, 0, len(c.sets))
-	for key := range c.sets {
-		keys = append(keys, key)
+	for key, set := range c.sets {
+		if !c.expired(set) {
+			keys = append(keys, key)
+		}
 	}
 	return keys
 }
 
+// KeysMatch 获取匹配 Redis 风格 glob 模式的 key，结果按字典序排列
+// 支持 *、?、[abc] / [^abc] / [a-z] 字符集以及 \ 转义，'/' 不是特殊字符
+func (c *CacheZSort) KeysMatch(pattern string) []string {
+	c.mu.RLock()
+	keys := make([]string, 0)
+	for key, set := range c.sets {
+		if !c.expired(set) && globMatch(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Strings(keys)
+	return keys
+}
+
+// ==================== DBSize / ScanKeys ====================
+
+// DBSize 获取有序集合（未过期的 key）的数量，不分配内存
+func (c *CacheZSort) DBSize() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	n := 0
+	for _, set := range c.sets {
+		if !c.expired(set) {
+			n++
+		}
+	}
+	return n
+}
+
+// ScanKeys 以游标方式增量遍历 key，首次调用传 cursor 0，返回的 next 为 0 表示遍历结束
+// 与 ZScan 相同，key 按其哈希值排序，游标即下一批的起始哈希；每批先取至多约 count 个 key
+// （count <= 0 时为 10），再按 match 过滤（为空表示匹配全部）。与 Redis 的 SCAN 相同，过滤发生在按 count
+// 截取之后，返回的批次可能为空而 next 不为 0，是否结束只能以 next 是否为 0 判断。
+// 单批耗时 O(n log count)，n 为 key 总数。遍历期间其他协程创建或删除 key 不会使后续批次平移：整个遍历期间始终存在的 key 恰好被返回一次，
+// 遍历中途创建或删除的 key 可能返回也可能不返回
+func (c *CacheZSort) ScanKeys(cursor uint64, match string, count int) (uint64, []string) {
+	if count <= 0 {
+		count = defaultScanCount
+	}
+
+	c.mu.RLock()
+	next, batch := scanHashBatch(cursor, count, func(visit func(string, *ZSet)) {
+		for key, set := range c.sets {
+			if !c.expired(set) {
+				visit(key, set)
+			}
+		}
+	})
+	c.mu.RUnlock()
+
+	keys := make([]string, 0, len(batch))
+	for _, entry := range batch {
+		if match == "" || globMatch(match, entry.key) {
+			keys = append(keys, entry.key)
+		}
+	}
+	return next, keys
+}
+
+// ==================== Rename ====================
+
+// Rename 将 oldKey 重命名为 newKey，newKey 已存在时被覆盖，返回 oldKey 是否存在
+// 只在全局写锁内移动集合指针，不复制任何节点，过期时间随集合一起转移；oldKey 与 newKey 相同时
+// 不做修改，仅返回 oldKey 是否存在。之后对 oldKey 的写入会创建新的集合
+func (c *CacheZSort) Rename(oldKey, newKey string) bool {
+	return c.rename(oldKey, newKey, false)
+}
+
+// RenameNX 仅当 newKey 不存在时将 oldKey 重命名为 newKey，成功时返回 true
+// oldKey 不存在、newKey 已存在（包括 oldKey 与 newKey 相同）时返回 false
+func (c *CacheZSort) RenameNX(oldKey, newKey string) bool {
+	return c.rename(oldKey, newKey, true)
+}
+
+// rename 重命名的实现，nx 为 true 时不覆盖已存在的 newKey
+func (c *CacheZSort) rename(oldKey, newKey string, nx bool) bool {
+	c.mu.Lock()
+	set, ok := c.sets[oldKey]
+	if ok && c.expired(set) {
+		c.dropSetLocked(oldKey, set)
+		ok = false
+	}
+	if !ok {
+		c.mu.Unlock()
+		return false
+	}
+	if oldKey == newKey {
+		c.mu.Unlock()
+		return !nx
+	}
+	dst, exists := c.sets[newKey]
+	if exists && c.expired(dst) {
+		c.dropSetLocked(newKey, dst)
+		exists = false
+	}
+	if exists && nx {
+		c.mu.Unlock()
+		return false
+	}
+	if exists {
+		c.dropSetLocked(newKey, dst)
+	}
+
+	// 直接移动 *ZSet 指针：此前已取得该集合的读写方（无论通过哪个 key）继续作用于同一集合，
+	// 相当于在重命名之前完成
+	delete(c.sets, oldKey)
+	c.sets[newKey] = set
+	c.publishSetsLocked()
+	wake := c.notifier.hasWaiters()
+	c.mu.Unlock()
+
+	c.notifier.notify(newKey, wake)
+	return true
+}
+
+// ==================== ZStats ====================
+
+// ZStats 获取 key 底层跳表的结构统计信息，key 不存在时返回 false
+func (c *CacheZSort) ZStats(key string) (SkipListStats, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return SkipListStats{}, false
+	}
+	return set.sl.Stats(), true
+}
+
+// ==================== ZMemUsage / MemUsage ====================
+
+// ZMemUsage 估算 key 占用的内存字节数（节点、按层级分配的指针数组、成员字符串与分数等）
+// key 不存在时返回 0；结果为近似值，适合用于观察趋势与比较不同 key 的大小
+func (c *CacheZSort) ZMemUsage(key string) int64 {
+	set := c.getZSet(key)
+	if set == nil {
+		return 0
+	}
+	return set.sl.MemUsage()
+}
+
+// MemUsage 估算所有未过期 key 占用的内存字节数之和（含 key 字符串本身）
+func (c *CacheZSort) MemUsage() int64 {
+	c.mu.RLock()
+	sets := make(map[string]*ZSet, len(c.sets))
+	for key, set := range c.sets {
+		if !c.expired(set) {
+			sets[key] = set
+		}
+	}
+	c.mu.RUnlock()
+
+	var total int64
+	for key, set := range sets {
+		total += int64(len(key)) + set.sl.MemUsage()
+	}
+	return total
+}
+
+// ==================== SnapshotKeys ====================
+
+// SnapshotKeys 对多个有序集合做一致性快照
+// 依次获取各集合的读锁（顺序同 WithLock），全部持有后再统一复制，
+// 保证返回的所有 key 反映同一时刻的状态；不存在的 key 不会出现在结果中
+func (c *CacheZSort) SnapshotKeys(keys []string) map[string][]ScoreMember {
+	return c.snapshotKeys(keys, false)
+}
+
+// snapshotKeys 一致性快照的实现，shared 为 true 时分数不做复制（见 allShared），仅供内部只读使用
+func (c *CacheZSort) snapshotKeys(keys []string, shared bool) map[string][]ScoreMember {
+	sets := make(map[string]*ZSet, len(keys))
+	c.mu.RLock()
+	for _, key := range keys {
+		if set, ok := c.sets[key]; ok && !c.expired(set) {
+			sets[key] = set
+		}
+	}
+	c.mu.RUnlock()
+
+	runlock := rlockZSets(sets)
+	defer runlock()
+
+	result := make(map[string][]ScoreMember, len(sets))
+	for key, set := range sets {
+		if shared {
+			result[key] = set.sl.allShared()
+		} else {
+			result[key] = set.sl.allInternal()
+		}
+	}
+	return result
+}
+
 // ==================== Flush ====================
 
 // Flush 清空所有有序集合
 func (c *CacheZSort) Flush() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	for _, set := range c.sets {
+		set.markRemoved()
+	}
 	c.sets = make(map[string]*ZSet)
+	c.publishSetsLocked()
 }
 
 // ==================== ZPopMin ====================
 
-// ZPopMin 弹出分数最低的成员
+// ZPopMin 弹出分数最低的至多 count 个成员，count <= 0 或 key 不存在时返回 nil
+// 只弹出一个成员时可使用 ZPopMinOne
 func (c *CacheZSort) ZPopMin(key string, count int) []ScoreMember {
-	set := c.getZSet(key)
-	if set == nil {
+	if count <= 0 {
 		return nil
 	}
-
-	if count <= 0 {
+	set := c.lockExistingZSet(key)
+	if set == nil {
 		return nil
 	}
 
-	set.mu.Lock()
-	defer set.mu.Unlock()
-
 	card := set.sl.Len()
 	if count > card {
 		count = card
 	}
 
-	result := set.sl.Range(1, count, false)
-	set.sl.RemoveByRank(1, count)
+	result := set.sl.rangeInternal(1, count, false)
+	set.sl.removeByRankInternal(1, count)
+	return c.finishPop(key, set, result)
+}
 
+// finishPop 释放集合写锁并完成弹出：清理空集合，为弹出的成员发布 EventRemove 事件（调用者必须持有集合写锁）
+// 事件中的分数单独复制，订阅者修改它不会影响返回给调用方的结果
+func (c *CacheZSort) finishPop(key string, set *ZSet, result []ScoreMember) []ScoreMember {
+	var events []Event
+	if c.events.active() {
+		events = make([]Event, 0, len(result))
+		for _, sm := range result {
+			events = append(events, Event{Op: EventRemove, Key: key, Member: sm.Member, OldScore: new(big.Rat).Set(sm.Score)})
+		}
+	}
+	set.sl.mu.Unlock()
+
+	c.removeIfEmpty(key, set)
+	c.events.publish(events)
 	return result
 }
 
+// ZPopMinOne 原子地弹出分数最低的一个成员，集合为空或 key 不存在时返回 false
+func (c *CacheZSort) ZPopMinOne(key string) (ScoreMember, bool) {
+	return firstPopped(c.ZPopMin(key, 1))
+}
+
+// firstPopped 返回弹出结果中的第一个成员
+func firstPopped(result []ScoreMember) (ScoreMember, bool) {
+	if len(result) == 0 {
+		return ScoreMember{}, false
+	}
+	return result[0], true
+}
+
 // ==================== ZPopMax ====================
 
-// ZPopMax 弹出分数最高的成员
+// ZPopMax 弹出分数最高的至多 count 个成员，count <= 0 或 key 不存在时返回 nil
+// 只弹出一个成员时可使用 ZPopMaxOne
 func (c *CacheZSort) ZPopMax(key string, count int) []ScoreMember {
-	set := c.getZSet(key)
-	if set == nil {
+	if count <= 0 {
 		return nil
 	}
-
-	if count <= 0 {
+	set := c.lockExistingZSet(key)
+	if set == nil {
 		return nil
 	}
 
-	set.mu.Lock()
-	defer set.mu.Unlock()
-
 	card := set.sl.Len()
 	if count > card {
 		count = card
 	}
 
 	start := card - count + 1
-	result := set.sl.Range(start, card, true)
-	set.sl.RemoveByRank(start, card)
+	result := set.sl.rangeInternal(start, card, true)
+	set.sl.removeByRankInternal(start, card)
+	return c.finishPop(key, set, result)
+}
 
-	return result
+// ZPopMaxOne 原子地弹出分数最高的一个成员，集合为空或 key 不存在时返回 false
+func (c *CacheZSort) ZPopMaxOne(key string) (ScoreMember, bool) {
+	return firstPopped(c.ZPopMax(key, 1))
+}
+
+// ==================== ZPopByScore ====================
+
+// ZPopByScore 原子地弹出分数 <= max 的成员，至多 limit 个（limit <= 0 表示不限制），分数最低的在前
+// 适用于延迟队列：以到期时间为分数，多个消费者并发调用也不会弹出同一成员
+func (c *CacheZSort) ZPopByScore(key string, max *big.Rat, limit int) []ScoreMember {
+	set := c.lockExistingZSet(key)
+	if set == nil {
+		return nil
+	}
+
+	count := 0
+	for node := set.sl.head.forward[0]; node != nil && compare(node.score, max) <= 0; node = node.forward[0] {
+		if limit > 0 && count >= limit {
+			break
+		}
+		count++
+	}
+	if count == 0 {
+		set.sl.mu.Unlock()
+		return nil
+	}
+
+	result := set.sl.rangeInternal(1, count, false)
+	set.sl.removeByRankInternal(1, count)
+	return c.finishPop(key, set, result)
+}
+
+// ==================== ZPopMinTierLimited / ZPopMaxTierLimited ====================
+
+// ZPopMinTierLimited 弹出分数最低的一档（分数相同的全部成员），但至多 maxCount 个
+// 该档成员超过 maxCount 时按成员名升序弹出前 maxCount 个，其余保留；maxCount <= 0 时返回 nil
+func (c *CacheZSort) ZPopMinTierLimited(key string, maxCount int) []ScoreMember {
+	return c.zpopTierLimited(key, maxCount, true)
+}
+
+// ZPopMaxTierLimited 弹出分数最高的一档，但至多 maxCount 个
+// 档内同样按成员名升序弹出前 maxCount 个，其余保留；maxCount <= 0 时返回 nil
+func (c *CacheZSort) ZPopMaxTierLimited(key string, maxCount int) []ScoreMember {
+	return c.zpopTierLimited(key, maxCount, false)
+}
+
+// zpopTierLimited 弹出最低（min 为 true）或最高一档中至多 maxCount 个成员
+func (c *CacheZSort) zpopTierLimited(key string, maxCount int, min bool) []ScoreMember {
+	if maxCount <= 0 {
+		return nil
+	}
+	set := c.lockExistingZSet(key)
+	if set == nil {
+		return nil
+	}
+
+	if set.sl.length == 0 {
+		set.sl.mu.Unlock()
+		return nil
+	}
+
+	var start, stop int
+	if min {
+		// 最低档从排名 1 开始，沿底层向后数出同分成员
+		tier := set.sl.head.forward[0].score
+		start, stop = 1, 0
+		for node := set.sl.head.forward[0]; node != nil && stop < maxCount && compare(node.score, tier) == 0; node = node.forward[0] {
+			stop++
+		}
+	} else {
+		// 最高档的起始排名 = 分数低于该档的成员数 + 1
+		start = set.sl.countBelowInternal(set.sl.tail.score) + 1
+		stop = start + maxCount - 1
+		if stop > set.sl.length {
+			stop = set.sl.length
+		}
+	}
+
+	result := set.sl.rangeInternal(start, stop, false)
+	set.sl.removeByRankInternal(start, stop)
+	return c.finishPop(key, set, result)
+}
+
+// ==================== ZMPop ====================
+
+// ZMPop 按顺序扫描 keys，从第一个非空的集合弹出至多 count 个成员
+// min 为 true 时从分数最低端弹出，否则从最高端弹出；返回实际使用的 key 与弹出的成员。
+// 不存在或为空的 key 会被跳过，全部为空或 count <= 0 时返回空 key 与 nil
+func (c *CacheZSort) ZMPop(keys []string, min bool, count int) (string, []ScoreMember) {
+	if count <= 0 {
+		return "", nil
+	}
+	for _, key := range keys {
+		var popped []ScoreMember
+		if min {
+			popped = c.ZPopMin(key, count)
+		} else {
+			popped = c.ZPopMax(key, count)
+		}
+		if len(popped) > 0 {
+			return key, popped
+		}
+	}
+	return "", nil
+}
+
+// ==================== ZDrainSnapshot ====================
+
+// ZDrainSnapshot 原子地取出 key 的全部成员（按分数升序）并删除该 key，适用于按时间段汇总后重新计数
+// 在取出前的写入都包含在结果中，之后的写入落在新建的集合里，任何一次写入都不会被重复计数或丢失
+func (c *CacheZSort) ZDrainSnapshot(key string) []ScoreMember {
+	c.mu.Lock()
+	set, ok := c.sets[key]
+	if !ok || c.expired(set) {
+		c.mu.Unlock()
+		return nil
+	}
+
+	// 先持有集合写锁并标记移除，使后续写入方转而创建新集合
+	set.sl.mu.Lock()
+	set.removed = true
+	delete(c.sets, key)
+	c.publishSetsLocked()
+	c.mu.Unlock()
+
+	defer set.sl.mu.Unlock()
+	return set.sl.allInternal()
 }
@@ -0,0 +1,53 @@
+package csort
+
+// ==================== RangeCursor ====================
+
+// RangeCursor 有状态的排名区间查询游标，适用于反复查询相邻或重叠窗口的场景（如滑动窗口看板）
+// 游标记住上次查询结束位置的节点，下一次窗口靠近时直接沿链表移动，无需从表头重新下降；
+// 集合结构发生变化（插入、删除成员）或 key 被替换后会自动退回到普通查询。
+// RangeCursor 不是并发安全的，每个协程应使用各自的游标
+type RangeCursor struct {
+	c    *CacheZSort
+	key  string
+	set  *ZSet
+	node *skipNode // 上次查询 stop 位置的节点
+	rank int       // node 的排名（1-based）
+
+	version uint64
+}
+
+// NewRangeCursor 创建指定 key 的区间查询游标
+func (c *CacheZSort) NewRangeCursor(key string) *RangeCursor {
+	return &RangeCursor{c: c, key: key}
+}
+
+// Range 获取指定排名范围的成员（正序，从0开始，闭区间），负数索引语义同 ZRange
+func (rc *RangeCursor) Range(start, stop int) []ScoreMember {
+	set := rc.c.getZSet(rc.key)
+	if set == nil {
+		rc.set, rc.node = nil, nil
+		return nil
+	}
+	if set != rc.set {
+		rc.set, rc.node = set, nil
+	}
+
+	card := set.sl.Len()
+	if start < 0 {
+		start = card + start
+	}
+	if stop < 0 {
+		stop = card + stop
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	result, node, version := set.sl.rangeFrom(start+1, stop+1, rc.node, rc.rank, rc.version)
+	if node == nil {
+		return nil
+	}
+	rc.node, rc.rank, rc.version = node, start+len(result), version
+	rc.c.checkDuplicates(rc.key, result)
+	return result
+}
@@ -0,0 +1,93 @@
+package csort
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestRangeCursorMatchesZRange 测试游标结果与直接查询一致，包括中途修改集合的情况
+func TestRangeCursorMatchesZRange(t *testing.T) {
+	cache := New()
+	for i := 0; i < 200; i++ {
+		cache.ZAddInt64("test", fmt.Sprintf("m%03d", i), int64(i%37))
+	}
+
+	check := func(rc *RangeCursor, start, stop int) {
+		t.Helper()
+		got := rc.Range(start, stop)
+		want := cache.ZRange("test", start, stop, false)
+		if len(got) != len(want) {
+			t.Fatalf("Range(%d, %d) returned %d members, want %d", start, stop, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].Member != want[i] {
+				t.Fatalf("Range(%d, %d)[%d] = %s, want %s", start, stop, i, got[i].Member, want[i])
+			}
+		}
+	}
+
+	rc := cache.NewRangeCursor("test")
+
+	// 向前滑动
+	for start := 0; start < 190; start += 3 {
+		check(rc, start, start+9)
+	}
+	// 向后滑动
+	for start := 180; start >= 0; start -= 4 {
+		check(rc, start, start+9)
+	}
+	// 大跨度跳转、负数索引、越界
+	check(rc, 5, 15)
+	check(rc, 150, 160)
+	check(rc, -10, -1)
+	check(rc, 195, 300)
+	check(rc, 250, 260)
+
+	// 修改集合后提示失效，结果仍然正确
+	check(rc, 50, 60)
+	cache.ZAddInt64("test", "new", 0)
+	cache.ZRem("test", "m100")
+	check(rc, 52, 62)
+
+	// key 被删除后重建
+	cache.Del("test")
+	if got := rc.Range(0, 10); got != nil {
+		t.Errorf("Range on deleted key = %v, want nil", got)
+	}
+	cache.ZAddInt64("test", "x", 1)
+	check(rc, 0, 10)
+}
+
+// newSlidingWindowCache 构造滑动窗口基准测试的数据
+func newSlidingWindowCache(n int) *CacheZSort {
+	cache := New()
+	for i := 0; i < n; i++ {
+		cache.ZAddInt64("bench", fmt.Sprintf("member%d", i), int64(i))
+	}
+	return cache
+}
+
+// BenchmarkSlidingWindowCursor 基准测试使用游标的滑动窗口查询
+func BenchmarkSlidingWindowCursor(b *testing.B) {
+	const n, window = 100000, 50
+	cache := newSlidingWindowCache(n)
+	rc := cache.NewRangeCursor("bench")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := i % (n - window)
+		rc.Range(start, start+window-1)
+	}
+}
+
+// BenchmarkSlidingWindowZRange 基准测试重复调用 ZRange 的滑动窗口查询
+func BenchmarkSlidingWindowZRange(b *testing.B) {
+	const n, window = 100000, 50
+	cache := newSlidingWindowCache(n)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := i % (n - window)
+		cache.ZRange("bench", start, start+window-1, false)
+	}
+}
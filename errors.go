@@ -4,7 +4,14 @@ import "errors"
 
 // 错误定义
 var (
-	ErrInvalidScore   = errors.New("invalid score format")
-	ErrKeyNotFound    = errors.New("key not found")
-	ErrMemberNotFound = errors.New("member not found")
+	ErrInvalidScore    = errors.New("invalid score format")
+	ErrKeyNotFound     = errors.New("key not found")
+	ErrMemberNotFound  = errors.New("member not found")
+	ErrTooManyKeys     = errors.New("too many keys")
+	ErrDuplicateMember = errors.New("duplicate member in result")
+	ErrScoreImmutable  = errors.New("score of existing member is immutable")
+	ErrInvalidSnapshot = errors.New("invalid snapshot data")
+	ErrNegativeScore   = errors.New("negative score")
+	ErrUnsortedEdges   = errors.New("histogram edges not strictly ascending")
+	ErrInvalidConfig   = errors.New("invalid configuration")
 )
@@ -0,0 +1,148 @@
+package csort
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+)
+
+// EventOp 成员变更事件的类型
+type EventOp int
+
+const (
+	EventAdd    EventOp = iota + 1 // 新增成员
+	EventUpdate                    // 已有成员的分数改变
+	EventRemove                    // 删除成员
+)
+
+// String 返回事件类型的名称
+func (op EventOp) String() string {
+	switch op {
+	case EventAdd:
+		return "add"
+	case EventUpdate:
+		return "update"
+	case EventRemove:
+		return "remove"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 描述一次成员变更
+type Event struct {
+	Op       EventOp
+	Key      string
+	Member   string
+	OldScore *big.Rat // EventAdd 时为 nil
+	NewScore *big.Rat // EventRemove 时为 nil
+}
+
+// subscriber 一个已登记的订阅者
+type subscriber struct {
+	id uint64
+	fn func(Event)
+}
+
+// eventHub 管理变更事件的订阅者
+// 订阅者列表写时复制，发布事件时无需加锁
+type eventHub struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   atomic.Pointer[[]subscriber]
+}
+
+// active 是否存在订阅者，为 false 时写入方跳过事件收集
+func (h *eventHub) active() bool {
+	subs := h.subs.Load()
+	return subs != nil && len(*subs) > 0
+}
+
+// add 登记订阅者，返回其编号
+func (h *eventHub) add(fn func(Event)) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	var subs []subscriber
+	if old := h.subs.Load(); old != nil {
+		subs = append(subs, *old...)
+	}
+	subs = append(subs, subscriber{id: h.nextID, fn: fn})
+	h.subs.Store(&subs)
+	return h.nextID
+}
+
+// remove 注销指定编号的订阅者
+func (h *eventHub) remove(id uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old := h.subs.Load()
+	if old == nil {
+		return
+	}
+	subs := make([]subscriber, 0, len(*old))
+	for _, sub := range *old {
+		if sub.id != id {
+			subs = append(subs, sub)
+		}
+	}
+	h.subs.Store(&subs)
+}
+
+// publish 按订阅顺序依次把事件交给每个订阅者（调用者不得持有任何锁）
+func (h *eventHub) publish(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	subs := h.subs.Load()
+	if subs == nil {
+		return
+	}
+	for _, ev := range events {
+		for _, sub := range *subs {
+			sub.fn(ev)
+		}
+	}
+}
+
+// memberEvent 比较写入前后的节点生成事件（调用者必须持有集合写锁）
+// prev 为写入前成员所在节点（不存在时为 nil），node 为写入后的节点；分数未变时返回 false
+func memberEvent(key, member string, prev, node *skipNode) (Event, bool) {
+	switch {
+	case prev == nil:
+		return Event{Op: EventAdd, Key: key, Member: member, NewScore: new(big.Rat).Set(node.score)}, true
+	case prev != node:
+		return Event{Op: EventUpdate, Key: key, Member: member, OldScore: new(big.Rat).Set(prev.score), NewScore: new(big.Rat).Set(node.score)}, true
+	default:
+		return Event{}, false
+	}
+}
+
+// removeEvents 为被删除的成员生成 EventRemove 事件
+func removeEvents(key string, removed []ScoreMember) []Event {
+	events := make([]Event, 0, len(removed))
+	for _, sm := range removed {
+		events = append(events, Event{Op: EventRemove, Key: key, Member: sm.Member, OldScore: sm.Score})
+	}
+	return events
+}
+
+// ==================== Subscribe ====================
+
+// Subscribe 订阅成员变更事件，返回用于取消订阅的函数（可重复调用）
+// 逐个成员的写入会触发事件：ZAdd 系列（含 ZAddWithValue、ZAddMultiple、ZAddBatch 与 Restore）、ZMergeMax、ZMergeMin、
+// ZUpdateScore、ZIncrBy、ZIncrByMembers、ZRescale、ZUndelete、ZRem、ZRemMultiple、ZRemRangeByRank、ZRemRangeByScore、
+// ZTrim、ZRemStaleThan、ZPop 系列（含 ZMPop 与 BZPopMin/BZPopMax）以及 WithLock 事务；分数未变化的写入不触发事件。
+// 整体替换或删除 key 的操作不逐个发布成员事件：ImportJSON、ZUnionStore 等 *Store 运算、ZCopy、ZRangeStore、
+// Rename、Del、Flush、ZDrainSnapshot 与过期回收（其中替换为非空集合的操作仍会唤醒阻塞在该 key 上的弹出方）。
+// 回调在写操作释放集合锁之后、于调用写操作的协程中同步执行，可以安全地读写缓存；
+// 写穿透回调失败并回滚的写入不会触发事件
+func (c *CacheZSort) Subscribe(fn func(ev Event)) (unsubscribe func()) {
+	id := c.events.add(fn)
+	var once sync.Once
+	return func() {
+		once.Do(func() { c.events.remove(id) })
+	}
+}
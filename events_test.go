@@ -0,0 +1,204 @@
+package csort
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRecorder 记录收到的事件，供测试断言
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) record(ev Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+// take 返回并清空已记录的事件
+func (r *eventRecorder) take() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+// TestSubscribeUpsert 测试分数变化与未变化的写入分别触发的事件
+func TestSubscribeUpsert(t *testing.T) {
+	cache := New()
+	var rec eventRecorder
+	cache.Subscribe(rec.record)
+
+	cache.ZAddInt64("lb", "a", 10)
+	events := rec.take()
+	if len(events) != 1 || events[0].Op != EventAdd || events[0].Member != "a" ||
+		events[0].OldScore != nil || events[0].NewScore.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Fatalf("add events = %+v, want one EventAdd with score 10", events)
+	}
+
+	// 分数未变化的写入不触发事件
+	cache.ZAddInt64("lb", "a", 10)
+	cache.ZIncrBy("lb", "a", new(big.Rat))
+	if events := rec.take(); len(events) != 0 {
+		t.Errorf("unchanged upsert fired %+v, want none", events)
+	}
+
+	cache.ZAddInt64("lb", "a", 15)
+	events = rec.take()
+	if len(events) != 1 || events[0].Op != EventUpdate ||
+		events[0].OldScore.Cmp(big.NewRat(10, 1)) != 0 || events[0].NewScore.Cmp(big.NewRat(15, 1)) != 0 {
+		t.Fatalf("update events = %+v, want EventUpdate 10 -> 15", events)
+	}
+
+	cache.ZIncrBy("lb", "a", big.NewRat(5, 1))
+	events = rec.take()
+	if len(events) != 1 || events[0].Op != EventUpdate || events[0].NewScore.Cmp(big.NewRat(20, 1)) != 0 {
+		t.Errorf("ZIncrBy events = %+v, want EventUpdate to 20", events)
+	}
+
+	cache.ZRem("lb", "a")
+	events = rec.take()
+	if len(events) != 1 || events[0].Op != EventRemove || events[0].OldScore.Cmp(big.NewRat(20, 1)) != 0 || events[0].NewScore != nil {
+		t.Errorf("ZRem events = %+v, want EventRemove with old score 20", events)
+	}
+	if cache.ZRem("lb", "a"); len(rec.take()) != 0 {
+		t.Error("removing a missing member should not fire events")
+	}
+}
+
+// TestSubscribeRangeRemove 测试按范围删除为每个成员触发事件
+func TestSubscribeRangeRemove(t *testing.T) {
+	cache := New()
+	for i, m := range []string{"a", "b", "c", "d", "e"} {
+		cache.ZAddInt64("lb", m, int64(i))
+	}
+
+	var rec eventRecorder
+	cache.Subscribe(rec.record)
+
+	cache.ZRemRangeByScore("lb", big.NewRat(1, 1), big.NewRat(2, 1))
+	events := rec.take()
+	if len(events) != 2 || events[0].Member != "b" || events[1].Member != "c" || events[0].Op != EventRemove {
+		t.Errorf("ZRemRangeByScore events = %+v, want removes of b and c", events)
+	}
+
+	cache.ZRemRangeByRank("lb", -1, -1)
+	events = rec.take()
+	if len(events) != 1 || events[0].Member != "e" || events[0].OldScore.Cmp(big.NewRat(4, 1)) != 0 {
+		t.Errorf("ZRemRangeByRank events = %+v, want remove of e", events)
+	}
+}
+
+// TestSubscribeMerge 测试 ZMergeMax/ZMergeMin 仅为实际写入的成员触发事件
+func TestSubscribeMerge(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("lb", "a", 10)
+	cache.ZAddInt64("lb", "b", 10)
+
+	var rec eventRecorder
+	cache.Subscribe(rec.record)
+
+	cache.ZMergeMax("lb", map[string]*big.Rat{"a": big.NewRat(20, 1), "b": big.NewRat(5, 1)})
+	events := rec.take()
+	if len(events) != 1 || events[0].Op != EventUpdate || events[0].Member != "a" ||
+		events[0].OldScore.Cmp(big.NewRat(10, 1)) != 0 || events[0].NewScore.Cmp(big.NewRat(20, 1)) != 0 {
+		t.Errorf("ZMergeMax events = %+v, want EventUpdate of a 10 -> 20", events)
+	}
+
+	cache.ZMergeMin("lb", map[string]*big.Rat{"c": big.NewRat(1, 1)})
+	events = rec.take()
+	if len(events) != 1 || events[0].Op != EventAdd || events[0].Member != "c" {
+		t.Errorf("ZMergeMin events = %+v, want EventAdd of c", events)
+	}
+}
+
+// TestSubscribeMultipleAndUnsubscribe 测试多个订阅者以及取消订阅
+func TestSubscribeMultipleAndUnsubscribe(t *testing.T) {
+	cache := New()
+	var first, second eventRecorder
+	unsubscribe := cache.Subscribe(first.record)
+	cache.Subscribe(second.record)
+
+	cache.ZAddInt64("lb", "a", 1)
+	if len(first.take()) != 1 || len(second.take()) != 1 {
+		t.Fatal("both subscribers should receive the event")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	cache.ZAddInt64("lb", "b", 2)
+	if len(first.take()) != 0 {
+		t.Error("unsubscribed callback should not receive events")
+	}
+	if len(second.take()) != 1 {
+		t.Error("remaining subscriber should still receive events")
+	}
+
+	// 回调在锁外执行，可以读写缓存
+	cache.Subscribe(func(ev Event) {
+		if ev.Key == "lb" {
+			cache.ZScore("lb", ev.Member)
+			cache.ZAddInt64("audit", ev.Member, 1)
+		}
+	})
+	cache.ZAddInt64("lb", "c", 3)
+	if _, ok := cache.ZScore("audit", "c"); !ok {
+		t.Error("callback should be able to write to the cache")
+	}
+}
+
+// TestSubscribePopAndIdle 测试弹出、闲置清理与恢复软删除成员触发的事件
+func TestSubscribePopAndIdle(t *testing.T) {
+	clock := newFakeClock()
+	cache := New(WithClock(clock.Now), WithSoftDelete())
+	for i, m := range []string{"a", "b", "c", "d"} {
+		cache.ZAddInt64("lb", m, int64(i))
+	}
+
+	var rec eventRecorder
+	cache.Subscribe(rec.record)
+
+	popped := cache.ZPopMin("lb", 2)
+	events := rec.take()
+	if len(events) != 2 || events[0].Op != EventRemove || events[0].Member != "a" || events[1].Member != "b" ||
+		events[1].OldScore.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Fatalf("ZPopMin events = %+v, want EventRemove of a and b", events)
+	}
+	// 事件中的分数与返回结果互不共享
+	events[0].OldScore.SetInt64(99)
+	if popped[0].Score.Cmp(new(big.Rat)) != 0 {
+		t.Errorf("popped score = %v, want 0", popped[0].Score)
+	}
+
+	cache.ZPopMax("lb", 1)
+	if events := rec.take(); len(events) != 1 || events[0].Op != EventRemove || events[0].Member != "d" {
+		t.Errorf("ZPopMax events = %+v, want EventRemove of d", events)
+	}
+
+	clock.Advance(time.Minute)
+	cache.ZAddInt64("lb", "e", 5)
+	rec.take()
+	if n := cache.ZRemStaleThan("lb", 30*time.Second); n != 1 {
+		t.Fatalf("ZRemStaleThan = %d, want 1", n)
+	}
+	events = rec.take()
+	if len(events) != 1 || events[0].Op != EventRemove || events[0].Member != "c" || events[0].OldScore.Cmp(big.NewRat(2, 1)) != 0 {
+		t.Errorf("ZRemStaleThan events = %+v, want EventRemove of c with old score 2", events)
+	}
+
+	cache.ZAddInt64("lb", "f", 6)
+	cache.ZRem("lb", "f")
+	rec.take()
+	if !cache.ZUndelete("lb", "f") {
+		t.Fatal("ZUndelete should restore f")
+	}
+	events = rec.take()
+	if len(events) != 1 || events[0].Op != EventAdd || events[0].Member != "f" || events[0].NewScore.Cmp(big.NewRat(6, 1)) != 0 {
+		t.Errorf("ZUndelete events = %+v, want EventAdd of f with score 6", events)
+	}
+}
@@ -0,0 +1,69 @@
+package csort
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestGlobMatch 测试 Redis 风格的 glob 匹配
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "", true},
+		{"*", "", true},
+		{"*", "anything/with/slashes", true},
+		{"user:*", "user:42", true},
+		{"user:*", "admin:42", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h*llo", "heeeello", true},
+		{"h**o", "ho", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h[c-a]llo", "hbllo", true},
+		{"h[a-c]llo", "hdllo", false},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{`[\]]`, "]", true},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"abc", "abcd", false},
+	}
+	for _, tc := range cases {
+		if got := globMatch(tc.pattern, tc.s); got != tc.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tc.pattern, tc.s, got, tc.want)
+		}
+	}
+}
+
+// TestKeysMatch 测试按 glob 模式筛选 key
+func TestKeysMatch(t *testing.T) {
+	cache := New()
+	for _, key := range []string{"user:1", "user:42", "users", "score:ab", "score:abc", "score:1", "a*b", "a?b", "axb"} {
+		cache.ZAddInt64(key, "m", 1)
+	}
+
+	cases := []struct {
+		pattern string
+		want    []string
+	}{
+		{"user:*", []string{"user:1", "user:42"}},
+		{"score:??", []string{"score:ab"}},
+		{"users", []string{"users"}},
+		{`a\*b`, []string{"a*b"}},
+		{`a\?b`, []string{"a?b"}},
+		{"a?b", []string{"a*b", "a?b", "axb"}},
+		{"score:[0-9]", []string{"score:1"}},
+		{"nothing*", []string{}},
+	}
+	for _, tc := range cases {
+		if got := cache.KeysMatch(tc.pattern); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("KeysMatch(%q) = %v, want %v", tc.pattern, got, tc.want)
+		}
+	}
+}
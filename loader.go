@@ -0,0 +1,54 @@
+package csort
+
+import "bytes"
+
+// ==================== SetLoader ====================
+
+// SetLoader 设置读穿透加载回调，传入 nil 表示取消
+// 查询的 key 不存在（或已过期）时调用 fn，fn 返回 true 且数据非空时用其内容创建该 key，
+// 之后的读取直接命中缓存。fn 在任何锁之外调用；若加载期间该 key 已被其他写入或加载创建，
+// 则丢弃本次加载结果并使用已有集合。需要新建 key 但 key 数量已达上限时视为未命中
+func (c *CacheZSort) SetLoader(fn func(key string) ([]ScoreMember, bool)) {
+	if fn == nil {
+		c.loader.Store(nil)
+		return
+	}
+	c.loader.Store(&fn)
+}
+
+// loadZSet 通过加载回调填充不存在的 key，未设置回调或未命中时返回 nil
+func (c *CacheZSort) loadZSet(key string) *ZSet {
+	fn := c.loader.Load()
+	if fn == nil {
+		return nil
+	}
+
+	members, ok := (*fn)(key)
+	if !ok || len(members) == 0 {
+		return nil
+	}
+
+	loaded := c.newZSet()
+	for _, sm := range members {
+		node := loaded.sl.insertInternal(sm.Member, sm.Score)
+		if sm.Value != nil {
+			node.value = bytes.Clone(sm.Value)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, exists := c.sets[key]; exists {
+		if !c.expired(existing) {
+			return existing
+		}
+		c.dropSetLocked(key, existing)
+	}
+	if c.maxKeys > 0 && len(c.sets) >= c.maxKeys {
+		return nil
+	}
+	c.sets[key] = loaded
+	c.publishSetsLocked()
+	return loaded
+}
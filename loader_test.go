@@ -0,0 +1,58 @@
+package csort
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+)
+
+// TestSetLoader 测试未命中时调用加载回调，之后的读取直接命中缓存
+func TestSetLoader(t *testing.T) {
+	cache := New()
+	var calls atomic.Int32
+	cache.SetLoader(func(key string) ([]ScoreMember, bool) {
+		calls.Add(1)
+		if key != "backed" {
+			return nil, false
+		}
+		return []ScoreMember{
+			{Member: "a", Score: big.NewRat(1, 1)},
+			{Member: "b", Score: big.NewRat(2, 1)},
+		}, true
+	})
+
+	score, ok := cache.ZScore("backed", "b")
+	if !ok || score.Cmp(big.NewRat(2, 1)) != 0 {
+		t.Fatalf("ZScore after load = %v, %v, want 2, true", score, ok)
+	}
+	if got := cache.ZRange("backed", 0, -1, false); len(got) != 2 || got[0] != "a" {
+		t.Errorf("ZRange = %v, want [a b]", got)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("loader called %d times, want 1", n)
+	}
+
+	// 加载回调未命中时 key 仍不存在
+	if _, ok := cache.ZScore("other", "a"); ok {
+		t.Error("ZScore on a loader miss should return false")
+	}
+	if cache.getZSet("other") != nil {
+		t.Error("loader miss should not create the key")
+	}
+
+	// 删除后再次读取会重新加载
+	cache.Del("backed")
+	before := calls.Load()
+	if card, _ := cache.ZCard("backed"); card != 2 {
+		t.Errorf("ZCard after reload = %d, want 2", card)
+	}
+	if calls.Load() != before+1 {
+		t.Error("reading a deleted key should call the loader again")
+	}
+
+	// 取消加载回调
+	cache.SetLoader(nil)
+	if _, ok := cache.ZScore("fresh", "a"); ok {
+		t.Error("ZScore without a loader should miss")
+	}
+}
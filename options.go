@@ -0,0 +1,193 @@
+package csort
+
+import (
+	"math/big"
+	"time"
+)
+
+// defaultScorePrecision 分数转字符串时默认保留的小数位数
+const defaultScorePrecision = 20
+
+// Option 配置 CacheZSort 的可选项
+type Option func(*CacheZSort)
+
+// Options 通过 NewWithOptions 创建 CacheZSort 时使用的配置，零值字段使用默认值
+type Options struct {
+	MaxLevel int     // 新建集合的跳表最大层级，须在 [1, 64] 内，0 表示默认值 32
+	P        float64 // 跳表节点晋升概率，须在 (0, 1) 内，0 表示默认值 0.25
+
+	CaseInsensitiveMembers bool // 成员名不区分大小写，同 WithCaseInsensitiveMembers
+}
+
+// NewWithOptions 按 Options 创建 CacheZSort，随后依次应用 opts
+// 配置非法时返回包装了 ErrInvalidConfig 的错误，校验规则同 NewSkipListWithConfig
+func NewWithOptions(o Options, opts ...Option) (*CacheZSort, error) {
+	maxLevel, p := o.MaxLevel, o.P
+	if maxLevel == 0 {
+		maxLevel = defaultMaxLevel
+	}
+	if p == 0 {
+		p = defaultP
+	}
+	if err := validateSkipListConfig(maxLevel, p); err != nil {
+		return nil, err
+	}
+
+	c := New(opts...)
+	c.maxLevel = maxLevel
+	c.p = p
+	if o.CaseInsensitiveMembers {
+		c.caseInsensitive = true
+	}
+	return c, nil
+}
+
+// WithLosslessStrings 让所有返回字符串分数的方法使用 big.Rat.RatString 输出
+// 例如 7/2 输出为 "7/2"、整数输出为 "42"，可通过 SetString 无损解析回原值；
+// 默认使用 FloatString(20)，对无限小数或超过 20 位小数的分数会有舍入
+func WithLosslessStrings() Option {
+	return func(c *CacheZSort) {
+		c.losslessStrings = true
+	}
+}
+
+// WithReadMostly 启用读多写少模式，适用于单写多读的场景
+// key 映射表以写时复制方式维护：创建或删除 key 时复制整张表并原子替换，
+// 读取 key 时无需获取全局锁；单个集合内的读写仍由集合自身的锁保护。
+// key 数量较多且频繁创建/删除 key 时不宜开启
+func WithReadMostly() Option {
+	return func(c *CacheZSort) {
+		c.readMostly = true
+	}
+}
+
+// WithClock 注入时钟，用于记录成员分数的变更时间，默认为 time.Now
+func WithClock(now func() time.Time) Option {
+	return func(c *CacheZSort) {
+		c.now = now
+	}
+}
+
+// WithTieBreak 设置分数相同时成员的排序规则，less(a, b) 为 true 表示 a 排在 b 之前，默认按成员名升序
+// 对所有集合生效，影响 ZRange、ZRank 等按排名的操作以及 ZUnion 等结果的顺序；
+// less 的要求与 NewSkipListWithTieBreak 相同，使用自定义规则时 ZRangeByLex 等按字典序的操作结果未定义
+func WithTieBreak(less func(a, b string) bool) Option {
+	return func(c *CacheZSort) {
+		c.tieBreak = less
+	}
+}
+
+// WithCaseInsensitiveMembers 开启成员名不区分大小写模式
+// 成员名按 strings.ToLower 归一化后索引，"Alice" 与 "alice" 视为同一成员，ZScore、ZRem 等可使用任意大小写形式；
+// 返回结果中的成员名保留原始形式，并跟随最近一次写入（ZAdd("k", "ALICE", ...) 之后显示为 "ALICE"）。
+// 分数相同的成员按归一化后的成员名排序，ZRangeByLex、ZLexRank 等按字典序的操作同样按归一化后的成员名比较。
+// ZUnionStore、ZInter、ZDiff 等集合运算也按归一化后的成员名合并，结果使用参数中靠后的 key 里的形式
+func WithCaseInsensitiveMembers() Option {
+	return func(c *CacheZSort) {
+		c.caseInsensitive = true
+	}
+}
+
+// WithMaxKeysStrict 限制有序集合（key）的最大数量
+// 达到上限后创建新 key 的写操作会被拒绝（ZAddString 等返回 ErrTooManyKeys，
+// ZAdd 等返回 false），已存在的 key 不受影响；与淘汰策略不同，不会删除任何已有数据。
+// n <= 0 表示不限制
+func WithMaxKeysStrict(n int) Option {
+	return func(c *CacheZSort) {
+		c.maxKeys = n
+	}
+}
+
+// WithAutoCleanup 开启空集合自动清理
+// 开启后 ZRem、ZRemMultiple、ZRemRangeByRank、ZRemRangeByScore、ZPopMin、ZPopMax
+// 等操作删空集合时会同时删除该 key，使 Exists 返回 false；默认保留空集合
+func WithAutoCleanup() Option {
+	return func(c *CacheZSort) {
+		c.autoCleanup = true
+	}
+}
+
+// WithSoftDelete 开启软删除
+// 开启后 ZRem、ZRemMultiple 删除的成员会以墓碑形式保留：它们不再出现在任何查询中，
+// 但可以通过 ZUndelete 恢复，直到调用 ZCompactDeleted 将墓碑彻底清除。
+// 其他删除操作（ZRemRangeBy*、ZPop* 等）不受影响，仍为物理删除
+func WithSoftDelete() Option {
+	return func(c *CacheZSort) {
+		c.softDelete = true
+	}
+}
+
+// WithImmutableScores 开启只追加模式：成员的分数一旦写入便不可修改
+// 对已存在的成员以不同分数 ZAdd 时返回 ErrScoreImmutable（ZAdd 返回 false），以相同分数重复写入为空操作；
+// 批量写入中任一成员违反约束时整批不写入，ZIncrBy 对已存在成员的非零增量失败，
+// ZMergeMax/ZMergeMin 只创建新成员
+func WithImmutableScores() Option {
+	return func(c *CacheZSort) {
+		c.immutableScores = true
+	}
+}
+
+// WithErrorHook 设置内部异常的上报回调，例如重复检测发现的问题
+// 回调在触发异常的操作中同步调用，不应阻塞或重入同一个 key 的写操作
+func WithErrorHook(fn func(err error)) Option {
+	return func(c *CacheZSort) {
+		c.errorHook = fn
+	}
+}
+
+// WithDuplicateDetection 开启调试用的重复成员检测
+// 开启后 ZRange、ZRevRange、ZRangeByScore、ZRevRangeByScore 会检查单次结果中
+// 是否出现同一成员多次，发现时通过 WithErrorHook 上报 ErrDuplicateMember；
+// 正常情况下不应触发，检测会带来额外开销，仅建议在排查并发问题时开启
+func WithDuplicateDetection() Option {
+	return func(c *CacheZSort) {
+		c.detectDuplicates = true
+	}
+}
+
+// SetScorePrecision 设置分数转字符串时保留的小数位数，默认 20，负数按 0 处理
+// 作用于 ZScoreString、ZRange 等所有返回字符串分数的方法；开启 WithLosslessStrings 时不生效
+func (c *CacheZSort) SetScorePrecision(n int) {
+	if n < 0 {
+		n = 0
+	}
+	c.scorePrecision.Store(int32(n))
+}
+
+// formatScore 按实例配置将分数格式化为字符串
+func (c *CacheZSort) formatScore(score *big.Rat) string {
+	if c.losslessStrings {
+		return score.RatString()
+	}
+	return score.FloatString(int(c.scorePrecision.Load()))
+}
+
+// exactScoreString 返回分数的精确字符串
+// 有限小数返回最短的十进制形式，否则返回 "分子/分母" 形式
+func exactScoreString(score *big.Rat) string {
+	if score.IsInt() {
+		return score.Num().String()
+	}
+
+	// 分母只含因子 2 和 5 时为有限小数，所需小数位数为两者指数的较大值
+	den := new(big.Int).Set(score.Denom())
+	digits := 0
+	for _, p := range []int64{2, 5} {
+		factor := big.NewInt(p)
+		rem := new(big.Int)
+		n := 0
+		for {
+			q, r := new(big.Int).QuoRem(den, factor, rem)
+			if r.Sign() != 0 {
+				break
+			}
+			den = q
+			n++
+		}
+		digits = max(digits, n)
+	}
+	if den.Cmp(big.NewInt(1)) != 0 {
+		return score.RatString()
+	}
+	return score.FloatString(digits)
+}
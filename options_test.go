@@ -0,0 +1,677 @@
+package csort
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestWithLosslessStrings 测试无损字符串分数输出
+func TestWithLosslessStrings(t *testing.T) {
+	cache := New(WithLosslessStrings())
+
+	cache.ZAdd("test", "a", big.NewRat(7, 2))
+	cache.ZAdd("test", "b", big.NewRat(1, 3))
+	cache.ZAddInt64("test", "c", 42)
+
+	// 校验字符串可以无损解析回原分数
+	roundTrip := func(name, got string, want *big.Rat) {
+		t.Helper()
+		parsed, ok := new(big.Rat).SetString(got)
+		if !ok {
+			t.Fatalf("%s: cannot parse %q", name, got)
+		}
+		if parsed.Cmp(want) != 0 {
+			t.Errorf("%s: %q round-trips to %s, want %s", name, got, parsed.RatString(), want.RatString())
+		}
+	}
+
+	score, ok := cache.ZScoreString("test", "a")
+	if !ok || score != "7/2" {
+		t.Errorf("ZScoreString(a) = %q, want 7/2", score)
+	}
+	roundTrip("ZScoreString(a)", score, big.NewRat(7, 2))
+
+	score, _ = cache.ZScoreString("test", "c")
+	if score != "42" {
+		t.Errorf("ZScoreString(c) = %q, want 42", score)
+	}
+
+	_, prevScore, ok := cache.GetPrevMemberString("test", "a")
+	if !ok {
+		t.Fatal("GetPrevMemberString(a) failed")
+	}
+	roundTrip("GetPrevMemberString(a)", prevScore, big.NewRat(1, 3))
+
+	newScore, ok := cache.ZIncrBy("test", "b", big.NewRat(1, 3))
+	if !ok {
+		t.Fatal("ZIncrBy failed")
+	}
+	roundTrip("ZIncrBy(b)", newScore, big.NewRat(2, 3))
+
+	result := cache.ZRange("test", 0, -1, true)
+	roundTrip("ZRange score", result[1].(string), big.NewRat(2, 3))
+
+	// 默认配置仍为 20 位小数
+	def := New()
+	def.ZAdd("test", "a", big.NewRat(7, 2))
+	if score, _ := def.ZScoreString("test", "a"); score != "3.50000000000000000000" {
+		t.Errorf("default ZScoreString = %q, want 3.50000000000000000000", score)
+	}
+}
+
+// TestWithReadMostly 测试读多写少模式下 key 的创建、删除与并发读取
+func TestWithReadMostly(t *testing.T) {
+	cache := New(WithReadMostly())
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	// 单写者：反复创建和删除 key
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			key := fmt.Sprintf("key%d", i%10)
+			cache.ZAddInt64(key, "m", int64(i))
+			if i%3 == 0 {
+				cache.Del(key)
+			}
+		}
+		close(stop)
+	}()
+
+	// 多读者：无锁查找 key
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				for i := 0; i < 10; i++ {
+					key := fmt.Sprintf("key%d", i)
+					if set := cache.getZSet(key); set != nil && set.sl == nil {
+						t.Errorf("getZSet(%s) returned a set without skip list", key)
+					}
+					cache.Exists(key)
+					cache.ZScore(key, "m")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	cache.Flush()
+	cache.ZAddInt64("final", "m", 1)
+	if !cache.Exists("final") {
+		t.Fatal("Exists(final) = false after ZAdd")
+	}
+	if cache.Exists("key1") {
+		t.Error("Exists(key1) = true after Flush")
+	}
+	if score, ok := cache.ZScore("final", "m"); !ok || score.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("ZScore(final, m) = %v, %v, want 1, true", score, ok)
+	}
+	if n := cache.Del("final"); n != 1 || cache.Exists("final") {
+		t.Errorf("Del(final) = %d, Exists = %v, want 1, false", n, cache.Exists("final"))
+	}
+}
+
+// benchmarkKeyLookup 并发查找 key 的基准测试
+func benchmarkKeyLookup(b *testing.B, cache *CacheZSort) {
+	for i := 0; i < 100; i++ {
+		cache.ZAddInt64(fmt.Sprintf("key%d", i), "m", int64(i))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			cache.getZSet(fmt.Sprintf("key%d", i%100))
+			i++
+		}
+	})
+}
+
+// BenchmarkKeyLookupRWMutex 基准测试默认模式（RWMutex）下的 key 查找
+func BenchmarkKeyLookupRWMutex(b *testing.B) {
+	benchmarkKeyLookup(b, New())
+}
+
+// BenchmarkKeyLookupReadMostly 基准测试读多写少模式（无锁）下的 key 查找
+func BenchmarkKeyLookupReadMostly(b *testing.B) {
+	benchmarkKeyLookup(b, New(WithReadMostly()))
+}
+
+// TestWithMaxKeysStrict 测试 key 数量上限
+func TestWithMaxKeysStrict(t *testing.T) {
+	cache := New(WithMaxKeysStrict(2))
+
+	if !cache.ZAddInt64("k1", "a", 1) || !cache.ZAddInt64("k2", "a", 2) {
+		t.Fatal("ZAdd within the key limit should succeed")
+	}
+
+	// 第 3 个 key 被拒绝
+	if cache.ZAddInt64("k3", "a", 3) {
+		t.Error("ZAdd creating the 3rd key should fail")
+	}
+	if ok, err := cache.ZAddString("k3", "a", "3"); ok || !errors.Is(err, ErrTooManyKeys) {
+		t.Errorf("ZAddString = %v, %v, want false, ErrTooManyKeys", ok, err)
+	}
+	if _, err := cache.ZAddMultipleString("k3", map[string]string{"a": "3"}); !errors.Is(err, ErrTooManyKeys) {
+		t.Errorf("ZAddMultipleString err = %v, want ErrTooManyKeys", err)
+	}
+	if _, ok := cache.ZIncrBy("k3", "a", big.NewRat(1, 1)); ok {
+		t.Error("ZIncrBy creating the 3rd key should fail")
+	}
+	if cache.Exists("k3") {
+		t.Error("k3 should not have been created")
+	}
+
+	// 已有 key 不受影响
+	if !cache.ZAddInt64("k1", "b", 10) {
+		t.Error("ZAdd on an existing key should succeed")
+	}
+	if card, _ := cache.ZCard("k1"); card != 2 {
+		t.Errorf("ZCard(k1) = %d, want 2", card)
+	}
+
+	// 删除 key 后可再次创建
+	cache.Del("k2")
+	if !cache.ZAddInt64("k3", "a", 3) {
+		t.Error("ZAdd should succeed after a key was deleted")
+	}
+}
+
+// TestWithDuplicateDetection 测试范围查询的重复成员检测
+func TestWithDuplicateDetection(t *testing.T) {
+	var reported []error
+	cache := New(WithDuplicateDetection(), WithErrorHook(func(err error) {
+		reported = append(reported, err)
+	}))
+
+	cache.ZAddInt64("test", "a", 1)
+	cache.ZAddInt64("test", "b", 2)
+	cache.ZAddInt64("test", "c", 3)
+
+	// 正常数据不触发
+	cache.ZRange("test", 0, -1, false)
+	if len(reported) != 0 {
+		t.Fatalf("unexpected reports on healthy set: %v", reported)
+	}
+
+	// 直接篡改节点制造重复成员
+	set := cache.getZSet("test")
+	set.sl.memberMap["b"].member = "a"
+
+	cache.ZRange("test", 0, -1, false)
+	cache.ZRevRangeByScore("test", big.NewRat(10, 1), big.NewRat(0, 1), false, 0, -1)
+	if len(reported) != 2 {
+		t.Fatalf("got %d reports, want 2", len(reported))
+	}
+	for _, err := range reported {
+		if !errors.Is(err, ErrDuplicateMember) {
+			t.Errorf("reported error = %v, want ErrDuplicateMember", err)
+		}
+	}
+
+	// 关闭检测时不上报
+	reported = nil
+	plain := New(WithErrorHook(func(err error) { reported = append(reported, err) }))
+	plain.ZAddInt64("test", "a", 1)
+	plain.ZAddInt64("test", "b", 2)
+	plain.getZSet("test").sl.memberMap["b"].member = "a"
+	plain.ZRange("test", 0, -1, false)
+	if len(reported) != 0 {
+		t.Errorf("detection disabled but got reports: %v", reported)
+	}
+}
+
+// TestWithAutoCleanup 测试集合变空时自动删除 key
+func TestWithAutoCleanup(t *testing.T) {
+	cache := New(WithAutoCleanup())
+
+	cache.ZAddInt64("test", "a", 1)
+	cache.ZAddInt64("test", "b", 2)
+
+	cache.ZRem("test", "a")
+	if !cache.Exists("test") {
+		t.Fatal("key should remain while it still has members")
+	}
+	cache.ZRem("test", "b")
+	if cache.Exists("test") {
+		t.Error("removing the last member should delete the key")
+	}
+
+	// 其他删空集合的操作
+	drain := map[string]func(key string){
+		"ZRemMultiple":     func(key string) { cache.ZRemMultiple(key, []string{"a", "b"}) },
+		"ZRemRangeByRank":  func(key string) { cache.ZRemRangeByRank(key, 0, -1) },
+		"ZRemRangeByScore": func(key string) { cache.ZRemRangeByScore(key, big.NewRat(0, 1), big.NewRat(10, 1)) },
+		"ZPopMin":          func(key string) { cache.ZPopMin(key, 5) },
+		"ZPopMax":          func(key string) { cache.ZPopMax(key, 5) },
+	}
+	for name, fn := range drain {
+		cache.ZAddInt64(name, "a", 1)
+		cache.ZAddInt64(name, "b", 2)
+		fn(name)
+		if cache.Exists(name) {
+			t.Errorf("%s draining the set should delete the key", name)
+		}
+	}
+
+	// 清理后重新写入可正常创建
+	cache.ZAddInt64("test", "c", 3)
+	if card, ok := cache.ZCard("test"); !ok || card != 1 {
+		t.Errorf("ZCard after re-add = %d, %v, want 1, true", card, ok)
+	}
+
+	// 默认不清理
+	plain := New()
+	plain.ZAddInt64("test", "a", 1)
+	plain.ZRem("test", "a")
+	if !plain.Exists("test") {
+		t.Error("without WithAutoCleanup the empty key should remain")
+	}
+}
+
+// TestWithAutoCleanupConcurrent 测试自动清理与并发写入不会丢失数据
+func TestWithAutoCleanupConcurrent(t *testing.T) {
+	cache := New(WithAutoCleanup())
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				member := fmt.Sprintf("g%d-%d", g, i)
+				cache.ZAddInt64("test", member, int64(i))
+				if _, ok := cache.ZScore("test", member); !ok {
+					// 只有本 goroutine 会删除自己的成员
+					t.Errorf("member %s lost after ZAdd", member)
+					return
+				}
+				cache.ZRem("test", member)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if cache.Exists("test") {
+		t.Error("key should be cleaned up after all members were removed")
+	}
+}
+
+// TestWithImmutableScores 测试只追加模式下已存在成员的分数不可修改
+func TestWithImmutableScores(t *testing.T) {
+	cache := New(WithImmutableScores())
+
+	if !cache.ZAddInt64("ledger", "tx1", 100) {
+		t.Fatal("ZAdd of a new member should succeed")
+	}
+	if !cache.ZAddInt64("ledger", "tx1", 100) {
+		t.Error("re-adding the same score should be a no-op success")
+	}
+	if cache.ZAddInt64("ledger", "tx1", 200) {
+		t.Error("ZAdd with a different score should fail")
+	}
+	if _, err := cache.ZAddString("ledger", "tx1", "300"); !errors.Is(err, ErrScoreImmutable) {
+		t.Errorf("ZAddString error = %v, want ErrScoreImmutable", err)
+	}
+	if _, ok := cache.ZIncrBy("ledger", "tx1", big.NewRat(1, 1)); ok {
+		t.Error("ZIncrBy on an existing member should fail")
+	}
+
+	// 批量写入中有违规成员时整批不写入
+	_, err := cache.ZAddMultipleString("ledger", map[string]string{"tx2": "5", "tx1": "1"})
+	if !errors.Is(err, ErrScoreImmutable) {
+		t.Errorf("ZAddMultipleString error = %v, want ErrScoreImmutable", err)
+	}
+	if _, ok := cache.ZScore("ledger", "tx2"); ok {
+		t.Error("rejected batch should not write any member")
+	}
+
+	// 同一批次中新成员先后以不同分数出现同样违反约束
+	batch := []ScoreMember{{Member: "tx9", Score: big.NewRat(1, 1)}, {Member: "tx9", Score: big.NewRat(2, 1)}}
+	if n := cache.ZAddBatch("ledger", batch); n != 0 {
+		t.Errorf("ZAddBatch with a conflicting duplicate = %d, want 0", n)
+	}
+	if _, ok := cache.ZScore("ledger", "tx9"); ok {
+		t.Error("rejected batch should not write the duplicated member")
+	}
+	batch[1].Score = big.NewRat(1, 1)
+	if n := cache.ZAddBatch("ledger", batch); n != 1 {
+		t.Errorf("ZAddBatch with an identical duplicate = %d, want 1", n)
+	}
+	if score, _ := cache.ZScore("ledger", "tx9"); score.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("tx9 score = %v, want 1", score)
+	}
+
+	// 新成员照常写入
+	if !cache.ZAddInt64("ledger", "tx2", 50) {
+		t.Error("ZAdd of another new member should succeed")
+	}
+	if _, ok := cache.ZIncrBy("ledger", "tx3", big.NewRat(7, 1)); !ok {
+		t.Error("ZIncrBy creating a new member should succeed")
+	}
+	if n := cache.ZMergeMax("ledger", map[string]*big.Rat{"tx1": big.NewRat(999, 1), "tx4": big.NewRat(1, 1)}); n != 1 {
+		t.Errorf("ZMergeMax = %d, want 1", n)
+	}
+
+	for member, want := range map[string]int64{"tx1": 100, "tx2": 50, "tx3": 7, "tx4": 1} {
+		if score, _ := cache.ZScore("ledger", member); score.Cmp(big.NewRat(want, 1)) != 0 {
+			t.Errorf("score(%s) = %v, want %d", member, score, want)
+		}
+	}
+}
+
+// TestSetScorePrecision 测试可配置的分数输出精度
+func TestSetScorePrecision(t *testing.T) {
+	cache := New()
+	cache.ZAdd("test", "a", big.NewRat(1, 3))
+	cache.ZAdd("test", "b", big.NewRat(5, 2))
+
+	// 默认 20 位，保持兼容
+	if s, _ := cache.ZScoreString("test", "b"); s != "2.50000000000000000000" {
+		t.Errorf("default ZScoreString = %s", s)
+	}
+
+	cache.SetScorePrecision(3)
+	if s, _ := cache.ZScoreString("test", "a"); s != "0.333" {
+		t.Errorf("ZScoreString with precision 3 = %s, want 0.333", s)
+	}
+	if got := cache.ZRange("test", 0, -1, true); got[1] != "0.333" || got[3] != "2.500" {
+		t.Errorf("ZRange withScores = %v", got)
+	}
+	if _, s, _ := cache.GetNextMemberString("test", "a"); s != "2.500" {
+		t.Errorf("GetNextMemberString = %s, want 2.500", s)
+	}
+
+	cache.SetScorePrecision(-1)
+	if s, _ := cache.ZScoreString("test", "b"); s != "3" {
+		t.Errorf("ZScoreString with precision 0 = %s, want 3", s)
+	}
+}
+
+// TestZScoreExact 测试精确分数字符串
+func TestZScoreExact(t *testing.T) {
+	cache := New()
+	cache.SetScorePrecision(2) // 不影响 ZScoreExact
+
+	cases := []struct {
+		score *big.Rat
+		want  string
+	}{
+		{big.NewRat(5, 2), "2.5"},
+		{big.NewRat(1, 3), "1/3"},
+		{big.NewRat(-7, 1), "-7"},
+		{big.NewRat(1, 1024), "0.0009765625"},
+		{big.NewRat(3, 80), "0.0375"},
+		{big.NewRat(1, 6), "1/6"},
+	}
+	for _, tc := range cases {
+		cache.ZAdd("test", "m", tc.score)
+		if got, ok := cache.ZScoreExact("test", "m"); !ok || got != tc.want {
+			t.Errorf("ZScoreExact(%v) = %s, %v, want %s", tc.score, got, ok, tc.want)
+		}
+	}
+	if _, ok := cache.ZScoreExact("test", "missing"); ok {
+		t.Error("ZScoreExact of missing member should return false")
+	}
+}
+
+// TestNewWithOptions 测试新建集合使用配置的跳表参数，以及非法配置返回错误
+func TestNewWithOptions(t *testing.T) {
+	cache, err := NewWithOptions(Options{MaxLevel: 8, P: 0.5})
+	if err != nil {
+		t.Fatalf("NewWithOptions: %v", err)
+	}
+	for i := 0; i < 2000; i++ {
+		cache.ZAddInt64("test", fmt.Sprintf("m%04d", i), int64(i))
+	}
+
+	stats, _ := cache.ZStats("test")
+	if stats.MaxLevel != 8 || stats.Level > 8 {
+		t.Errorf("MaxLevel = %d, Level = %d, want max 8", stats.MaxLevel, stats.Level)
+	}
+	if got := cache.ZRange("test", 0, 2, false); fmt.Sprint(got) != "[m0000 m0001 m0002]" {
+		t.Errorf("ZRange = %v", got)
+	}
+	if rank, _ := cache.ZRank("test", "m1500"); rank != 1500 {
+		t.Errorf("ZRank(m1500) = %d, want 1500", rank)
+	}
+	cache.ZRemRangeByScore("test", big.NewRat(0, 1), big.NewRat(999, 1))
+	if card, _ := cache.ZCard("test"); card != 1000 {
+		t.Errorf("ZCard = %d, want 1000", card)
+	}
+
+	// 零值字段使用默认值
+	cache, err = NewWithOptions(Options{}, WithLosslessStrings())
+	if err != nil {
+		t.Fatalf("NewWithOptions(zero): %v", err)
+	}
+	cache.ZAddInt64("test", "a", 1)
+	if stats, _ := cache.ZStats("test"); stats.MaxLevel != defaultMaxLevel {
+		t.Errorf("default MaxLevel = %d, want %d", stats.MaxLevel, defaultMaxLevel)
+	}
+
+	for _, o := range []Options{{MaxLevel: 65}, {MaxLevel: -1}, {P: 1}, {P: -0.5}} {
+		if c, err := NewWithOptions(o); c != nil || !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("NewWithOptions(%+v) = %v, %v, want ErrInvalidConfig", o, c, err)
+		}
+	}
+}
+
+// TestWithTieBreak 测试按成员名降序的并列规则
+func TestWithTieBreak(t *testing.T) {
+	cache := New(WithTieBreak(func(a, b string) bool { return a > b }))
+	for _, m := range []string{"a", "b", "c"} {
+		cache.ZAddInt64("test", m, 1)
+	}
+	cache.ZAddInt64("test", "z", 0)
+	cache.ZAddInt64("test", "y", 2)
+
+	if got := cache.ZRange("test", 0, -1, false); fmt.Sprint(got) != "[z c b a y]" {
+		t.Errorf("ZRange = %v, want [z c b a y]", got)
+	}
+	if rank, _ := cache.ZRank("test", "a"); rank != 3 {
+		t.Errorf("ZRank(a) = %d, want 3", rank)
+	}
+	cache.ZRem("test", "b")
+	if got := cache.ZRevRange("test", 0, -1, false); fmt.Sprint(got) != "[y a c z]" {
+		t.Errorf("ZRevRange after ZRem = %v, want [y a c z]", got)
+	}
+
+	// 直接返回的并集与存储后的顺序一致
+	cache.ZAddInt64("other", "b", 1)
+	if got, want := cache.ZUnion([]string{"test", "other"}, nil, "", false), []interface{}{"z", "c", "b", "a", "y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ZUnion = %v, want %v", got, want)
+	}
+	cache.ZUnionStoreLimit("top", []string{"test", "other"}, nil, "", 2)
+	if got := cache.ZRange("top", 0, -1, false); fmt.Sprint(got) != "[a y]" {
+		t.Errorf("ZUnionStoreLimit = %v, want [a y]", got)
+	}
+	checkSkipListInvariants(t, cache.getZSet("test").sl)
+}
+
+// TestWithCaseInsensitiveMembers 测试成员名不区分大小写时的查找、更新与删除
+func TestWithCaseInsensitiveMembers(t *testing.T) {
+	cache := New(WithCaseInsensitiveMembers())
+	cache.ZAddInt64("k", "Alice", 10)
+	cache.ZAddInt64("k", "bob", 20)
+
+	// 查找
+	if score, ok := cache.ZScore("k", "alice"); !ok || score.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Errorf("ZScore(alice) = %v, %v, want 10", score, ok)
+	}
+	if rank, ok := cache.ZRank("k", "BOB"); !ok || rank != 1 {
+		t.Errorf("ZRank(BOB) = %d, %v, want 1", rank, ok)
+	}
+
+	// 更新：分数跟随写入，显示形式跟随最近一次写入
+	cache.ZAddInt64("k", "ALICE", 30)
+	if card, _ := cache.ZCard("k"); card != 2 {
+		t.Errorf("ZCard = %d, want 2", card)
+	}
+	if got := cache.ZRange("k", 0, -1, false); fmt.Sprint(got) != "[bob ALICE]" {
+		t.Errorf("ZRange = %v, want [bob ALICE]", got)
+	}
+	cache.ZAddInt64("k", "Bob", 20)
+	if got := cache.ZRange("k", 0, -1, false); fmt.Sprint(got) != "[Bob ALICE]" {
+		t.Errorf("ZRange after same-score write = %v, want [Bob ALICE]", got)
+	}
+	cache.ZIncrByInt64("k", "aLiCe", 1)
+	if score, _ := cache.ZScore("k", "Alice"); score.Cmp(big.NewRat(31, 1)) != 0 {
+		t.Errorf("ZScore after ZIncrBy = %v, want 31", score)
+	}
+
+	// 分数相同时按归一化后的成员名排序
+	cache.ZAddInt64("tie", "b", 1)
+	cache.ZAddInt64("tie", "A", 1)
+	cache.ZAddInt64("tie", "C", 1)
+	if got := cache.ZRange("tie", 0, -1, false); fmt.Sprint(got) != "[A b C]" {
+		t.Errorf("tie order = %v, want [A b C]", got)
+	}
+	checkSkipListInvariants(t, cache.getZSet("tie").sl)
+
+	// 删除
+	if !cache.ZRem("k", "alice") {
+		t.Error("ZRem(alice) should remove ALICE")
+	}
+	if _, ok := cache.ZScore("k", "ALICE"); ok {
+		t.Error("ALICE should be gone")
+	}
+	if removed := cache.ZRemMultiple("k", []string{"BOB", "bob"}); removed != 1 {
+		t.Errorf("ZRemMultiple removed %d, want 1", removed)
+	}
+	if card, _ := cache.ZCard("k"); card != 0 {
+		t.Errorf("ZCard after removals = %d, want 0", card)
+	}
+
+	// 默认区分大小写
+	plain := New()
+	plain.ZAddInt64("k", "Alice", 1)
+	if _, ok := plain.ZScore("k", "alice"); ok {
+		t.Error("members should be case-sensitive by default")
+	}
+}
+
+// TestCaseInsensitiveSoftDelete 测试不区分大小写模式下的软删除与恢复
+func TestCaseInsensitiveSoftDelete(t *testing.T) {
+	cache := New(WithCaseInsensitiveMembers(), WithSoftDelete())
+	cache.ZAddInt64("k", "Alice", 5)
+	cache.ZRem("k", "ALICE")
+	if !cache.ZUndelete("k", "alice") {
+		t.Fatal("ZUndelete(alice) should restore Alice")
+	}
+	if score, ok := cache.ZScore("k", "Alice"); !ok || score.Cmp(big.NewRat(5, 1)) != 0 {
+		t.Errorf("restored score = %v, %v, want 5", score, ok)
+	}
+	if cache.ZUndelete("k", "Alice") {
+		t.Error("tombstone should be consumed by the first ZUndelete")
+	}
+}
+
+// TestCaseInsensitiveLex 测试不区分大小写模式下按字典序的查询与成员排列顺序一致
+func TestCaseInsensitiveLex(t *testing.T) {
+	cache := New(WithCaseInsensitiveMembers())
+	for _, m := range []string{"Bob", "alice", "Carol"} {
+		cache.ZAddInt64("k", m, 0)
+	}
+
+	for member, want := range map[string]int{"alice": 0, "ALICE": 0, "Bob": 1, "bob": 1, "carol": 2} {
+		if rank, ok := cache.ZLexRank("k", member); !ok || rank != want {
+			t.Errorf("ZLexRank(%s) = %d, %v, want %d", member, rank, ok, want)
+		}
+	}
+	if rank, ok := cache.ZLexRank("k", "dave"); ok || rank != -1 {
+		t.Errorf("ZLexRank(dave) = %d, %v, want -1, false", rank, ok)
+	}
+
+	if got := cache.ZRangeByLex("k", "[a", "[c", 0, 0); fmt.Sprint(got) != "[alice Bob]" {
+		t.Errorf("ZRangeByLex([a, [c) = %v, want [alice Bob]", got)
+	}
+	if got := cache.ZRangeByLex("k", "(BOB", "+", 0, 0); fmt.Sprint(got) != "[Carol]" {
+		t.Errorf("ZRangeByLex((BOB, +) = %v, want [Carol]", got)
+	}
+	if got := cache.ZRevRangeByLex("k", "[CAROL", "[b", 0, 0); fmt.Sprint(got) != "[Carol Bob]" {
+		t.Errorf("ZRevRangeByLex([CAROL, [b) = %v, want [Carol Bob]", got)
+	}
+	if got := cache.ZRevRangeByLex("k", "+", "-", 0, 0); fmt.Sprint(got) != "[Carol Bob alice]" {
+		t.Errorf("ZRevRangeByLex(+, -) = %v, want [Carol Bob alice]", got)
+	}
+}
+
+// TestCaseInsensitiveRankMembers 测试不区分大小写模式下批量排名按归一化后的成员名去重并返回保存的成员名
+func TestCaseInsensitiveRankMembers(t *testing.T) {
+	cache, err := NewWithOptions(Options{CaseInsensitiveMembers: true})
+	if err != nil {
+		t.Fatalf("NewWithOptions: %v", err)
+	}
+	cache.ZAddInt64("k", "Alice", 1)
+	cache.ZAddInt64("k", "bob", 2)
+	cache.ZAddInt64("k", "Carol", 3)
+
+	got := cache.ZSubsetRanked("k", []string{"CAROL", "alice", "ALICE", "dave"})
+	if len(got) != 2 {
+		t.Fatalf("ZSubsetRanked = %v, want 2 members", got)
+	}
+	if got[0].Member != "Alice" || got[0].Rank != 0 || got[1].Member != "Carol" || got[1].Rank != 2 {
+		t.Errorf("ZSubsetRanked = %+v, want Alice@0, Carol@2", got)
+	}
+
+	ranks := cache.ZRankMulti("k", []string{"BOB", "Bob", "carol"})
+	if len(ranks) != 2 || ranks["bob"] != 1 || ranks["Carol"] != 2 {
+		t.Errorf("ZRankMulti = %v, want map[Carol:2 bob:1]", ranks)
+	}
+}
+
+// TestCaseInsensitiveSetOps 测试不区分大小写模式下集合运算按归一化后的成员名合并
+func TestCaseInsensitiveSetOps(t *testing.T) {
+	cache := New(WithCaseInsensitiveMembers())
+	cache.ZAddInt64("u1", "Alice", 1)
+	cache.ZAddInt64("u1", "bob", 5)
+	cache.ZAddInt64("u2", "alice", 2)
+	cache.ZAddInt64("u2", "Carol", 3)
+
+	if n := cache.ZUnionStore("sum", []string{"u1", "u2"}, nil, ""); n != 3 {
+		t.Errorf("ZUnionStore = %d, want 3", n)
+	}
+	if score, ok := cache.ZScore("sum", "ALICE"); !ok || score.Cmp(big.NewRat(3, 1)) != 0 {
+		t.Errorf("union score(alice) = %v, %v, want 3", score, ok)
+	}
+	if got := cache.ZUnion([]string{"u1", "u2"}, nil, "", false); fmt.Sprint(got) != "[alice Carol bob]" {
+		t.Errorf("ZUnion = %v, want [alice Carol bob]", got)
+	}
+	if got := cache.ZInter([]string{"u2", "u1"}, nil, "max", false); fmt.Sprint(got) != "[Alice]" {
+		t.Errorf("ZInter = %v, want [Alice]", got)
+	}
+	if n := cache.ZInterStore("inter", []string{"u1", "u2"}, nil, ""); n != 1 {
+		t.Errorf("ZInterStore = %d, want 1", n)
+	}
+	if got := cache.ZDiff("u1", "u2"); len(got) != 1 || got[0].Member != "bob" {
+		t.Errorf("ZDiff = %v, want [bob]", got)
+	}
+
+	cache.ZAddInt64("v1", "Alice", 1)
+	cache.ZAddInt64("v2", "ALICE", 1)
+	if !cache.ZEqual("v1", "v2") {
+		t.Error("ZEqual should match members that differ only in case")
+	}
+
+	// 默认区分大小写时两种写法是不同的成员
+	plain := New()
+	plain.ZAddInt64("u1", "Alice", 1)
+	plain.ZAddInt64("u2", "alice", 2)
+	if n := plain.ZUnionStore("sum", []string{"u1", "u2"}, nil, ""); n != 2 {
+		t.Errorf("case-sensitive ZUnionStore = %d, want 2", n)
+	}
+}
@@ -0,0 +1,287 @@
+package csort
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// snapshotVersion Save 写出的数据格式版本号
+//
+// 格式（所有整数均为 uvarint，字符串与字节串均带长度前缀）：
+//
+//	version(1 字节) keyCount { key memberCount { member score value } }
+//
+// score 为 big.Rat 的 GobEncode 编码，完整保留分子与分母；value 为成员附带的数据。
+// 版本 1 不含 value，Load 仍可读取
+const snapshotVersion byte = 2
+
+// maxSnapshotLen 单个字符串或字节串允许的最大长度，防止损坏的数据触发超大内存分配
+const maxSnapshotLen = 1 << 30
+
+// ==================== Save / Load ====================
+
+// Save 将整个实例的所有 key、成员及精确分数序列化写入 w
+// 在整个存储上取得一致性读锁后复制数据，释放锁后再写入 w，写入期间不阻塞其他操作；
+// 保存有效成员及其附带的数据，配置项、软删除的墓碑、过期时间等不会被保存，已过期的 key 会被跳过
+func (c *CacheZSort) Save(w io.Writer) error {
+	keys, data := c.snapshotAll()
+
+	bw := bufio.NewWriter(w)
+	if err := bw.WriteByte(snapshotVersion); err != nil {
+		return err
+	}
+	writeUvarint(bw, uint64(len(keys)))
+	for _, key := range keys {
+		writeBytes(bw, []byte(key))
+		writeUvarint(bw, uint64(len(data[key])))
+		for _, sm := range data[key] {
+			writeBytes(bw, []byte(sm.Member))
+			score, err := sm.Score.GobEncode()
+			if err != nil {
+				return err
+			}
+			writeBytes(bw, score)
+			writeBytes(bw, sm.Value)
+		}
+	}
+	return bw.Flush()
+}
+
+// snapshotAll 在持有实例读锁及所有集合读锁的情况下获取全部数据，返回排序后的 key 列表
+func (c *CacheZSort) snapshotAll() ([]string, map[string][]ScoreMember) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.sets))
+	sets := make(map[string]*ZSet, len(c.sets))
+	for key, set := range c.sets {
+		if !c.expired(set) {
+			keys = append(keys, key)
+			sets[key] = set
+		}
+	}
+	sort.Strings(keys)
+
+	runlock := rlockZSets(sets)
+	defer runlock()
+
+	data := make(map[string][]ScoreMember, len(keys))
+	for _, key := range keys {
+		data[key] = sets[key].sl.allShared()
+	}
+	return keys, data
+}
+
+// Load 从 r 读取 Save 写出的数据并创建新实例，opts 为新实例的配置项
+// 版本号不受支持或数据损坏时返回包装了 ErrInvalidSnapshot 的错误
+func Load(r io.Reader, opts ...Option) (*CacheZSort, error) {
+	br := bufio.NewReader(r)
+
+	version, err := br.ReadByte()
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+	if version < 1 || version > snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+
+	c := New(opts...)
+	keyCount, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+	for i := uint64(0); i < keyCount; i++ {
+		key, err := readBytes(br)
+		if err != nil {
+			return nil, err
+		}
+		memberCount, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, snapshotError(err)
+		}
+
+		set := c.newZSet()
+		for j := uint64(0); j < memberCount; j++ {
+			member, err := readBytes(br)
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := readBytes(br)
+			if err != nil {
+				return nil, err
+			}
+			score := new(big.Rat)
+			if err := score.GobDecode(encoded); err != nil {
+				return nil, snapshotError(err)
+			}
+			node := set.sl.insertInternal(string(member), score)
+			if version >= 2 {
+				value, err := readBytes(br)
+				if err != nil {
+					return nil, err
+				}
+				if len(value) > 0 {
+					node.value = value
+				}
+			}
+		}
+		c.sets[string(key)] = set
+	}
+	c.publishSetsLocked()
+	return c, nil
+}
+
+// snapshotError 将读取过程中的错误包装为 ErrInvalidSnapshot
+func snapshotError(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+}
+
+// writeUvarint 写入 uvarint 编码的整数，错误由 bufio.Writer 记录并在 Flush 时返回
+func writeUvarint(w *bufio.Writer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	w.Write(buf[:n])
+}
+
+// writeBytes 写入带长度前缀的字节串
+func writeBytes(w *bufio.Writer, b []byte) {
+	writeUvarint(w, uint64(len(b)))
+	w.Write(b)
+}
+
+// readBytes 读取带长度前缀的字节串
+func readBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+	if n > maxSnapshotLen {
+		return nil, fmt.Errorf("%w: length %d too large", ErrInvalidSnapshot, n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, snapshotError(err)
+	}
+	return b, nil
+}
+
+// ==================== ExportJSON / ImportJSON ====================
+
+// jsonMember 单个有序集合 JSON 导入导出时的成员格式
+type jsonMember struct {
+	Member string `json:"member"`
+	Score  string `json:"score"` // 精确有理数字符串，如 "1/3"、"-5"
+}
+
+// ExportJSON 将单个有序集合导出为 JSON 数组，按分数升序排列
+// 每个元素形如 {"member": "a", "score": "1/3"}，分数使用精确有理数字符串，不丢失精度；
+// key 不存在时返回 ErrKeyNotFound
+func (c *CacheZSort) ExportJSON(key string) ([]byte, error) {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	members := set.sl.All()
+	out := make([]jsonMember, len(members))
+	for i, sm := range members {
+		out[i] = jsonMember{Member: sm.Member, Score: sm.Score.RatString()}
+	}
+	return json.Marshal(out)
+}
+
+// ImportJSON 从 ExportJSON 格式的数据创建或整体替换 key 对应的有序集合
+// JSON 格式错误或分数字符串非法时返回包装了 ErrInvalidScore 的错误，且不修改 key；
+// 同一成员出现多次时以最后一次为准
+func (c *CacheZSort) ImportJSON(key string, data []byte) error {
+	var in []jsonMember
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidScore, err)
+	}
+
+	members := make(map[string]*big.Rat, len(in))
+	for _, jm := range in {
+		score, ok := new(big.Rat).SetString(jm.Score)
+		if !ok {
+			return fmt.Errorf("%w: member %q has score %q", ErrInvalidScore, jm.Member, jm.Score)
+		}
+		members[jm.Member] = score
+	}
+	return c.replaceZSet(key, members)
+}
+
+// ==================== Dump / Restore ====================
+
+// dumpVersion Dump 写出的单成员数据格式版本号
+//
+// 格式：version(1 字节) score value，score 与 value 均为 uvarint 长度前缀的字节串，
+// score 为 big.Rat 的 GobEncode 编码
+const dumpVersion byte = 1
+
+// Dump 将单个成员的精确分数（分子与分母）及附带数据序列化，用于在实例之间转移
+// 成员或 key 不存在时返回 false
+func (c *CacheZSort) Dump(key, member string) ([]byte, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return nil, false
+	}
+	set.sl.mu.RLock()
+	node, ok := set.sl.memberMap[set.sl.key(member)]
+	if !ok {
+		set.sl.mu.RUnlock()
+		return nil, false
+	}
+	score, err := node.score.GobEncode()
+	value := bytes.Clone(node.value)
+	set.sl.mu.RUnlock()
+	if err != nil {
+		return nil, false
+	}
+
+	data := []byte{dumpVersion}
+	data = binary.AppendUvarint(data, uint64(len(score)))
+	data = append(data, score...)
+	data = binary.AppendUvarint(data, uint64(len(value)))
+	data = append(data, value...)
+	return data, true
+}
+
+// Restore 将 Dump 得到的数据写入 key 的 member（key 不存在时创建），覆盖其分数与附带数据
+// 数据无法解析时返回包装了 ErrInvalidSnapshot 的错误且不做修改；写入失败时返回相应错误
+func (c *CacheZSort) Restore(key, member string, data []byte) error {
+	if len(data) == 0 || data[0] != dumpVersion {
+		return fmt.Errorf("%w: unsupported dump payload", ErrInvalidSnapshot)
+	}
+	br := bufio.NewReader(bytes.NewReader(data[1:]))
+	encoded, err := readBytes(br)
+	if err != nil {
+		return err
+	}
+	value, err := readBytes(br)
+	if err != nil {
+		return err
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return fmt.Errorf("%w: trailing data", ErrInvalidSnapshot)
+	}
+	score := new(big.Rat)
+	if err := score.GobDecode(encoded); err != nil {
+		return snapshotError(err)
+	}
+	if len(value) == 0 {
+		value = nil
+	}
+	return c.zaddNode(key, member, score, nil, func(node *skipNode) {
+		node.value = value
+	})
+}
@@ -0,0 +1,196 @@
+package csort
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// TestSaveLoadRoundTrip 测试整个实例的保存与恢复，分数精度无损
+func TestSaveLoadRoundTrip(t *testing.T) {
+	cache := New()
+	huge, _ := new(big.Rat).SetString("123456789012345678901234567890.123456789012345678901234567890")
+	cache.ZAdd("prices", "huge", huge)
+	cache.ZAdd("prices", "third", big.NewRat(1, 3))
+	cache.ZAdd("prices", "neg", big.NewRat(-22, 7))
+	cache.ZAddInt64("ranks", "alice", 100)
+	cache.ZAddInt64("ranks", "bob", 100)
+	cache.ZAddInt64("ranks", "", 0) // 空成员名
+	cache.ZAddWithValue("ranks", "dave", big.NewRat(5, 1), []byte("Dave D."))
+
+	var buf bytes.Buffer
+	if err := cache.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if buf.Bytes()[0] != snapshotVersion {
+		t.Errorf("first byte = %d, want version %d", buf.Bytes()[0], snapshotVersion)
+	}
+
+	loaded, err := Load(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	for _, key := range []string{"prices", "ranks"} {
+		want := cache.getZSet(key).sl.All()
+		got := loaded.getZSet(key).sl.All()
+		if len(got) != len(want) {
+			t.Fatalf("%s: loaded %d members, want %d", key, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].Member != want[i].Member || got[i].Score.Cmp(want[i].Score) != 0 || !bytes.Equal(got[i].Value, want[i].Value) {
+				t.Errorf("%s[%d] = %s %v, want %s %v", key, i, got[i].Member, got[i].Score, want[i].Member, want[i].Score)
+			}
+		}
+	}
+	if keys := loaded.Keys(); len(keys) != 2 {
+		t.Errorf("loaded keys = %v, want 2 keys", keys)
+	}
+
+	// 恢复后的实例可正常写入
+	loaded.ZAddInt64("ranks", "carol", 1)
+	if card, _ := loaded.ZCard("ranks"); card != 5 {
+		t.Errorf("ZCard after write = %d, want 5", card)
+	}
+}
+
+// TestLoadVersion1 测试读取不含成员附带数据的版本 1 格式
+func TestLoadVersion1(t *testing.T) {
+	// 版本 1：1 个 key "k"，1 个成员 "m"，分数 3/2
+	score, _ := big.NewRat(3, 2).GobEncode()
+	data := []byte{1, 1, 1, 'k', 1, 1, 'm', byte(len(score))}
+	data = append(data, score...)
+
+	loaded, err := Load(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got, ok := loaded.ZScore("k", "m"); !ok || got.Cmp(big.NewRat(3, 2)) != 0 {
+		t.Errorf("ZScore = %v, %v, want 3/2, true", got, ok)
+	}
+}
+
+// TestLoadInvalid 测试不支持的版本与截断的数据
+func TestLoadInvalid(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "a", 1)
+	var buf bytes.Buffer
+	cache.Save(&buf)
+	data := buf.Bytes()
+
+	inputs := map[string][]byte{
+		"empty":     nil,
+		"version":   append([]byte{snapshotVersion + 1}, data[1:]...),
+		"truncated": data[:len(data)-2],
+	}
+	for name, input := range inputs {
+		if _, err := Load(bytes.NewReader(input)); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("%s: Load error = %v, want ErrInvalidSnapshot", name, err)
+		}
+	}
+}
+
+// TestExportImportJSON 测试单个有序集合的 JSON 导入导出
+func TestExportImportJSON(t *testing.T) {
+	cache := New()
+	cache.ZAdd("test", "third", big.NewRat(1, 3))
+	cache.ZAdd("test", "neg", big.NewRat(-5, 2))
+	cache.ZAddInt64("test", "int", 7)
+
+	data, err := cache.ExportJSON("test")
+	if err != nil {
+		t.Fatalf("ExportJSON: %v", err)
+	}
+	want := `[{"member":"neg","score":"-5/2"},{"member":"third","score":"1/3"},{"member":"int","score":"7"}]`
+	if string(data) != want {
+		t.Errorf("ExportJSON = %s, want %s", data, want)
+	}
+
+	// 导入到新 key，并替换已有 key 的内容
+	other := New()
+	other.ZAddInt64("copy", "stale", 1)
+	if err := other.ImportJSON("copy", data); err != nil {
+		t.Fatalf("ImportJSON: %v", err)
+	}
+	if _, ok := other.ZScore("copy", "stale"); ok {
+		t.Error("ImportJSON should replace the existing set")
+	}
+	for _, member := range []string{"neg", "third", "int"} {
+		got, _ := other.ZScore("copy", member)
+		orig, _ := cache.ZScore("test", member)
+		if got == nil || got.Cmp(orig) != 0 {
+			t.Errorf("imported score(%s) = %v, want %v", member, got, orig)
+		}
+	}
+
+	if _, err := cache.ExportJSON("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("ExportJSON(missing) error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+// TestImportJSONInvalid 测试非法 JSON 与非法分数
+func TestImportJSONInvalid(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "keep", 1)
+
+	inputs := []string{
+		`not json`,
+		`{"member":"a","score":"1"}`,
+		`[{"member":"a","score":"abc"}]`,
+		`[{"member":"a","score":1}]`,
+		`[{"member":"a","score":"1/0"}]`,
+	}
+	for _, input := range inputs {
+		if err := cache.ImportJSON("test", []byte(input)); !errors.Is(err, ErrInvalidScore) {
+			t.Errorf("ImportJSON(%s) error = %v, want ErrInvalidScore", input, err)
+		}
+	}
+	if _, ok := cache.ZScore("test", "keep"); !ok {
+		t.Error("failed import should not modify the key")
+	}
+}
+
+// TestDumpRestore 测试单个成员的序列化与恢复，超出 float64 精度的分数不丢失
+func TestDumpRestore(t *testing.T) {
+	src := New()
+	score, _ := new(big.Rat).SetString("12345678901234567890123456789/7")
+	if f, _ := score.Float64(); new(big.Rat).SetFloat64(f).Cmp(score) == 0 {
+		t.Fatal("test score should not be exactly representable as float64")
+	}
+	src.ZAddWithValue("board", "alice", score, []byte("meta"))
+
+	data, ok := src.Dump("board", "alice")
+	if !ok {
+		t.Fatal("Dump should succeed")
+	}
+	if _, ok := src.Dump("board", "missing"); ok {
+		t.Error("Dump of a missing member should fail")
+	}
+
+	dst := New()
+	if err := dst.Restore("other", "alice", data); err != nil {
+		t.Fatalf("Restore error: %v", err)
+	}
+	if got, _ := dst.ZScore("other", "alice"); got.Cmp(score) != 0 {
+		t.Errorf("restored score = %v, want %v", got.RatString(), score.RatString())
+	}
+	if value, _ := dst.ZGetValue("other", "alice"); string(value) != "meta" {
+		t.Errorf("restored value = %q, want meta", value)
+	}
+
+	invalid := [][]byte{
+		nil,
+		{0xff},
+		data[:len(data)-2],
+		append(append([]byte{}, data...), 0),
+	}
+	for _, payload := range invalid {
+		if err := dst.Restore("other", "bob", payload); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Restore(%v) error = %v, want ErrInvalidSnapshot", payload, err)
+		}
+	}
+	if _, ok := dst.ZScore("other", "bob"); ok {
+		t.Error("invalid payloads should not write the member")
+	}
+}
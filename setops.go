@@ -0,0 +1,574 @@
+package csort
+
+import (
+	"bytes"
+	"container/heap"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// 聚合方式，用于 ZUnionStore 等多集合运算
+const (
+	AggregateSum = "sum"
+	AggregateMin = "min"
+	AggregateMax = "max"
+)
+
+// normalizeAggregate 规范化聚合方式，空字符串视为 sum；不支持的方式返回 false
+func normalizeAggregate(aggregate string) (string, bool) {
+	switch agg := strings.ToLower(aggregate); agg {
+	case "":
+		return AggregateSum, true
+	case AggregateSum, AggregateMin, AggregateMax:
+		return agg, true
+	default:
+		return "", false
+	}
+}
+
+// weightedSources 读取各源集合的一致性快照并乘以对应权重
+// weights 为 nil 时权重均为 1；权重数量与 keys 不一致时返回 false
+func (c *CacheZSort) weightedSources(keys []string, weights []*big.Rat) ([][]ScoreMember, bool) {
+	if weights != nil && len(weights) != len(keys) {
+		return nil, false
+	}
+
+	// 快照中的分数与跳表节点共享，后续运算只读取源分数，不会修改它们
+	snap := c.snapshotKeys(keys, true)
+	sources := make([][]ScoreMember, len(keys))
+	for i, key := range keys {
+		if weights == nil {
+			sources[i] = snap[key]
+			continue
+		}
+		// 同一 key 出现多次时各自独立计权，不能共享同一份结果
+		src := make([]ScoreMember, len(snap[key]))
+		for j, sm := range snap[key] {
+			src[j] = ScoreMember{Score: new(big.Rat).Mul(sm.Score, weights[i]), Member: sm.Member}
+		}
+		sources[i] = src
+	}
+	return sources, true
+}
+
+// combineScore 按聚合方式将 score 合并到 acc 中
+func combineScore(acc, score *big.Rat, aggregate string) {
+	switch aggregate {
+	case AggregateMin:
+		if score.Cmp(acc) < 0 {
+			acc.Set(score)
+		}
+	case AggregateMax:
+		if score.Cmp(acc) > 0 {
+			acc.Set(score)
+		}
+	default:
+		acc.Add(acc, score)
+	}
+}
+
+// memberNames 在不区分大小写模式下记录归一化名称对应的成员名，使多集合运算按归一化名称合并成员
+// fold 为 nil（区分大小写）时不做任何记录，成员名原样使用
+type memberNames struct {
+	fold  func(string) string
+	names map[string]string
+}
+
+// newMemberNames 创建使用 fold 归一化成员名的 memberNames
+func newMemberNames(fold func(string) string) *memberNames {
+	n := &memberNames{fold: fold}
+	if fold != nil {
+		n.names = make(map[string]string)
+	}
+	return n
+}
+
+// key 返回成员的归一化名称
+func (n *memberNames) key(member string) string {
+	if n.fold == nil {
+		return member
+	}
+	return n.fold(member)
+}
+
+// add 返回成员的归一化名称并记录其原始形式，后出现的形式覆盖先出现的
+func (n *memberNames) add(member string) string {
+	if n.fold == nil {
+		return member
+	}
+	k := n.fold(member)
+	n.names[k] = member
+	return k
+}
+
+// restore 将以归一化名称为键的结果换回最后记录的成员名
+func (n *memberNames) restore(scores map[string]*big.Rat) map[string]*big.Rat {
+	if n.fold == nil {
+		return scores
+	}
+	result := make(map[string]*big.Rat, len(scores))
+	for k, score := range scores {
+		result[n.names[k]] = score
+	}
+	return result
+}
+
+// unionScores 计算多个源集合的并集及聚合分数
+// 成员按 fold 归一化后合并（nil 表示区分大小写），结果使用最后一个包含该成员的源集合中的成员名
+func unionScores(sources [][]ScoreMember, aggregate string, fold func(string) string) map[string]*big.Rat {
+	names := newMemberNames(fold)
+	result := make(map[string]*big.Rat)
+	for _, src := range sources {
+		for _, sm := range src {
+			k := names.add(sm.Member)
+			if acc, ok := result[k]; ok {
+				combineScore(acc, sm.Score, aggregate)
+			} else {
+				result[k] = new(big.Rat).Set(sm.Score)
+			}
+		}
+	}
+	return names.restore(result)
+}
+
+// interScores 计算多个源集合的交集及聚合分数
+// 以成员最少的源集合驱动遍历，其余集合建立索引用于存在性判断；成员名的处理同 unionScores
+func interScores(sources [][]ScoreMember, aggregate string, fold func(string) string) map[string]*big.Rat {
+	names := newMemberNames(fold)
+	result := make(map[string]*big.Rat)
+	if len(sources) == 0 {
+		return result
+	}
+
+	smallest := 0
+	for i, src := range sources {
+		if len(src) < len(sources[smallest]) {
+			smallest = i
+		}
+	}
+	if len(sources[smallest]) == 0 {
+		return result
+	}
+
+	indexes := make([]map[string]*big.Rat, len(sources))
+	for i, src := range sources {
+		if i == smallest {
+			continue
+		}
+		index := make(map[string]*big.Rat, len(src))
+		for _, sm := range src {
+			index[names.key(sm.Member)] = sm.Score
+		}
+		indexes[i] = index
+	}
+
+next:
+	for _, sm := range sources[smallest] {
+		k := names.key(sm.Member)
+		acc := new(big.Rat)
+		for i := range sources {
+			score := sm.Score
+			if i != smallest {
+				var ok bool
+				if score, ok = indexes[i][k]; !ok {
+					continue next
+				}
+			}
+			if i == 0 {
+				acc.Set(score)
+			} else {
+				combineScore(acc, score, aggregate)
+			}
+		}
+		result[k] = acc
+	}
+
+	if fold != nil {
+		// 按源集合顺序记录成员名，使结果与 unionScores 一样使用最后一个源集合中的形式
+		for _, src := range sources {
+			for _, sm := range src {
+				if _, ok := result[names.key(sm.Member)]; ok {
+					names.add(sm.Member)
+				}
+			}
+		}
+	}
+	return names.restore(result)
+}
+
+// combineScores 校验聚合方式与权重后，用 combine（unionScores 或 interScores）合并各源集合的分数
+// 供 *Store 与直接返回结果的变体共用；aggregate 不支持或 weights 数量与 keys 不一致时返回 false
+func (c *CacheZSort) combineScores(keys []string, weights []*big.Rat, aggregate string, combine func([][]ScoreMember, string, func(string) string) map[string]*big.Rat) (map[string]*big.Rat, bool) {
+	agg, ok := normalizeAggregate(aggregate)
+	if !ok {
+		return nil, false
+	}
+	sources, ok := c.weightedSources(keys, weights)
+	if !ok {
+		return nil, false
+	}
+	return combine(sources, agg, c.memberFold()), true
+}
+
+// sortedOutput 将合并结果按集合顺序（分数升序，分数相同时按 memberLess）排列为 ZRange 风格的输出
+func (c *CacheZSort) sortedOutput(scores map[string]*big.Rat, withScores bool) []interface{} {
+	sorted := make([]ScoreMember, 0, len(scores))
+	for member, score := range scores {
+		sorted = append(sorted, ScoreMember{Score: score, Member: member})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return scoreLess(sorted[i], sorted[j], c.memberLess)
+	})
+
+	size := len(sorted)
+	if withScores {
+		size *= 2
+	}
+	output := make([]interface{}, 0, size)
+	for _, sm := range sorted {
+		output = append(output, sm.Member)
+		if withScores {
+			output = append(output, c.formatScore(sm.Score))
+		}
+	}
+	return output
+}
+
+// storeZSet 用给定成员构建新集合并替换 dest 当前的内容，返回结果基数
+// 结果为空且开启了自动清理时直接删除 dest；因 key 数量达到上限而无法创建 dest 时返回 0
+func (c *CacheZSort) storeZSet(dest string, members map[string]*big.Rat) int {
+	if err := c.replaceZSet(dest, members); err != nil {
+		return 0
+	}
+	return len(members)
+}
+
+// replaceZSet 用给定成员构建新集合并替换 dest 当前的内容
+// 配置了 WithMaxKeysStrict 且需要新建 dest 但 key 数量已达上限时返回 ErrTooManyKeys
+func (c *CacheZSort) replaceZSet(dest string, members map[string]*big.Rat) error {
+	set := c.newZSet()
+	for member, score := range members {
+		set.sl.insertInternal(member, score)
+	}
+
+	c.mu.Lock()
+	old, exists := c.sets[dest]
+	if !exists && c.maxKeys > 0 && len(c.sets) >= c.maxKeys {
+		c.mu.Unlock()
+		return ErrTooManyKeys
+	}
+	if exists {
+		// 标记旧集合已移除，使持有旧指针的写入方重新获取集合
+		old.markRemoved()
+	}
+
+	if len(members) == 0 && c.autoCleanup {
+		delete(c.sets, dest)
+	} else {
+		c.sets[dest] = set
+	}
+	c.publishSetsLocked()
+	wake := c.notifier.hasWaiters()
+	c.mu.Unlock()
+
+	// 整体替换不逐个发布成员事件，但要唤醒阻塞在 dest 上的弹出方
+	c.notifier.notify(dest, wake && len(members) > 0)
+	return nil
+}
+
+// ==================== ZUnionStore ====================
+
+// ZUnionStore 计算多个有序集合的并集并存入 dest，返回结果的成员数量
+// 每个源集合的分数先乘以 weights 中对应的权重（weights 为 nil 时均为 1），
+// 再按 aggregate（"sum"、"min"、"max"，空字符串视为 "sum"）合并同一成员的分数。
+// 不存在的 key 视为空集合；dest 可以是源 key 之一，结果会替换 dest 原有的内容。
+// weights 数量与 keys 不一致或 aggregate 不支持时不做任何修改并返回 0
+func (c *CacheZSort) ZUnionStore(dest string, keys []string, weights []*big.Rat, aggregate string) int {
+	scores, ok := c.combineScores(keys, weights, aggregate, unionScores)
+	if !ok {
+		return 0
+	}
+	return c.storeZSet(dest, scores)
+}
+
+// ZUnion 与 ZUnionStore 相同地计算并集，但不写入任何 key，直接按集合顺序返回结果
+// withScores 为 true 时成员与分数交替排列；参数非法时返回 nil
+func (c *CacheZSort) ZUnion(keys []string, weights []*big.Rat, aggregate string, withScores bool) []interface{} {
+	scores, ok := c.combineScores(keys, weights, aggregate, unionScores)
+	if !ok {
+		return nil
+	}
+	return c.sortedOutput(scores, withScores)
+}
+
+// ZUnionStoreLimit 与 ZUnionStore 相同，但聚合后只把分数最高的 limit 个成员存入 dest
+// 分数相同时按 ZRevRange 中靠前的成员优先；limit <= 0 时等同于 ZUnionStore。
+// 通过容量为 limit 的最小堆选出结果，无需对整个并集排序
+func (c *CacheZSort) ZUnionStoreLimit(dest string, keys []string, weights []*big.Rat, aggregate string, limit int) int {
+	scores, ok := c.combineScores(keys, weights, aggregate, unionScores)
+	if !ok {
+		return 0
+	}
+	return c.storeZSet(dest, topScores(scores, limit, c.memberLess))
+}
+
+// scoreHeap 按集合顺序（分数升序，分数相同时按 tieBreak）排列的最小堆，堆顶是当前保留结果中排名最低的成员
+type scoreHeap struct {
+	items    []ScoreMember
+	tieBreak func(a, b string) bool
+}
+
+// scoreLess 判断 a 在集合顺序中是否排在 b 之前，分数相同时按 tieBreak 比较成员（nil 表示按成员名升序）
+func scoreLess(a, b ScoreMember, tieBreak func(a, b string) bool) bool {
+	if cmp := compare(a.Score, b.Score); cmp != 0 {
+		return cmp < 0
+	}
+	if tieBreak == nil {
+		return a.Member < b.Member
+	}
+	return tieBreak(a.Member, b.Member)
+}
+
+func (h *scoreHeap) Len() int           { return len(h.items) }
+func (h *scoreHeap) Less(i, j int) bool { return scoreLess(h.items[i], h.items[j], h.tieBreak) }
+func (h *scoreHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *scoreHeap) Push(x any)         { h.items = append(h.items, x.(ScoreMember)) }
+func (h *scoreHeap) Pop() any {
+	old := h.items
+	x := old[len(old)-1]
+	h.items = old[:len(old)-1]
+	return x
+}
+
+// topScores 保留分数最高的 limit 个成员，limit <= 0 或成员数不超过 limit 时原样返回
+func topScores(scores map[string]*big.Rat, limit int, tieBreak func(a, b string) bool) map[string]*big.Rat {
+	if limit <= 0 || len(scores) <= limit {
+		return scores
+	}
+
+	h := &scoreHeap{items: make([]ScoreMember, 0, limit), tieBreak: tieBreak}
+	for member, score := range scores {
+		sm := ScoreMember{Score: score, Member: member}
+		if h.Len() < limit {
+			heap.Push(h, sm)
+			continue
+		}
+		// 只有排在堆顶之后的成员才能进入前 limit 名
+		if !scoreLess(h.items[0], sm, tieBreak) {
+			continue
+		}
+		h.items[0] = sm
+		heap.Fix(h, 0)
+	}
+
+	result := make(map[string]*big.Rat, len(h.items))
+	for _, sm := range h.items {
+		result[sm.Member] = sm.Score
+	}
+	return result
+}
+
+// ==================== ZInterStore ====================
+
+// ZInterStore 计算多个有序集合的交集并存入 dest，返回结果的成员数量
+// 只保留在所有源集合中都存在的成员，权重与聚合方式同 ZUnionStore。
+// 任一源 key 为空或不存在时结果为空集合，同样会覆盖 dest 原有的内容
+func (c *CacheZSort) ZInterStore(dest string, keys []string, weights []*big.Rat, aggregate string) int {
+	scores, ok := c.combineScores(keys, weights, aggregate, interScores)
+	if !ok {
+		return 0
+	}
+	return c.storeZSet(dest, scores)
+}
+
+// ZInter 与 ZInterStore 相同地计算交集，但不写入任何 key，直接按集合顺序返回结果
+// withScores 为 true 时成员与分数交替排列；参数非法时返回 nil
+func (c *CacheZSort) ZInter(keys []string, weights []*big.Rat, aggregate string, withScores bool) []interface{} {
+	scores, ok := c.combineScores(keys, weights, aggregate, interScores)
+	if !ok {
+		return nil
+	}
+	return c.sortedOutput(scores, withScores)
+}
+
+// ==================== ZDiff ====================
+
+// ZDiff 计算 key 与其他集合的差集
+// 返回 key 中不出现在任何 others 集合里的成员，保留原分数及排序；
+// key 为空或不存在时返回空结果，others 中包含 key 自身时结果为空
+func (c *CacheZSort) ZDiff(key string, others ...string) []ScoreMember {
+	snap := c.SnapshotKeys(append([]string{key}, others...))
+	base := snap[key]
+	if len(base) == 0 {
+		return nil
+	}
+
+	excluded := make(map[string]struct{})
+	for _, other := range others {
+		for _, sm := range snap[other] {
+			excluded[c.memberKey(sm.Member)] = struct{}{}
+		}
+	}
+
+	result := make([]ScoreMember, 0, len(base))
+	for _, sm := range base {
+		if _, ok := excluded[c.memberKey(sm.Member)]; !ok {
+			result = append(result, sm)
+		}
+	}
+	return result
+}
+
+// ZDiffStore 计算 key 与其他集合的差集并存入 dest，返回结果的成员数量
+// 结果会替换 dest 原有的内容，dest 可以是 key 或 others 之一
+func (c *CacheZSort) ZDiffStore(dest, key string, others ...string) int {
+	diff := c.ZDiff(key, others...)
+	members := make(map[string]*big.Rat, len(diff))
+	for _, sm := range diff {
+		members[sm.Member] = sm.Score
+	}
+	return c.storeZSet(dest, members)
+}
+
+// ==================== ZDiffByThreshold ====================
+
+// ScoreDiff 表示同一成员在两个集合中的分数，不存在的一侧为 nil
+type ScoreDiff struct {
+	Member string
+	ScoreA *big.Rat
+	ScoreB *big.Rat
+}
+
+// ZDiffByThreshold 找出两个集合之间分数变化超过 delta 的成员（|ScoreA - ScoreB| > delta）
+// 只出现在其中一个集合的成员视为差值无穷大，总会被返回；结果按成员名排序
+func (c *CacheZSort) ZDiffByThreshold(keyA, keyB string, delta *big.Rat) []ScoreDiff {
+	snap := c.SnapshotKeys([]string{keyA, keyB})
+
+	onlyB := make(map[string]ScoreMember, len(snap[keyB]))
+	for _, sm := range snap[keyB] {
+		onlyB[c.memberKey(sm.Member)] = sm
+	}
+
+	result := make([]ScoreDiff, 0)
+	diff := new(big.Rat)
+	for _, sm := range snap[keyA] {
+		k := c.memberKey(sm.Member)
+		b, ok := onlyB[k]
+		delete(onlyB, k)
+		if ok && diff.Sub(sm.Score, b.Score).Abs(diff).Cmp(delta) <= 0 {
+			continue
+		}
+		result = append(result, ScoreDiff{Member: sm.Member, ScoreA: sm.Score, ScoreB: b.Score})
+	}
+	for _, b := range onlyB {
+		result = append(result, ScoreDiff{Member: b.Member, ScoreB: b.Score})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Member < result[j].Member
+	})
+	return result
+}
+
+// ==================== ZEqual / ZDiffMembers ====================
+
+// ZEqual 判断两个集合是否包含相同的成员且各成员分数完全相等（使用 big.Rat.Cmp 精确比较）
+// 不存在的 key 视为空集合
+func (c *CacheZSort) ZEqual(key1, key2 string) bool {
+	onlyIn1, onlyIn2, scoreDiffers := c.ZDiffMembers(key1, key2)
+	return len(onlyIn1) == 0 && len(onlyIn2) == 0 && len(scoreDiffers) == 0
+}
+
+// ZDiffMembers 比较两个集合的成员，分别返回只在 key1 中、只在 key2 中以及两边分数不相等的成员
+// 分数使用 big.Rat.Cmp 精确比较；不存在的 key 视为空集合，各结果按成员名排序
+func (c *CacheZSort) ZDiffMembers(key1, key2 string) (onlyIn1, onlyIn2, scoreDiffers []string) {
+	snap := c.SnapshotKeys([]string{key1, key2})
+
+	rest2 := make(map[string]ScoreMember, len(snap[key2]))
+	for _, sm := range snap[key2] {
+		rest2[c.memberKey(sm.Member)] = sm
+	}
+	for _, sm := range snap[key1] {
+		k := c.memberKey(sm.Member)
+		sm2, ok := rest2[k]
+		delete(rest2, k)
+		switch {
+		case !ok:
+			onlyIn1 = append(onlyIn1, sm.Member)
+		case sm.Score.Cmp(sm2.Score) != 0:
+			scoreDiffers = append(scoreDiffers, sm.Member)
+		}
+	}
+	for _, sm := range rest2 {
+		onlyIn2 = append(onlyIn2, sm.Member)
+	}
+
+	sort.Strings(onlyIn1)
+	sort.Strings(onlyIn2)
+	sort.Strings(scoreDiffers)
+	return onlyIn1, onlyIn2, scoreDiffers
+}
+
+// ==================== ZCopy ====================
+
+// ZCopy 将 src 深拷贝到 dst：复制每个成员的分数与附带数据，与源集合不共享任何节点或分数指针
+// src 不存在、dst 已存在且 replace 为 false、或需要新建 dst 但 key 数量已达上限时返回 false。
+// 不复制 src 的过期时间
+func (c *CacheZSort) ZCopy(src, dst string, replace bool) bool {
+	source := c.getZSet(src)
+	if source == nil {
+		return false
+	}
+
+	// allShared 不复制分数，由 insertInternal 完成唯一一次复制；附带数据在此单独深拷贝
+	source.sl.mu.RLock()
+	members := source.sl.allShared()
+	source.sl.mu.RUnlock()
+
+	copied := c.newZSet()
+	for _, sm := range members {
+		copied.sl.insertInternal(sm.Member, sm.Score).value = bytes.Clone(sm.Value)
+	}
+
+	c.mu.Lock()
+	old, exists := c.sets[dst]
+	if exists && c.expired(old) {
+		c.dropSetLocked(dst, old)
+		exists = false
+	}
+	if exists && !replace {
+		c.mu.Unlock()
+		return false
+	}
+	if !exists && c.maxKeys > 0 && len(c.sets) >= c.maxKeys {
+		c.mu.Unlock()
+		return false
+	}
+	if exists {
+		// 标记旧集合已移除，使持有旧指针的写入方重新获取集合
+		old.markRemoved()
+	}
+
+	c.sets[dst] = copied
+	c.publishSetsLocked()
+	wake := c.notifier.hasWaiters()
+	c.mu.Unlock()
+
+	c.notifier.notify(dst, wake && len(members) > 0)
+	return true
+}
+
+// ==================== ZRangeStore ====================
+
+// ZRangeStore 将 src 中指定排名范围的成员（从0开始，闭区间，支持负数索引）连同分数存入 dst，返回 dst 的基数
+// reverse 为 true 时按倒序取排名。结果会替换 dst 原有的内容，dst 可以与 src 相同；
+// src 不存在或范围为空时清空 dst 并返回 0
+func (c *CacheZSort) ZRangeStore(dst, src string, start, stop int, reverse bool) int {
+	result := c.zrange(src, start, stop, reverse)
+	members := make(map[string]*big.Rat, len(result))
+	for _, sm := range result {
+		members[sm.Member] = sm.Score
+	}
+	return c.storeZSet(dst, members)
+}
@@ -0,0 +1,542 @@
+package csort
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// assertScores 校验 key 中的成员及分数与 want 完全一致
+func assertScores(t *testing.T, cache *CacheZSort, key string, want map[string]*big.Rat) {
+	t.Helper()
+
+	all := cache.ZRange(key, 0, -1, false)
+	if len(all) != len(want) {
+		t.Fatalf("%s has %d members, want %d: %v", key, len(all), len(want), all)
+	}
+	for member, score := range want {
+		got, ok := cache.ZScore(key, member)
+		if !ok {
+			t.Errorf("%s: member %s missing", key, member)
+			continue
+		}
+		if got.Cmp(score) != 0 {
+			t.Errorf("%s: score of %s = %s, want %s", key, member, got.RatString(), score.RatString())
+		}
+	}
+}
+
+// TestZUnionStore 测试并集存储的各聚合方式
+func TestZUnionStore(t *testing.T) {
+	newCache := func() *CacheZSort {
+		cache := New()
+		cache.ZAddInt64("s1", "a", 1)
+		cache.ZAddInt64("s1", "b", 2)
+		cache.ZAddInt64("s2", "b", 3)
+		cache.ZAddInt64("s2", "c", 4)
+		return cache
+	}
+
+	cases := []struct {
+		aggregate string
+		want      map[string]*big.Rat
+	}{
+		{"sum", map[string]*big.Rat{"a": big.NewRat(1, 1), "b": big.NewRat(5, 1), "c": big.NewRat(4, 1)}},
+		{"", map[string]*big.Rat{"a": big.NewRat(1, 1), "b": big.NewRat(5, 1), "c": big.NewRat(4, 1)}},
+		{"min", map[string]*big.Rat{"a": big.NewRat(1, 1), "b": big.NewRat(2, 1), "c": big.NewRat(4, 1)}},
+		{"MAX", map[string]*big.Rat{"a": big.NewRat(1, 1), "b": big.NewRat(3, 1), "c": big.NewRat(4, 1)}},
+	}
+	for _, tc := range cases {
+		cache := newCache()
+		n := cache.ZUnionStore("out", []string{"s1", "s2", "missing"}, nil, tc.aggregate)
+		if n != 3 {
+			t.Errorf("ZUnionStore(%q) = %d, want 3", tc.aggregate, n)
+		}
+		assertScores(t, cache, "out", tc.want)
+	}
+
+	// 带权重
+	cache := newCache()
+	n := cache.ZUnionStore("out", []string{"s1", "s2"}, []*big.Rat{big.NewRat(2, 1), big.NewRat(1, 2)}, "sum")
+	if n != 3 {
+		t.Errorf("weighted ZUnionStore = %d, want 3", n)
+	}
+	assertScores(t, cache, "out", map[string]*big.Rat{
+		"a": big.NewRat(2, 1),
+		"b": big.NewRat(11, 2), // 2*2 + 3/2
+		"c": big.NewRat(2, 1),
+	})
+
+	// dest 为源 key 之一，且会替换原有内容
+	cache = newCache()
+	cache.ZUnionStore("s1", []string{"s1", "s2"}, nil, "max")
+	assertScores(t, cache, "s1", map[string]*big.Rat{
+		"a": big.NewRat(1, 1), "b": big.NewRat(3, 1), "c": big.NewRat(4, 1),
+	})
+	assertScores(t, cache, "s2", map[string]*big.Rat{"b": big.NewRat(3, 1), "c": big.NewRat(4, 1)})
+
+	// 参数不合法时不修改 dest
+	cache = newCache()
+	if n := cache.ZUnionStore("s1", []string{"s2"}, []*big.Rat{big.NewRat(1, 1), big.NewRat(1, 1)}, "sum"); n != 0 {
+		t.Errorf("ZUnionStore with mismatched weights = %d, want 0", n)
+	}
+	if n := cache.ZUnionStore("s1", []string{"s2"}, nil, "avg"); n != 0 {
+		t.Errorf("ZUnionStore with unknown aggregate = %d, want 0", n)
+	}
+	assertScores(t, cache, "s1", map[string]*big.Rat{"a": big.NewRat(1, 1), "b": big.NewRat(2, 1)})
+}
+
+// TestZInterStore 测试交集存储
+func TestZInterStore(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("s1", "a", 1)
+	cache.ZAddInt64("s1", "b", 2)
+	cache.ZAddInt64("s1", "c", 3)
+	cache.ZAddInt64("s2", "b", 10)
+	cache.ZAddInt64("s2", "c", 20)
+	cache.ZAddInt64("s2", "d", 30)
+	cache.ZAddInt64("s3", "c", 100)
+	cache.ZAddInt64("s3", "b", 200)
+
+	n := cache.ZInterStore("out", []string{"s1", "s2", "s3"}, nil, "sum")
+	if n != 2 {
+		t.Fatalf("ZInterStore(sum) = %d, want 2", n)
+	}
+	assertScores(t, cache, "out", map[string]*big.Rat{"b": big.NewRat(212, 1), "c": big.NewRat(123, 1)})
+
+	cache.ZInterStore("out", []string{"s1", "s2", "s3"}, nil, "min")
+	assertScores(t, cache, "out", map[string]*big.Rat{"b": big.NewRat(2, 1), "c": big.NewRat(3, 1)})
+
+	cache.ZInterStore("out", []string{"s1", "s2", "s3"}, nil, "max")
+	assertScores(t, cache, "out", map[string]*big.Rat{"b": big.NewRat(200, 1), "c": big.NewRat(100, 1)})
+
+	// 带权重
+	weights := []*big.Rat{big.NewRat(1, 1), big.NewRat(-1, 1), big.NewRat(1, 10)}
+	cache.ZInterStore("out", []string{"s1", "s2", "s3"}, weights, "sum")
+	assertScores(t, cache, "out", map[string]*big.Rat{
+		"b": big.NewRat(12, 1), // 2 - 10 + 20
+		"c": big.NewRat(-7, 1), // 3 - 20 + 10
+	})
+
+	// 源 key 不存在时结果为空并覆盖 dest
+	n = cache.ZInterStore("out", []string{"s1", "missing"}, nil, "sum")
+	if n != 0 {
+		t.Errorf("ZInterStore with missing source = %d, want 0", n)
+	}
+	if card, ok := cache.ZCard("out"); !ok || card != 0 {
+		t.Errorf("ZCard(out) = %d, %v, want 0, true (dest overwritten with empty set)", card, ok)
+	}
+}
+
+// TestZDiff 测试差集
+func TestZDiff(t *testing.T) {
+	cache := New()
+	for i := 0; i < 20; i++ {
+		cache.ZAddInt64("base", fmt.Sprintf("m%02d", i), int64(20-i))
+	}
+	for i := 0; i < 20; i += 3 {
+		cache.ZAddInt64("o1", fmt.Sprintf("m%02d", i), 0)
+	}
+	for i := 0; i < 20; i += 5 {
+		cache.ZAddInt64("o2", fmt.Sprintf("m%02d", i), 0)
+	}
+
+	// 手工计算期望的差集（按分数升序）
+	var want []string
+	for i := 19; i >= 0; i-- {
+		if i%3 != 0 && i%5 != 0 {
+			want = append(want, fmt.Sprintf("m%02d", i))
+		}
+	}
+
+	diff := cache.ZDiff("base", "o1", "o2", "missing")
+	if len(diff) != len(want) {
+		t.Fatalf("ZDiff returned %d members, want %d", len(diff), len(want))
+	}
+	for i, sm := range diff {
+		if sm.Member != want[i] {
+			t.Errorf("ZDiff[%d] = %s, want %s", i, sm.Member, want[i])
+		}
+		if score, _ := cache.ZScore("base", sm.Member); score.Cmp(sm.Score) != 0 {
+			t.Errorf("ZDiff[%d] score = %v, want original %v", i, sm.Score, score)
+		}
+	}
+
+	n := cache.ZDiffStore("out", "base", "o1", "o2")
+	if n != len(want) {
+		t.Errorf("ZDiffStore = %d, want %d", n, len(want))
+	}
+	if got := cache.ZRange("out", 0, -1, false); len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("ZDiffStore stored %v, want %v", got, want)
+	}
+
+	// 边界情况
+	if diff := cache.ZDiff("missing", "o1"); len(diff) != 0 {
+		t.Errorf("ZDiff on missing key returned %d members, want 0", len(diff))
+	}
+	if diff := cache.ZDiff("base", "o1", "base"); len(diff) != 0 {
+		t.Errorf("ZDiff with key in others returned %d members, want 0", len(diff))
+	}
+	if diff := cache.ZDiff("base"); len(diff) != 20 {
+		t.Errorf("ZDiff without others returned %d members, want 20", len(diff))
+	}
+	if n := cache.ZDiffStore("base", "base", "o1"); n != 13 {
+		t.Errorf("ZDiffStore into source = %d, want 13", n)
+	}
+}
+
+// TestZDiffByThreshold 测试按分数变化阈值找出显著变化的成员
+func TestZDiffByThreshold(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("before", "flat", 100)
+	cache.ZAddInt64("before", "edge", 100)
+	cache.ZAddInt64("before", "up", 100)
+	cache.ZAddInt64("before", "down", 100)
+	cache.ZAddInt64("before", "gone", 1)
+
+	cache.ZAddInt64("after", "flat", 103) // 变化 3，低于阈值
+	cache.ZAddInt64("after", "edge", 95)  // 变化 5，等于阈值，不算显著
+	cache.ZAddInt64("after", "up", 106)   // 变化 6
+	cache.ZAddInt64("after", "down", 90)  // 变化 10
+	cache.ZAddInt64("after", "new", 1)
+
+	got := cache.ZDiffByThreshold("before", "after", big.NewRat(5, 1))
+	want := []struct {
+		member         string
+		scoreA, scoreB *big.Rat
+	}{
+		{"down", big.NewRat(100, 1), big.NewRat(90, 1)},
+		{"gone", big.NewRat(1, 1), nil},
+		{"new", nil, big.NewRat(1, 1)},
+		{"up", big.NewRat(100, 1), big.NewRat(106, 1)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ZDiffByThreshold returned %d members, want %d: %v", len(got), len(want), got)
+	}
+	sameScore := func(a, b *big.Rat) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return a.Cmp(b) == 0
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Member != w.member || !sameScore(g.ScoreA, w.scoreA) || !sameScore(g.ScoreB, w.scoreB) {
+			t.Errorf("result[%d] = %s (%v, %v), want %s (%v, %v)", i, g.Member, g.ScoreA, g.ScoreB, w.member, w.scoreA, w.scoreB)
+		}
+	}
+
+	if got := cache.ZDiffByThreshold("missing", "missing2", big.NewRat(0, 1)); len(got) != 0 {
+		t.Errorf("ZDiffByThreshold on missing keys = %v, want empty", got)
+	}
+}
+
+// BenchmarkZUnionStore 基准测试并集运算（源快照共享节点分数，不做额外复制）
+func BenchmarkZUnionStore(b *testing.B) {
+	cache := New()
+	for i := 0; i < 10000; i++ {
+		cache.ZAddInt64("a", fmt.Sprintf("member%d", i), int64(i))
+		cache.ZAddInt64("b", fmt.Sprintf("member%d", i+5000), int64(i))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.ZUnionStore("dest", []string{"a", "b"}, nil, AggregateSum)
+	}
+}
+
+// TestZCopy 测试深拷贝集合后修改源集合不影响副本
+func TestZCopy(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("src", "a", 1)
+	cache.ZAddInt64("src", "b", 2)
+	cache.ZAddWithValue("src", "c", big.NewRat(3, 1), []byte("payload"))
+
+	if !cache.ZCopy("src", "dst", false) {
+		t.Fatal("ZCopy to a new key should succeed")
+	}
+
+	// 修改源集合
+	cache.ZAddInt64("src", "a", 100)
+	cache.ZRem("src", "b")
+	cache.ZAddInt64("src", "d", 4)
+	if score, _ := cache.ZScore("src", "c"); score != nil {
+		score.SetInt64(999)
+	}
+
+	got := cache.getZSet("dst").sl.All()
+	want := []struct {
+		member string
+		score  int64
+	}{{"a", 1}, {"b", 2}, {"c", 3}}
+	if len(got) != len(want) {
+		t.Fatalf("dst = %v, want %v", got, want)
+	}
+	for i, w := range want {
+		if got[i].Member != w.member || got[i].Score.Cmp(big.NewRat(w.score, 1)) != 0 {
+			t.Errorf("dst[%d] = %s:%v, want %s:%d", i, got[i].Member, got[i].Score, w.member, w.score)
+		}
+	}
+	if value, _ := cache.ZGetValue("dst", "c"); string(value) != "payload" {
+		t.Errorf("copied value = %q, want payload", value)
+	}
+
+	// 副本与源集合不共享节点或分数指针
+	srcSet, dstSet := cache.getZSet("src"), cache.getZSet("dst")
+	for member, node := range dstSet.sl.memberMap {
+		if srcNode, ok := srcSet.sl.memberMap[member]; ok && (srcNode == node || srcNode.score == node.score) {
+			t.Errorf("member %s shares a node or score with the source", member)
+		}
+	}
+
+	if cache.ZCopy("src", "dst", false) {
+		t.Error("ZCopy without replace should fail when dst exists")
+	}
+	if !cache.ZCopy("src", "dst", true) {
+		t.Error("ZCopy with replace should overwrite dst")
+	}
+	if card, _ := cache.ZCard("dst"); card != 3 {
+		t.Errorf("ZCard(dst) after replace = %d, want 3", card)
+	}
+	if cache.ZCopy("missing", "dst2", true) {
+		t.Error("ZCopy from a missing key should fail")
+	}
+}
+
+// TestZUnionStoreLimit 测试并集只保留聚合分数最高的 N 个成员
+func TestZUnionStoreLimit(t *testing.T) {
+	cache := New()
+	for i := 0; i < 20; i++ {
+		cache.ZAddInt64("s1", fmt.Sprintf("m%02d", i), int64(i))
+		cache.ZAddInt64("s2", fmt.Sprintf("m%02d", i), int64(20-i))
+	}
+	cache.ZAddInt64("s2", "m05", 100) // m05: 5 + 100
+	cache.ZAddInt64("s2", "x", 50)
+
+	n := cache.ZUnionStoreLimit("top", []string{"s1", "s2"}, []*big.Rat{big.NewRat(2, 1), big.NewRat(1, 1)}, "sum", 4)
+	if n != 4 {
+		t.Fatalf("ZUnionStoreLimit = %d, want 4", n)
+	}
+
+	// 加权求和后 mi = 2i + (20-i) = 20+i，m05 = 110，x = 50
+	got := cache.getZSet("top").sl.All()
+	want := []struct {
+		member string
+		score  int64
+	}{{"m18", 38}, {"m19", 39}, {"x", 50}, {"m05", 110}}
+	if len(got) != len(want) {
+		t.Fatalf("top = %v, want %v", got, want)
+	}
+	for i, w := range want {
+		if got[i].Member != w.member || got[i].Score.Cmp(big.NewRat(w.score, 1)) != 0 {
+			t.Errorf("top[%d] = %s:%v, want %s:%d", i, got[i].Member, got[i].Score, w.member, w.score)
+		}
+	}
+
+	// 分数并列时成员名较大的优先
+	cache.ZAddInt64("t1", "a", 1)
+	cache.ZAddInt64("t1", "b", 1)
+	cache.ZAddInt64("t1", "c", 1)
+	cache.ZUnionStoreLimit("tie", []string{"t1"}, nil, "sum", 2)
+	if got := cache.ZRange("tie", 0, -1, false); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("tie = %v, want [b c]", got)
+	}
+
+	if n := cache.ZUnionStoreLimit("all", []string{"s1"}, nil, "sum", 0); n != 20 {
+		t.Errorf("limit 0 stored %d members, want 20", n)
+	}
+}
+
+// BenchmarkZUnionStoreLimit 基准测试直接保留前 N 名的并集存储
+func BenchmarkZUnionStoreLimit(b *testing.B) {
+	cache := New()
+	for i := 0; i < 10000; i++ {
+		cache.ZAddInt64("a", fmt.Sprintf("member%d", i), int64(i))
+		cache.ZAddInt64("b", fmt.Sprintf("member%d", i+5000), int64(i))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.ZUnionStoreLimit("out", []string{"a", "b"}, nil, AggregateSum, 100)
+	}
+}
+
+// BenchmarkZUnionStoreThenTrim 基准测试先完整存储并集再裁剪到前 N 名
+func BenchmarkZUnionStoreThenTrim(b *testing.B) {
+	cache := New()
+	for i := 0; i < 10000; i++ {
+		cache.ZAddInt64("a", fmt.Sprintf("member%d", i), int64(i))
+		cache.ZAddInt64("b", fmt.Sprintf("member%d", i+5000), int64(i))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.ZUnionStore("out", []string{"a", "b"}, nil, AggregateSum)
+		cache.ZRemRangeByRank("out", 0, -101)
+	}
+}
+
+// TestZRangeStore 测试将排名范围存入另一个 key
+func TestZRangeStore(t *testing.T) {
+	cache := New()
+	for i, m := range []string{"a", "b", "c", "d", "e"} {
+		cache.ZAddInt64("src", m, int64(i+1))
+	}
+	members := func(key string) []string {
+		set := cache.getZSet(key)
+		if set == nil {
+			return nil
+		}
+		var result []string
+		for _, sm := range set.sl.All() {
+			result = append(result, sm.Member)
+		}
+		return result
+	}
+
+	cache.ZAddInt64("dst", "old", 100)
+	if n := cache.ZRangeStore("dst", "src", 1, 2, false); n != 2 {
+		t.Errorf("forward ZRangeStore = %d, want 2", n)
+	}
+	if got := members("dst"); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("forward dst = %v, want [b c]", got)
+	}
+	if score, _ := cache.ZScore("dst", "c"); score.Cmp(big.NewRat(3, 1)) != 0 {
+		t.Errorf("dst score of c = %v, want 3", score)
+	}
+
+	// 倒序的前两名
+	if n := cache.ZRangeStore("dst", "src", 0, 1, true); n != 2 {
+		t.Errorf("reverse ZRangeStore = %d, want 2", n)
+	}
+	if got := members("dst"); !reflect.DeepEqual(got, []string{"d", "e"}) {
+		t.Errorf("reverse dst = %v, want [d e]", got)
+	}
+
+	if n := cache.ZRangeStore("dst", "src", -3, -1, false); n != 3 {
+		t.Errorf("negative ZRangeStore = %d, want 3", n)
+	}
+	if got := members("dst"); !reflect.DeepEqual(got, []string{"c", "d", "e"}) {
+		t.Errorf("negative dst = %v, want [c d e]", got)
+	}
+
+	// dst 与 src 相同
+	if n := cache.ZRangeStore("src", "src", 0, 1, false); n != 2 {
+		t.Errorf("in-place ZRangeStore = %d, want 2", n)
+	}
+	if got := members("src"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("in-place src = %v, want [a b]", got)
+	}
+
+	// src 不存在时清空 dst
+	if n := cache.ZRangeStore("dst", "missing", 0, -1, false); n != 0 {
+		t.Errorf("missing src ZRangeStore = %d, want 0", n)
+	}
+	if card, _ := cache.ZCard("dst"); card != 0 {
+		t.Errorf("dst ZCard after missing src = %d, want 0", card)
+	}
+}
+
+// TestZEqual 测试两个集合的精确比较
+func TestZEqual(t *testing.T) {
+	cache := New()
+	if !cache.ZEqual("missing1", "missing2") {
+		t.Error("two missing keys should be equal")
+	}
+
+	// 两个分数的差远小于 float64 的精度
+	base, _ := new(big.Rat).SetString("0.1")
+	near, _ := new(big.Rat).SetString("0.10000000000000000000000000001")
+	f1, _ := base.Float64()
+	f2, _ := near.Float64()
+	if f1 != f2 {
+		t.Fatal("test scores should be indistinguishable as float64")
+	}
+	cache.ZAdd("a", "x", base)
+	cache.ZAddInt64("a", "y", 2)
+	cache.ZAdd("b", "x", base)
+	cache.ZAddInt64("b", "y", 2)
+	if !cache.ZEqual("a", "b") {
+		t.Error("identical sets should be equal")
+	}
+
+	cache.ZAdd("b", "x", near)
+	if cache.ZEqual("a", "b") {
+		t.Error("sets differing by a high-precision score should not be equal")
+	}
+	only1, only2, differs := cache.ZDiffMembers("a", "b")
+	if len(only1) != 0 || len(only2) != 0 || !reflect.DeepEqual(differs, []string{"x"}) {
+		t.Errorf("ZDiffMembers = %v, %v, %v, want [], [], [x]", only1, only2, differs)
+	}
+}
+
+// TestZDiffMembers 测试成员差异的分类
+func TestZDiffMembers(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "shared", 1)
+	cache.ZAddInt64("a", "changed", 2)
+	cache.ZAddInt64("a", "onlyA2", 3)
+	cache.ZAddInt64("a", "onlyA1", 4)
+	cache.ZAddInt64("b", "shared", 1)
+	cache.ZAddInt64("b", "changed", 5)
+	cache.ZAddInt64("b", "onlyB", 6)
+
+	only1, only2, differs := cache.ZDiffMembers("a", "b")
+	if !reflect.DeepEqual(only1, []string{"onlyA1", "onlyA2"}) {
+		t.Errorf("onlyIn1 = %v, want [onlyA1 onlyA2]", only1)
+	}
+	if !reflect.DeepEqual(only2, []string{"onlyB"}) {
+		t.Errorf("onlyIn2 = %v, want [onlyB]", only2)
+	}
+	if !reflect.DeepEqual(differs, []string{"changed"}) {
+		t.Errorf("scoreDiffers = %v, want [changed]", differs)
+	}
+
+	only1, only2, differs = cache.ZDiffMembers("a", "missing")
+	if len(only1) != 4 || len(only2) != 0 || len(differs) != 0 {
+		t.Errorf("ZDiffMembers against missing key = %v, %v, %v", only1, only2, differs)
+	}
+}
+
+// TestZUnionZInter 测试直接返回结果的并集与交集，与先存储再查询的结果一致
+func TestZUnionZInter(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "x", 1)
+	cache.ZAddInt64("a", "y", 2)
+	cache.ZAddInt64("a", "z", 3)
+	cache.ZAddInt64("b", "y", 10)
+	cache.ZAddInt64("b", "z", 1)
+	cache.ZAddInt64("b", "w", 4)
+
+	keys := []string{"a", "b", "missing"}
+	weights := []*big.Rat{big.NewRat(1, 1), big.NewRat(1, 2), big.NewRat(3, 1)}
+	for _, agg := range []string{"", AggregateMin, AggregateMax} {
+		for _, withScores := range []bool{false, true} {
+			cache.ZUnionStore("union", keys, weights, agg)
+			if got, want := cache.ZUnion(keys, weights, agg, withScores), cache.ZRange("union", 0, -1, withScores); !reflect.DeepEqual(got, want) {
+				t.Errorf("ZUnion(%q, %v) = %v, want %v", agg, withScores, got, want)
+			}
+			cache.ZInterStore("inter", keys[:2], weights[:2], agg)
+			if got, want := cache.ZInter(keys[:2], weights[:2], agg, withScores), cache.ZRange("inter", 0, -1, withScores); !reflect.DeepEqual(got, want) {
+				t.Errorf("ZInter(%q, %v) = %v, want %v", agg, withScores, got, want)
+			}
+		}
+	}
+
+	// 不写入任何 key
+	before := cache.DBSize()
+	cache.ZUnion([]string{"a", "b"}, nil, "", false)
+	if cache.DBSize() != before {
+		t.Error("ZUnion should not create keys")
+	}
+	if got := cache.ZInter(keys, nil, "", false); len(got) != 0 {
+		t.Errorf("ZInter with a missing key = %v, want empty", got)
+	}
+	if got := cache.ZUnion(keys, weights[:1], "", false); got != nil {
+		t.Errorf("ZUnion with mismatched weights = %v, want nil", got)
+	}
+	if got := cache.ZInter(keys, nil, "avg", false); got != nil {
+		t.Errorf("ZInter with an unknown aggregate = %v, want nil", got)
+	}
+}
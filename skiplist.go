@@ -1,15 +1,53 @@
 package csort
 
 import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"hash/fnv"
 	"math/big"
 	"math/rand/v2"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
 // ScoreMember 表示一个分数-成员对
 type ScoreMember struct {
 	Score  *big.Rat // 使用 big.Rat 支持任意精度小数
 	Member string
+	Value  []byte // 成员附带的数据（见 ZAddWithValue），仅 Range、All 等会填充
+}
+
+// RankedMember 表示带排名的分数-成员对
+type RankedMember struct {
+	Rank   int // 正序排名（从0开始）
+	Score  *big.Rat
+	Member string
+}
+
+// ScoreGroup 表示一个分数及持有该分数的成员数量
+type ScoreGroup struct {
+	Score *big.Rat
+	Count int
+}
+
+// QueryStats 记录一次范围查询的开销，用于定位退化为全表扫描的查询
+type QueryStats struct {
+	NodesVisited    int           // 定位与遍历过程中访问的节点数
+	LevelsDescended int           // 定位阶段下降经过的层数
+	Elapsed         time.Duration // 查询耗时（不含等待锁的时间）
+}
+
+// SkipListStats 跳表结构的统计信息，用于排查层级退化与调整晋升概率
+type SkipListStats struct {
+	Length      int     // 成员数量
+	Level       int     // 当前最高层级
+	MaxLevel    int     // 允许的最大层级
+	LevelCounts []int   // LevelCounts[i] 为出现在第 i 层（从0开始）的节点数，即层级 >= i+1 的节点数
+	AvgLevel    float64 // 节点的平均层级（平均 forward 指针数），理想值约为 1/(1-p)
 }
 
 // skipNode 跳表节点
@@ -20,6 +58,11 @@ type skipNode struct {
 	span     []int       // 每层的跨度（用于 O(log n) 排名计算）
 	backward *skipNode   // 后向指针，用于反向遍历
 	level    int
+
+	updatedAt time.Time // 分数最近一次变更的时间
+	touchedAt time.Time // 最近一次 Touch 的时间，不影响分数与排名
+	value     []byte    // 成员附带的数据，更新分数时保留
+	stamp     uint64    // 最近一次写入该成员时的写入序号，用于判断成员在此之后是否被再次写入
 }
 
 // SkipList 跳表实现
@@ -27,23 +70,86 @@ type SkipList struct {
 	head      *skipNode
 	tail      *skipNode
 	length    int
+	size      atomic.Int64 // length 的原子副本，供 Len 无锁读取
 	level     int
 	maxLevel  int
-	p         float64              // 节点晋升概率
-	memberMap map[string]*skipNode // member → node 索引（O(1) 查找）
+	p         float64                // 节点晋升概率
+	memberMap map[string]*skipNode   // member → node 索引（O(1) 查找）
+	tieBreak  func(a, b string) bool // 分数相同时成员的排序规则，nil 表示按成员名升序
+	fold      func(string) string    // 成员名归一化函数，memberMap 以归一化后的成员名为键；nil 表示区分大小写
+	now       func() time.Time       // 时钟，用于记录节点变更时间
+	version   uint64                 // 结构版本号，每次插入、删除节点时递增，用于判断节点提示是否失效
+	writes    uint64                 // 写入序号，每次 insertInternal 时递增（包括分数未变的写入）
+	inserts   atomic.Int64           // 累计新增的成员数（不含分数更新）
+	deletes   atomic.Int64           // 累计删除的成员数（不含分数更新）
 	mu        sync.RWMutex
 }
 
+// 跳表默认的最大层级与节点晋升概率
+const (
+	defaultMaxLevel = 32
+	defaultP        = 0.25
+)
+
 // NewSkipList 创建新的跳表
 func NewSkipList() *SkipList {
-	maxLevel := 32
+	return NewSkipListWithConfig(defaultMaxLevel, defaultP)
+}
+
+// NewSkipListWithConfig 使用指定的最大层级与节点晋升概率创建跳表
+// maxLevel 必须在 [1, 64] 内，p 必须在 (0, 1) 内，否则 panic。
+// 较小的 p 减少指针数量、节省内存，较大的 p 使查找路径更短；maxLevel 应满足 (1/p)^maxLevel 远大于预期的成员数
+func NewSkipListWithConfig(maxLevel int, p float64) *SkipList {
+	if err := validateSkipListConfig(maxLevel, p); err != nil {
+		panic("csort: " + err.Error())
+	}
 	return &SkipList{
 		head:      &skipNode{forward: make([]*skipNode, maxLevel), span: make([]int, maxLevel)},
 		level:     1,
 		maxLevel:  maxLevel,
-		p:         0.25,
+		p:         p,
 		memberMap: make(map[string]*skipNode),
+		now:       time.Now,
+	}
+}
+
+// validateSkipListConfig 校验跳表配置，非法时返回包装了 ErrInvalidConfig 的错误
+func validateSkipListConfig(maxLevel int, p float64) error {
+	if maxLevel < 1 || maxLevel > 64 {
+		return fmt.Errorf("%w: skip list maxLevel %d out of range [1, 64]", ErrInvalidConfig, maxLevel)
+	}
+	if !(p > 0 && p < 1) {
+		return fmt.Errorf("%w: skip list probability %v out of range (0, 1)", ErrInvalidConfig, p)
+	}
+	return nil
+}
+
+// NewSkipListWithTieBreak 创建分数相同时按 less 排序成员的跳表，less 为 nil 时按成员名升序
+// less 必须是严格弱序且对不同成员给出确定的先后（不能把两个不同成员视为相等）。
+// 按字典序工作的 RangeByLex、LexRank 等方法假定默认顺序，使用自定义规则时其结果未定义
+func NewSkipListWithTieBreak(maxLevel int, p float64, less func(a, b string) bool) *SkipList {
+	sl := NewSkipListWithConfig(maxLevel, p)
+	sl.tieBreak = less
+	return sl
+}
+
+// key 返回成员在 memberMap 中的键：设置了归一化函数时为归一化后的成员名，否则为成员名本身
+func (sl *SkipList) key(member string) string {
+	if sl.fold == nil {
+		return member
+	}
+	return sl.fold(member)
+}
+
+// memberLess 判断分数相同时成员 a 是否排在 b 之前（按归一化后的成员名比较）
+func (sl *SkipList) memberLess(a, b string) bool {
+	if sl.fold != nil {
+		a, b = sl.fold(a), sl.fold(b)
+	}
+	if sl.tieBreak == nil {
+		return a < b
 	}
+	return sl.tieBreak(a, b)
 }
 
 // randomLevel 随机生成节点层级
@@ -57,11 +163,15 @@ func (sl *SkipList) randomLevel() int {
 
 // compare 比较两个分数
 // 返回值: -1 表示 a < b, 0 表示 a == b, 1 表示 a > b
+// 两个分数都是整数时直接比较分子，跳过 big.Rat.Cmp 的交叉相乘与内存分配
 func compare(a, b *big.Rat) int {
+	if a.IsInt() && b.IsInt() {
+		return a.Num().Cmp(b.Num())
+	}
 	return a.Cmp(b)
 }
 
-// Insert 插入或更新元素
+// Insert 插入或更新元素，更新分数时保留成员附带的数据
 func (sl *SkipList) Insert(member string, score *big.Rat) {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
@@ -69,15 +179,23 @@ func (sl *SkipList) Insert(member string, score *big.Rat) {
 }
 
 // insertInternal 内部插入方法（无锁版本，调用者必须持有写锁）
-func (sl *SkipList) insertInternal(member string, score *big.Rat) {
+// 返回成员当前所在的节点；更新已有成员的分数时，新节点沿用旧节点附带的数据
+func (sl *SkipList) insertInternal(member string, score *big.Rat) *skipNode {
 	// 检查成员是否已存在
-	if existingNode, exists := sl.memberMap[member]; exists {
-		// 分数相同，不需要更新
+	var value []byte
+	if existingNode, exists := sl.memberMap[sl.key(member)]; exists {
+		// 分数相同，不需要更新；成员名的显示形式跟随最近一次写入
 		if compare(existingNode.score, score) == 0 {
-			return
+			existingNode.member = member
+			sl.writes++
+			existingNode.stamp = sl.writes
+			return existingNode
 		}
 		// 分数不同，先删除旧节点
+		value = existingNode.value
 		sl.deleteByNode(existingNode)
+	} else {
+		sl.inserts.Add(1)
 	}
 
 	// 查找插入位置并记录每层的 update 节点和 rank
@@ -91,7 +209,7 @@ func (sl *SkipList) insertInternal(member string, score *big.Rat) {
 		}
 		for node.forward[i] != nil {
 			cmp := compare(node.forward[i].score, score)
-			if cmp < 0 || (cmp == 0 && node.forward[i].member < member) {
+			if cmp < 0 || (cmp == 0 && sl.memberLess(node.forward[i].member, member)) {
 				rank[i] += node.span[i]
 				node = node.forward[i]
 			} else {
@@ -119,7 +237,12 @@ func (sl *SkipList) insertInternal(member string, score *big.Rat) {
 		forward: make([]*skipNode, newLevel),
 		span:    make([]int, newLevel),
 		level:   newLevel,
+
+		updatedAt: sl.now(),
+		value:     value,
 	}
+	sl.writes++
+	newNode.stamp = sl.writes
 
 	// 更新指针和跨度
 	for i := 0; i < newLevel; i++ {
@@ -147,12 +270,41 @@ func (sl *SkipList) insertInternal(member string, score *big.Rat) {
 	}
 
 	sl.length++
-	sl.memberMap[member] = newNode
+	sl.size.Add(1)
+	sl.memberMap[sl.key(member)] = newNode
+	sl.version++
+	return newNode
+}
+
+// updatePool 复用删除路径上的 update 数组，避免每次删除都分配 maxLevel 大小的切片
+var updatePool = sync.Pool{
+	New: func() any {
+		buf := make([]*skipNode, 0, defaultMaxLevel)
+		return &buf
+	},
+}
+
+// getUpdateBuf 从池中取出长度为 level 的 update 数组
+func getUpdateBuf(level int) *[]*skipNode {
+	buf := updatePool.Get().(*[]*skipNode)
+	if cap(*buf) < level {
+		*buf = make([]*skipNode, level)
+	}
+	*buf = (*buf)[:level]
+	return buf
+}
+
+// putUpdateBuf 清空指针后归还 update 数组，避免池中残留对已删除节点的引用
+func putUpdateBuf(buf *[]*skipNode) {
+	clear(*buf)
+	updatePool.Put(buf)
 }
 
 // deleteByNode 通过节点指针删除（内部方法，调用者必须持有写锁）
 func (sl *SkipList) deleteByNode(target *skipNode) {
-	update := make([]*skipNode, sl.maxLevel)
+	buf := getUpdateBuf(sl.level)
+	defer putUpdateBuf(buf)
+	update := *buf
 	node := sl.head
 
 	for i := sl.level - 1; i >= 0; i-- {
@@ -161,7 +313,7 @@ func (sl *SkipList) deleteByNode(target *skipNode) {
 				break
 			}
 			cmp := compare(node.forward[i].score, target.score)
-			if cmp < 0 || (cmp == 0 && node.forward[i].member < target.member) {
+			if cmp < 0 || (cmp == 0 && sl.memberLess(node.forward[i].member, target.member)) {
 				node = node.forward[i]
 			} else {
 				break
@@ -175,7 +327,8 @@ func (sl *SkipList) deleteByNode(target *skipNode) {
 
 // deleteNode 删除节点并更新指针和跨度
 func (sl *SkipList) deleteNode(node *skipNode, update []*skipNode) {
-	for i := 0; i < node.level; i++ {
+	// 高于节点层级的 update 节点同样跨过被删除节点，跨度也需要减一
+	for i := 0; i < sl.level; i++ {
 		if update[i].forward[i] == node {
 			update[i].span[i] += node.span[i] - 1
 			update[i].forward[i] = node.forward[i]
@@ -194,8 +347,10 @@ func (sl *SkipList) deleteNode(node *skipNode, update []*skipNode) {
 		sl.level--
 	}
 
-	delete(sl.memberMap, node.member)
+	delete(sl.memberMap, sl.key(node.member))
 	sl.length--
+	sl.size.Add(-1)
+	sl.version++
 }
 
 // Delete 删除指定成员
@@ -203,7 +358,7 @@ func (sl *SkipList) Delete(member string, score *big.Rat) bool {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
 
-	node, exists := sl.memberMap[member]
+	node, exists := sl.memberMap[sl.key(member)]
 	if !exists {
 		return false
 	}
@@ -214,6 +369,7 @@ func (sl *SkipList) Delete(member string, score *big.Rat) bool {
 	}
 
 	sl.deleteByNode(node)
+	sl.deletes.Add(1)
 	return true
 }
 
@@ -221,13 +377,18 @@ func (sl *SkipList) Delete(member string, score *big.Rat) bool {
 func (sl *SkipList) DeleteByMember(member string) bool {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
+	return sl.deleteByMemberInternal(member)
+}
 
-	node, exists := sl.memberMap[member]
+// deleteByMemberInternal 根据 member 名称删除（内部方法，调用者必须持有写锁）
+func (sl *SkipList) deleteByMemberInternal(member string) bool {
+	node, exists := sl.memberMap[sl.key(member)]
 	if !exists {
 		return false
 	}
 
 	sl.deleteByNode(node)
+	sl.deletes.Add(1)
 	return true
 }
 
@@ -235,17 +396,22 @@ func (sl *SkipList) DeleteByMember(member string) bool {
 func (sl *SkipList) GetRank(member string, score *big.Rat) int {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
+	return sl.getRankInternal(member, score)
+}
 
+// getRankInternal 获取成员的排名（从1开始，内部方法，调用者必须持有读锁）
+func (sl *SkipList) getRankInternal(member string, score *big.Rat) int {
 	rank := 0
 	node := sl.head
+	key := sl.key(member)
 
 	for i := sl.level - 1; i >= 0; i-- {
 		for node.forward[i] != nil {
 			cmp := compare(node.forward[i].score, score)
-			if cmp < 0 || (cmp == 0 && node.forward[i].member <= member) {
+			if cmp < 0 || (cmp == 0 && !sl.memberLess(member, node.forward[i].member)) {
 				rank += node.span[i]
 				node = node.forward[i]
-				if node.member == member {
+				if sl.key(node.member) == key {
 					return rank
 				}
 			} else {
@@ -257,6 +423,67 @@ func (sl *SkipList) GetRank(member string, score *big.Rat) int {
 	return 0 // 未找到
 }
 
+// CompetitionRanks 获取成员的竞赛排名（从1开始），一次遍历同时计算两种排名
+// standard 为标准排名（"1224"，并列取较小排名，之后跳过）；dense 为密集排名（"1223"，不留空位）
+func (sl *SkipList) CompetitionRanks(member string) (standard, dense int, ok bool) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	target, exists := sl.memberMap[sl.key(member)]
+	if !exists {
+		return 0, 0, false
+	}
+
+	// 沿底层遍历到目标分数档，记录分数变化的边界
+	position := 0
+	var prev *big.Rat
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		position++
+		if prev == nil || compare(node.score, prev) != 0 {
+			standard = position
+			dense++
+			prev = node.score
+		}
+		if compare(node.score, target.score) == 0 {
+			return standard, dense, true
+		}
+	}
+	return 0, 0, false
+}
+
+// RankMembers 批量获取成员的排名，只获取一次读锁
+// 结果按排名升序排列，Rank 为从0开始的正序排名，Member 为跳表中保存的成员名；
+// 不存在或重复（归一化后相同）的成员会被忽略
+func (sl *SkipList) RankMembers(members []string) []RankedMember {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	result := make([]RankedMember, 0, len(members))
+	seen := make(map[string]struct{}, len(members))
+	for _, member := range members {
+		key := sl.key(member)
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		node, exists := sl.memberMap[key]
+		if !exists {
+			continue
+		}
+		result = append(result, RankedMember{
+			Rank:   sl.getRankInternal(node.member, node.score) - 1,
+			Score:  new(big.Rat).Set(node.score),
+			Member: node.member,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Rank < result[j].Rank
+	})
+	return result
+}
+
 // GetByRank 根据排名获取成员 — O(log n) 通过 span 定位
 func (sl *SkipList) GetByRank(rank int) (string, *big.Rat, bool) {
 	sl.mu.RLock()
@@ -281,24 +508,202 @@ func (sl *SkipList) GetByRank(rank int) (string, *big.Rat, bool) {
 	return "", nil, false
 }
 
+// RandomMembers 随机选取 count 个成员，每次选取通过 span 按排名定位（O(log n)）
+// distinct 为 true 时成员互不重复，count 超过元素数量时返回全部成员（顺序随机）；
+// 为 false 时恰好返回 count 个成员，允许重复
+func (sl *SkipList) RandomMembers(count int, distinct bool) []ScoreMember {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	if count <= 0 || sl.length == 0 {
+		return nil
+	}
+
+	var ranks []int
+	switch {
+	case !distinct:
+		ranks = make([]int, count)
+		for i := range ranks {
+			ranks[i] = rand.IntN(sl.length) + 1
+		}
+	case count >= sl.length:
+		ranks = rand.Perm(sl.length)
+		for i := range ranks {
+			ranks[i]++
+		}
+	default:
+		// Floyd 抽样：不重复地均匀选取 count 个排名
+		picked := make(map[int]struct{}, count)
+		ranks = make([]int, 0, count)
+		for j := sl.length - count + 1; j <= sl.length; j++ {
+			r := rand.IntN(j) + 1
+			if _, dup := picked[r]; dup {
+				r = j
+			}
+			picked[r] = struct{}{}
+			ranks = append(ranks, r)
+		}
+	}
+
+	result := make([]ScoreMember, len(ranks))
+	for i, rank := range ranks {
+		node := sl.getNodeByRankInternal(rank)
+		result[i] = ScoreMember{Score: new(big.Rat).Set(node.score), Member: node.member}
+	}
+	return result
+}
+
+// WeightedSample 有放回地抽取 n 个成员，每次抽中某成员的概率为 分数/总分
+// 存在负分数时返回 ErrNegativeScore；集合为空、n <= 0 或总分为 0 时返回 nil
+func (sl *SkipList) WeightedSample(n int) ([]string, error) {
+	sl.mu.RLock()
+	if n <= 0 || sl.length == 0 {
+		sl.mu.RUnlock()
+		return nil, nil
+	}
+	members := make([]string, 0, sl.length)
+	prefix := make([]float64, 0, sl.length)
+	total := new(big.Rat)
+	for x := sl.head.forward[0]; x != nil; x = x.forward[0] {
+		if x.score.Sign() < 0 {
+			sl.mu.RUnlock()
+			return nil, fmt.Errorf("%w: member %q has score %s", ErrNegativeScore, x.member, x.score.RatString())
+		}
+		total.Add(total, x.score)
+		f, _ := total.Float64()
+		members = append(members, x.member)
+		prefix = append(prefix, f)
+	}
+	sl.mu.RUnlock()
+
+	sum := prefix[len(prefix)-1]
+	if sum <= 0 {
+		return nil, nil
+	}
+	result := make([]string, n)
+	for i := range result {
+		// 第一个前缀和大于 r 的成员；分数为 0 的成员前缀和与前一个相同，不会被选中
+		r := rand.Float64() * sum
+		j := sort.Search(len(prefix), func(j int) bool { return prefix[j] > r })
+		if j == len(prefix) {
+			// 浮点舍入使 r 等于总分时取最后一个成员
+			j--
+		}
+		result[i] = members[j]
+	}
+	return result, nil
+}
+
 // GetScore 获取成员的分数 — O(1) 通过 memberMap
 func (sl *SkipList) GetScore(member string) (*big.Rat, bool) {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
+	return sl.getScoreInternal(member)
+}
 
-	node, exists := sl.memberMap[member]
+// getScoreInternal 获取成员分数的副本（内部方法，调用者必须持有读锁）
+func (sl *SkipList) getScoreInternal(member string) (*big.Rat, bool) {
+	node, exists := sl.memberMap[sl.key(member)]
 	if !exists {
 		return nil, false
 	}
 	return new(big.Rat).Set(node.score), true
 }
 
+// GetValue 获取成员附带数据的副本，成员不存在时返回 false
+func (sl *SkipList) GetValue(member string) ([]byte, bool) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	node, exists := sl.memberMap[sl.key(member)]
+	if !exists {
+		return nil, false
+	}
+	return bytes.Clone(node.value), true
+}
+
+// GetScores 批量获取成员的分数，只获取一次读锁；不存在的成员对应位置为 nil
+func (sl *SkipList) GetScores(members []string) []*big.Rat {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	result := make([]*big.Rat, len(members))
+	for i, member := range members {
+		if node, exists := sl.memberMap[sl.key(member)]; exists {
+			result[i] = new(big.Rat).Set(node.score)
+		}
+	}
+	return result
+}
+
+// GetScoreAt 获取成员的分数及其最近一次变更时间 — O(1) 通过 memberMap
+func (sl *SkipList) GetScoreAt(member string) (*big.Rat, time.Time, bool) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	node, exists := sl.memberMap[sl.key(member)]
+	if !exists {
+		return nil, time.Time{}, false
+	}
+	return new(big.Rat).Set(node.score), node.updatedAt, true
+}
+
+// lastActive 返回节点最近一次变更或 Touch 的时间
+func (node *skipNode) lastActive() time.Time {
+	if node.touchedAt.After(node.updatedAt) {
+		return node.touchedAt
+	}
+	return node.updatedAt
+}
+
+// Touch 将成员的最近访问时间刷新为当前时间，不改变分数与排名；成员不存在时返回 false
+func (sl *SkipList) Touch(member string) bool {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return sl.touchInternal(member)
+}
+
+// touchInternal 刷新成员的最近访问时间（内部方法，调用者必须持有写锁）
+func (sl *SkipList) touchInternal(member string) bool {
+	node, exists := sl.memberMap[sl.key(member)]
+	if !exists {
+		return false
+	}
+	node.touchedAt = sl.now()
+	return true
+}
+
+// RemoveIdle 删除最近一次变更或 Touch 早于 cutoff 的所有成员，返回删除数量
+func (sl *SkipList) RemoveIdle(cutoff time.Time) int {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return len(sl.removeIdleInternal(cutoff))
+}
+
+// removeIdleInternal 删除不活跃的成员，返回被删除的成员及其分数（内部方法，调用者必须持有写锁）
+// 返回的 Score 直接引用已删除节点的分数，节点不再属于跳表，调用方可独占使用
+func (sl *SkipList) removeIdleInternal(cutoff time.Time) []ScoreMember {
+	var idle []*skipNode
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		if node.lastActive().Before(cutoff) {
+			idle = append(idle, node)
+		}
+	}
+	removed := make([]ScoreMember, 0, len(idle))
+	for _, node := range idle {
+		sl.deleteByNode(node)
+		removed = append(removed, ScoreMember{Score: node.score, Member: node.member})
+	}
+	sl.deletes.Add(int64(len(idle)))
+	return removed
+}
+
 // GetPrevMember 获取前一位成员（分数更小，或分数相同但 member 字典序更小）
 func (sl *SkipList) GetPrevMember(member string) (string, *big.Rat, bool) {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
 
-	node, exists := sl.memberMap[member]
+	node, exists := sl.memberMap[sl.key(member)]
 	if !exists {
 		return "", nil, false
 	}
@@ -314,7 +719,7 @@ func (sl *SkipList) GetNextMember(member string) (string, *big.Rat, bool) {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
 
-	node, exists := sl.memberMap[member]
+	node, exists := sl.memberMap[sl.key(member)]
 	if !exists {
 		return "", nil, false
 	}
@@ -326,11 +731,159 @@ func (sl *SkipList) GetNextMember(member string) (string, *big.Rat, bool) {
 	return "", nil, false
 }
 
+// ScanBackFrom 从指定成员开始沿 backward 方向向前遍历最多 count 个成员（不含该成员本身）
+// 结果按遍历顺序返回，即离锚点最近的成员在前；到达表头时提前结束
+func (sl *SkipList) ScanBackFrom(member string, count int) ([]ScoreMember, bool) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	node, exists := sl.memberMap[sl.key(member)]
+	if !exists {
+		return nil, false
+	}
+
+	result := make([]ScoreMember, 0)
+	for node = node.backward; node != nil && len(result) < count; node = node.backward {
+		result = append(result, ScoreMember{
+			Score:  new(big.Rat).Set(node.score),
+			Member: node.member,
+		})
+	}
+	return result, true
+}
+
+// RangeAround 获取成员及其前 before 个、后 after 个相邻成员（正序），在集合两端自动截断
+// 通过 memberMap 定位节点后沿 backward/forward 指针扩展，rank 为该成员从1开始的排名
+func (sl *SkipList) RangeAround(member string, before, after int) ([]ScoreMember, int, bool) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	node, exists := sl.memberMap[sl.key(member)]
+	if !exists {
+		return nil, 0, false
+	}
+	rank := sl.getRankInternal(member, node.score)
+
+	first, stepped := node, 0
+	for stepped < before && first.backward != nil {
+		first = first.backward
+		stepped++
+	}
+
+	total := stepped + 1 + after
+	result := make([]ScoreMember, 0, total)
+	for n := first; n != nil && len(result) < total; n = n.forward[0] {
+		result = append(result, ScoreMember{
+			Score:  new(big.Rat).Set(n.score),
+			Member: n.member,
+		})
+	}
+	return result, rank, true
+}
+
+// memberHash 计算成员的 64 位 FNV-1a 哈希，作为 ZScan 游标空间中的位置
+func memberHash(member string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(member))
+	return h.Sum64()
+}
+
+// scanByHash 按成员哈希升序返回哈希值 >= cursor 的至多 count 个成员
+// 哈希相同的成员总在同一批返回，因此批次可能略多于 count；
+// 返回的 next 为下一批的起始哈希，为 0 表示已遍历完毕
+func (sl *SkipList) scanByHash(cursor uint64, count int) (uint64, []ScoreMember) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	next, batch := scanHashBatch(cursor, count, func(visit func(string, *skipNode)) {
+		for key, node := range sl.memberMap {
+			visit(key, node)
+		}
+	})
+	result := make([]ScoreMember, len(batch))
+	for i, entry := range batch {
+		result[i] = ScoreMember{Score: new(big.Rat).Set(entry.item.score), Member: entry.item.member}
+	}
+	return next, result
+}
+
+// hashEntry 哈希游标扫描选出的条目
+type hashEntry[T any] struct {
+	hash uint64
+	key  string
+	item T
+}
+
+// hashMaxHeap 保存已见到的最小的若干个哈希值，堆顶为其中最大者
+type hashMaxHeap []uint64
+
+func (h hashMaxHeap) Len() int           { return len(h) }
+func (h hashMaxHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h hashMaxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *hashMaxHeap) Push(x any)        { *h = append(*h, x.(uint64)) }
+func (h *hashMaxHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// scanHashBatch 按 key 的哈希升序选出哈希值 >= cursor 的一批条目（ZScan 与 ScanKeys 共用）
+// each 每次调用都须依次提供同一组条目。批次为哈希值最小的 count 个条目，与其中最大哈希相同的条目一并返回，
+// 因此可能略多于 count。先用大小为 count 的最大堆确定本批的哈希上界，再只收集并排序上界以内的条目，
+// 单批耗时 O(n log count)。返回的 next 为下一批的起始哈希，为 0 表示已遍历完毕
+func scanHashBatch[T any](cursor uint64, count int, each func(visit func(key string, item T))) (uint64, []hashEntry[T]) {
+	smallest := make(hashMaxHeap, 0, count)
+	each(func(key string, _ T) {
+		h := memberHash(key)
+		switch {
+		case h < cursor:
+		case len(smallest) < count:
+			heap.Push(&smallest, h)
+		case h < smallest[0]:
+			smallest[0] = h
+			heap.Fix(&smallest, 0)
+		}
+	})
+	if len(smallest) == 0 {
+		return 0, nil
+	}
+	upper := smallest[0]
+
+	batch := make([]hashEntry[T], 0, len(smallest))
+	more := false
+	each(func(key string, item T) {
+		switch h := memberHash(key); {
+		case h < cursor:
+		case h > upper:
+			more = true
+		default:
+			batch = append(batch, hashEntry[T]{hash: h, key: key, item: item})
+		}
+	})
+	sort.Slice(batch, func(i, j int) bool {
+		if batch[i].hash != batch[j].hash {
+			return batch[i].hash < batch[j].hash
+		}
+		return batch[i].key < batch[j].key
+	})
+
+	var next uint64
+	if more {
+		next = upper + 1
+	}
+	return next, batch
+}
+
 // Range 获取排名范围内的成员 [start, stop] 闭区间（1-based）
 func (sl *SkipList) Range(start, stop int, reverse bool) []ScoreMember {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
+	return sl.rangeInternal(start, stop, reverse)
+}
 
+// rangeInternal 获取排名范围内的成员（内部方法，调用者必须持有读锁）
+func (sl *SkipList) rangeInternal(start, stop int, reverse bool) []ScoreMember {
 	if start < 1 {
 		start = 1
 	}
@@ -351,6 +904,7 @@ func (sl *SkipList) Range(start, stop int, reverse bool) []ScoreMember {
 			result = append(result, ScoreMember{
 				Score:  new(big.Rat).Set(node.score),
 				Member: node.member,
+				Value:  bytes.Clone(node.value),
 			})
 			node = node.backward
 			count--
@@ -362,6 +916,7 @@ func (sl *SkipList) Range(start, stop int, reverse bool) []ScoreMember {
 			result = append(result, ScoreMember{
 				Score:  new(big.Rat).Set(node.score),
 				Member: node.member,
+				Value:  bytes.Clone(node.value),
 			})
 			node = node.forward[0]
 			start++
@@ -371,6 +926,59 @@ func (sl *SkipList) Range(start, stop int, reverse bool) []ScoreMember {
 	return result
 }
 
+// rangeFrom 获取排名范围内的成员 [start, stop]（1-based），可从提示节点出发
+// hint 为上次返回的节点、hintRank 为其排名、version 为当时的结构版本号；
+// 提示仍然有效且与 start 距离不超过窗口大小时沿链表移动，否则从表头重新下降。
+// 返回结果、stop 处的节点及当前版本号，供下次调用作为提示
+func (sl *SkipList) rangeFrom(start, stop int, hint *skipNode, hintRank int, version uint64) ([]ScoreMember, *skipNode, uint64) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	if start < 1 {
+		start = 1
+	}
+	if stop > sl.length {
+		stop = sl.length
+	}
+	if start > stop {
+		return nil, nil, sl.version
+	}
+
+	var node *skipNode
+	if hint != nil && version == sl.version && abs(start-hintRank) <= stop-start+1 {
+		node = hint
+		for rank := hintRank; rank < start; rank++ {
+			node = node.forward[0]
+		}
+		for rank := hintRank; rank > start; rank-- {
+			node = node.backward
+		}
+	} else {
+		node = sl.getNodeByRankInternal(start)
+	}
+
+	result := make([]ScoreMember, 0, stop-start+1)
+	for rank := start; ; rank++ {
+		result = append(result, ScoreMember{
+			Score:  new(big.Rat).Set(node.score),
+			Member: node.member,
+		})
+		if rank == stop {
+			break
+		}
+		node = node.forward[0]
+	}
+	return result, node, sl.version
+}
+
+// abs 返回整数的绝对值
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
 // getNodeByRankInternal 根据排名获取节点（内部方法，无锁，O(log n)）
 func (sl *SkipList) getNodeByRankInternal(rank int) *skipNode {
 	if rank < 1 || rank > sl.length {
@@ -395,7 +1003,11 @@ func (sl *SkipList) getNodeByRankInternal(rank int) *skipNode {
 func (sl *SkipList) RangeByScore(min, max *big.Rat, reverse bool) []ScoreMember {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
+	return sl.rangeByScoreInternal(min, max, reverse)
+}
 
+// rangeByScoreInternal 根据分数范围获取成员（内部方法，调用者必须持有读锁）
+func (sl *SkipList) rangeByScoreInternal(min, max *big.Rat, reverse bool) []ScoreMember {
 	result := make([]ScoreMember, 0)
 
 	if reverse {
@@ -433,6 +1045,288 @@ func (sl *SkipList) RangeByScore(min, max *big.Rat, reverse bool) []ScoreMember
 	return result
 }
 
+// RangeByScoreProfiled 与 RangeByScore（正序）相同，同时返回访问节点数、下降层数与耗时
+func (sl *SkipList) RangeByScoreProfiled(min, max *big.Rat) ([]ScoreMember, QueryStats) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	var stats QueryStats
+	start := time.Now()
+
+	node := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		stats.LevelsDescended++
+		for node.forward[i] != nil {
+			stats.NodesVisited++
+			if compare(node.forward[i].score, min) >= 0 {
+				break
+			}
+			node = node.forward[i]
+		}
+	}
+	node = node.forward[0]
+
+	result := make([]ScoreMember, 0)
+	for node != nil {
+		stats.NodesVisited++
+		if compare(node.score, max) > 0 {
+			break
+		}
+		result = append(result, ScoreMember{
+			Score:  new(big.Rat).Set(node.score),
+			Member: node.member,
+		})
+		node = node.forward[0]
+	}
+
+	stats.Elapsed = time.Since(start)
+	return result, stats
+}
+
+// scoreBound 分数区间的一端
+type scoreBound struct {
+	value *big.Rat
+	incl  bool // 是否包含 value 本身
+	inf   int  // 0 表示有界，-1 表示负无穷，1 表示正无穷
+}
+
+// aboveMin 判断 score 是否满足下界
+func (b scoreBound) aboveMin(score *big.Rat) bool {
+	if b.inf != 0 {
+		return b.inf < 0
+	}
+	cmp := compare(score, b.value)
+	return cmp > 0 || (b.incl && cmp == 0)
+}
+
+// belowMax 判断 score 是否满足上界
+func (b scoreBound) belowMax(score *big.Rat) bool {
+	if b.inf != 0 {
+		return b.inf > 0
+	}
+	cmp := compare(score, b.value)
+	return cmp < 0 || (b.incl && cmp == 0)
+}
+
+// rangeByScoreBound 按分数区间获取成员（正序，支持开区间与无界）
+func (sl *SkipList) rangeByScoreBound(min, max scoreBound) []ScoreMember {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	// 利用跳表快速定位到第一个满足下界的节点
+	node := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for node.forward[i] != nil && !min.aboveMin(node.forward[i].score) {
+			node = node.forward[i]
+		}
+	}
+	node = node.forward[0]
+
+	result := make([]ScoreMember, 0)
+	for node != nil && max.belowMax(node.score) {
+		result = append(result, ScoreMember{
+			Score:  new(big.Rat).Set(node.score),
+			Member: node.member,
+		})
+		node = node.forward[0]
+	}
+	return result
+}
+
+// NearestByScore 获取分数与 target 最接近的 n 个成员，按距离从近到远返回
+// 先沿查找路径定位 target 的插入位置，再通过 backward/forward 指针向两侧扩展；
+// 距离相同时优先返回分数较低的一侧
+func (sl *SkipList) NearestByScore(target *big.Rat, n int) []ScoreMember {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	if n <= 0 {
+		return nil
+	}
+
+	// left 为最后一个分数 < target 的节点，right 为其后继
+	left := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for left.forward[i] != nil && compare(left.forward[i].score, target) < 0 {
+			left = left.forward[i]
+		}
+	}
+	right := left.forward[0]
+	if left == sl.head {
+		left = nil
+	}
+
+	result := make([]ScoreMember, 0, min(n, sl.length))
+	leftDist, rightDist := new(big.Rat), new(big.Rat)
+	for len(result) < n && (left != nil || right != nil) {
+		takeLeft := right == nil
+		if left != nil && right != nil {
+			leftDist.Sub(target, left.score)
+			rightDist.Sub(right.score, target)
+			takeLeft = compare(leftDist, rightDist) <= 0
+		}
+
+		var node *skipNode
+		if takeLeft {
+			node, left = left, left.backward
+		} else {
+			node, right = right, right.forward[0]
+		}
+		result = append(result, ScoreMember{Score: new(big.Rat).Set(node.score), Member: node.member})
+	}
+	return result
+}
+
+// MemberAtCumulative 按顺序累加分数，返回累计和首次达到或超过 threshold 时的成员
+// reverse 为 true 时从分数最高的成员开始累加
+func (sl *SkipList) MemberAtCumulative(threshold *big.Rat, reverse bool) (ScoreMember, bool) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	sum := new(big.Rat)
+	node := sl.head.forward[0]
+	if reverse {
+		node = sl.tail
+	}
+	for node != nil {
+		sum.Add(sum, node.score)
+		if compare(sum, threshold) >= 0 {
+			return ScoreMember{Score: new(big.Rat).Set(node.score), Member: node.member}, true
+		}
+		if reverse {
+			node = node.backward
+		} else {
+			node = node.forward[0]
+		}
+	}
+	return ScoreMember{}, false
+}
+
+// lexBound 字典序区间的一端
+type lexBound struct {
+	value string
+	incl  bool // 是否包含 value 本身
+	inf   bool // 无界（min 端表示负无穷，max 端表示正无穷）
+}
+
+// aboveMin 判断 member 是否满足下界
+func (b lexBound) aboveMin(member string) bool {
+	return b.inf || member > b.value || (b.incl && member == b.value)
+}
+
+// belowMax 判断 member 是否满足上界
+func (b lexBound) belowMax(member string) bool {
+	return b.inf || member < b.value || (b.incl && member == b.value)
+}
+
+// RangeByLex 按成员字典序获取区间内的成员（正序）
+// 仅当所有成员分数相同时结果才有意义；分数不同时行为未定义。
+// min 为空字符串且 minIncl 为 true 即表示无下界
+func (sl *SkipList) RangeByLex(min, max string, minIncl, maxIncl bool) []string {
+	return sl.rangeByLex(lexBound{value: min, incl: minIncl}, lexBound{value: max, incl: maxIncl})
+}
+
+// keyBound 将边界值按成员名同样的方式归一化，使其与 sl.key 的结果可比较
+func (sl *SkipList) keyBound(b lexBound) lexBound {
+	b.value = sl.key(b.value)
+	return b
+}
+
+// rangeByLex 按成员字典序获取区间内的成员（支持无界）
+// 比较的是归一化后的成员名（见 WithCaseInsensitiveMembers），与跳表中成员的排列顺序一致
+func (sl *SkipList) rangeByLex(min, max lexBound) []string {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+	min, max = sl.keyBound(min), sl.keyBound(max)
+
+	// 利用跳表快速定位到第一个满足下界的节点
+	node := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for node.forward[i] != nil && !min.aboveMin(sl.key(node.forward[i].member)) {
+			node = node.forward[i]
+		}
+	}
+	node = node.forward[0]
+
+	result := make([]string, 0)
+	for node != nil && max.belowMax(sl.key(node.member)) {
+		result = append(result, node.member)
+		node = node.forward[0]
+	}
+	return result
+}
+
+// revRangeByLex 按成员字典序倒序获取区间内的成员（支持无界）
+// 先沿查找路径定位最后一个满足上界的节点，再沿 backward 指针向前遍历
+func (sl *SkipList) revRangeByLex(min, max lexBound) []string {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+	min, max = sl.keyBound(min), sl.keyBound(max)
+
+	node := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for node.forward[i] != nil && max.belowMax(sl.key(node.forward[i].member)) {
+			node = node.forward[i]
+		}
+	}
+
+	result := make([]string, 0)
+	for ; node != nil && node != sl.head && min.aboveMin(sl.key(node.member)); node = node.backward {
+		result = append(result, node.member)
+	}
+	return result
+}
+
+// LexRank 仅按成员字典序定位成员，返回其排名（从1开始），不存在时返回 0
+// 跳过分数比较，仅当所有成员分数相同时结果与 GetRank 一致；分数不同时结果未定义
+func (sl *SkipList) LexRank(member string) int {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	key := sl.key(member)
+	rank := 0
+	node := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for node.forward[i] != nil && sl.key(node.forward[i].member) < key {
+			rank += node.span[i]
+			node = node.forward[i]
+		}
+	}
+	if next := node.forward[0]; next != nil && sl.key(next.member) == key {
+		return rank + 1
+	}
+	return 0
+}
+
+// countBelowInternal 统计分数严格小于 score 的成员数量（内部方法，调用者必须持有读锁）
+func (sl *SkipList) countBelowInternal(score *big.Rat) int {
+	rank := 0
+	node := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for node.forward[i] != nil && compare(node.forward[i].score, score) < 0 {
+			rank += node.span[i]
+			node = node.forward[i]
+		}
+	}
+	return rank
+}
+
+// ScoreGroups 按分数升序返回每个不同的分数及其成员数量，一次遍历合并相邻的相同分数
+func (sl *SkipList) ScoreGroups() []ScoreGroup {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	groups := make([]ScoreGroup, 0)
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		if n := len(groups); n > 0 && compare(groups[n-1].Score, node.score) == 0 {
+			groups[n-1].Count++
+			continue
+		}
+		groups = append(groups, ScoreGroup{Score: new(big.Rat).Set(node.score), Count: 1})
+	}
+	return groups
+}
+
 // CountByScore 统计分数范围内的成员数量
 func (sl *SkipList) CountByScore(min, max *big.Rat) int {
 	sl.mu.RLock()
@@ -459,34 +1353,47 @@ func (sl *SkipList) CountByScore(min, max *big.Rat) int {
 func (sl *SkipList) RemoveByScore(min, max *big.Rat) int {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
+	return sl.removeByScoreInternal(min, max)
+}
 
-	// 收集要删除的节点
-	var toDelete []*skipNode
+// removeByScoreInternal 删除分数范围内的所有成员（内部方法，调用者必须持有写锁）
+func (sl *SkipList) removeByScoreInternal(min, max *big.Rat) int {
+	buf := getUpdateBuf(sl.level)
+	defer putUpdateBuf(buf)
+	update := *buf
+
+	// 定位到第一个 >= min 的节点，同时记录每层的前驱
 	node := sl.head
 	for i := sl.level - 1; i >= 0; i-- {
 		for node.forward[i] != nil && compare(node.forward[i].score, min) < 0 {
 			node = node.forward[i]
 		}
+		update[i] = node
 	}
 	node = node.forward[0]
 
+	// 被删除节点的前驱保持不变，整段删除只需一次定位
+	removed := 0
 	for node != nil && compare(node.score, max) <= 0 {
-		toDelete = append(toDelete, node)
-		node = node.forward[0]
-	}
-
-	for _, n := range toDelete {
-		sl.deleteByNode(n)
+		next := node.forward[0]
+		sl.deleteNode(node, update)
+		removed++
+		node = next
 	}
+	sl.deletes.Add(int64(removed))
 
-	return len(toDelete)
+	return removed
 }
 
 // RemoveByRank 删除排名范围内的所有成员 [start, stop] 1-based
 func (sl *SkipList) RemoveByRank(start, stop int) int {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
+	return sl.removeByRankInternal(start, stop)
+}
 
+// removeByRankInternal 删除排名范围内的所有成员（内部方法，调用者必须持有写锁）
+func (sl *SkipList) removeByRankInternal(start, stop int) int {
 	if start < 1 {
 		start = 1
 	}
@@ -497,52 +1404,58 @@ func (sl *SkipList) RemoveByRank(start, stop int) int {
 		return 0
 	}
 
-	// 定位到 start 位置
-	node := sl.getNodeByRankInternal(start)
+	buf := getUpdateBuf(sl.level)
+	defer putUpdateBuf(buf)
+	update := *buf
+
+	// 定位到排名 start 的前驱，同时记录每层的前驱
+	traversed := 0
+	node := sl.head
+	for i := sl.level - 1; i >= 0; i-- {
+		for node.forward[i] != nil && traversed+node.span[i] < start {
+			traversed += node.span[i]
+			node = node.forward[i]
+		}
+		update[i] = node
+	}
+	node = node.forward[0]
 
 	count := 0
 	for node != nil && start+count <= stop {
 		next := node.forward[0]
-		sl.deleteByNode(node)
+		sl.deleteNode(node, update)
 		count++
 		node = next
 	}
+	sl.deletes.Add(int64(count))
 
 	return count
 }
 
-// InRankRange 检查成员是否在指定排名范围内
+// InRankRange 检查成员的排名是否在 [start, stop] 内（排名从1开始，与 GetRank 一致，闭区间）
+// 排名按成员当前的分数通过 span 计算；score 参数仅为兼容保留，不参与计算。成员不存在时返回 false
 func (sl *SkipList) InRankRange(member string, score *big.Rat, start, stop int) bool {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
 
-	rank := 0
-	node := sl.head
-
-	for i := sl.level - 1; i >= 0; i-- {
-		for node.forward[i] != nil {
-			cmp := compare(node.forward[i].score, score)
-			if cmp < 0 || (cmp == 0 && node.forward[i].member <= member) {
-				rank += node.span[i]
-				node = node.forward[i]
-				if node.member == member {
-					return rank >= start && rank <= stop
-				}
-			} else {
-				break
-			}
-		}
+	node, ok := sl.memberMap[sl.key(member)]
+	if !ok {
+		return false
 	}
-
-	return false
+	rank := sl.getRankInternal(member, node.score)
+	return rank >= start && rank <= stop
 }
 
 // IncrementBy 增加成员的分数
 func (sl *SkipList) IncrementBy(member string, increment *big.Rat) (*big.Rat, bool) {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
+	return sl.incrementByInternal(member, increment)
+}
 
-	existingNode, exists := sl.memberMap[member]
+// incrementByInternal 增加成员的分数（内部方法，调用者必须持有写锁）
+func (sl *SkipList) incrementByInternal(member string, increment *big.Rat) (*big.Rat, bool) {
+	existingNode, exists := sl.memberMap[sl.key(member)]
 	var newScore *big.Rat
 
 	if !exists {
@@ -551,47 +1464,152 @@ func (sl *SkipList) IncrementBy(member string, increment *big.Rat) (*big.Rat, bo
 	} else {
 		// 计算新分数
 		newScore = new(big.Rat).Add(existingNode.score, increment)
-		// 删除旧节点
-		sl.deleteByNode(existingNode)
 	}
 
-	// 插入新节点
+	// 由 insertInternal 完成更新（删除旧节点后重新插入，保留附带的数据）
 	sl.insertInternal(member, newScore)
 	return new(big.Rat).Set(newScore), true
 }
 
-// Len 返回元素数量
+// OpCounts 返回跳表生命周期内累计新增与删除的成员数，通过原子计数读取，无需加锁
+// 更新已有成员的分数在内部虽然是"删除旧节点 + 插入新节点"，但不计入任何一项；
+// Clear 清空的成员计入删除
+func (sl *SkipList) OpCounts() (inserts, deletes int64) {
+	return sl.inserts.Load(), sl.deletes.Load()
+}
+
+// Len 返回元素数量，通过原子计数读取，无需加锁
 func (sl *SkipList) Len() int {
-	sl.mu.RLock()
-	defer sl.mu.RUnlock()
-	return sl.length
+	return int(sl.size.Load())
 }
 
 // All 获取所有成员（按分数排序）
 func (sl *SkipList) All() []ScoreMember {
 	sl.mu.RLock()
 	defer sl.mu.RUnlock()
+	return sl.allInternal()
+}
+
+// Iterate 在读锁内按顺序遍历所有成员，fn 返回 false 时提前结束，不分配结果切片
+// 传给 fn 的 score 是副本，可以安全修改；fn 执行期间持有读锁，不能对同一跳表发起写操作
+func (sl *SkipList) Iterate(reverse bool, fn func(member string, score *big.Rat) bool) {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
 
+	node := sl.head.forward[0]
+	if reverse {
+		node = sl.tail
+	}
+	for node != nil {
+		if !fn(node.member, new(big.Rat).Set(node.score)) {
+			return
+		}
+		if reverse {
+			node = node.backward
+		} else {
+			node = node.forward[0]
+		}
+	}
+}
+
+// allInternal 获取所有成员的副本（内部方法，调用者必须持有读锁）
+func (sl *SkipList) allInternal() []ScoreMember {
 	result := make([]ScoreMember, 0, sl.length)
 	node := sl.head.forward[0]
 	for node != nil {
 		result = append(result, ScoreMember{
 			Score:  new(big.Rat).Set(node.score),
 			Member: node.member,
+			Value:  bytes.Clone(node.value),
 		})
 		node = node.forward[0]
 	}
 	return result
 }
 
+// allShared 获取所有成员，Score 直接引用节点内部的分数而不复制（内部方法，调用者必须持有读锁）
+// 节点分数在更新时整体替换、从不原地修改，因此释放锁后继续持有这些指针也是安全的；
+// 但调用方绝不能修改返回的 Score，仅供会立即复制或只读使用分数的内部运算使用
+func (sl *SkipList) allShared() []ScoreMember {
+	result := make([]ScoreMember, 0, sl.length)
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		result = append(result, ScoreMember{Score: node.score, Member: node.member, Value: node.value})
+	}
+	return result
+}
+
+// Stats 获取跳表结构的统计信息（只读，持有读锁）
+func (sl *SkipList) Stats() SkipListStats {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	stats := SkipListStats{
+		Length:      sl.length,
+		Level:       sl.level,
+		MaxLevel:    sl.maxLevel,
+		LevelCounts: make([]int, sl.level),
+	}
+	total := 0
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		for i := 0; i < node.level; i++ {
+			stats.LevelCounts[i]++
+		}
+		total += node.level
+	}
+	if sl.length > 0 {
+		stats.AvgLevel = float64(total) / float64(sl.length)
+	}
+	return stats
+}
+
+// 内存估算使用的固定开销
+const (
+	ratOverhead      = int64(unsafe.Sizeof(big.Rat{}))
+	nodeOverhead     = int64(unsafe.Sizeof(skipNode{}))
+	pointerSize      = int64(unsafe.Sizeof(uintptr(0)))
+	wordSize         = int64(unsafe.Sizeof(big.Word(0)))
+	mapEntryOverhead = int64(unsafe.Sizeof("")) + pointerSize + 16 // memberMap 中的 key、value 及桶内平摊开销
+)
+
+// ratSize 估算一个 big.Rat 占用的字节数
+func ratSize(r *big.Rat) int64 {
+	size := ratOverhead + int64(len(r.Num().Bits()))*wordSize
+	if !r.IsInt() {
+		size += int64(len(r.Denom().Bits())) * wordSize
+	}
+	return size
+}
+
+// MemUsage 估算跳表占用的字节数
+// 包括每个节点本身、按节点层级分配的 forward 与 span 数组、成员字符串、分数、附带数据以及 memberMap 条目；
+// 只是近似值，不含分配器对齐与 map 扩容预留的空间
+func (sl *SkipList) MemUsage() int64 {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	size := int64(unsafe.Sizeof(*sl)) + nodeOverhead + int64(sl.maxLevel)*(pointerSize+8)
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		size += nodeOverhead
+		size += int64(node.level) * (pointerSize + 8) // forward 与 span
+		size += int64(len(node.member))
+		size += ratSize(node.score)
+		size += int64(len(node.value))
+		size += mapEntryOverhead
+	}
+	return size
+}
+
 // Clear 清空跳表
 func (sl *SkipList) Clear() {
 	sl.mu.Lock()
 	defer sl.mu.Unlock()
 
 	sl.head = &skipNode{forward: make([]*skipNode, sl.maxLevel), span: make([]int, sl.maxLevel)}
+	sl.deletes.Add(int64(sl.length))
 	sl.tail = nil
 	sl.length = 0
+	sl.size.Store(0)
 	sl.level = 1
 	sl.memberMap = make(map[string]*skipNode)
+	sl.version++
 }
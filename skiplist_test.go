@@ -0,0 +1,596 @@
+package csort
+
+import (
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// TestRandomLevelDistribution 测试节点层级符合 p=0.25 的几何分布
+// randomLevel 使用 math/rand/v2 的全局随机源，该随机源并发安全且在进程启动时随机播种
+func TestRandomLevelDistribution(t *testing.T) {
+	const n = 100000
+	sl := NewSkipList()
+	for i := 0; i < n; i++ {
+		sl.Insert(strconv.Itoa(i), big.NewRat(int64(i), 1))
+	}
+
+	// atLeast[k] 统计层级 >= k+1 的节点数
+	atLeast := make([]int, sl.maxLevel)
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		for k := 0; k < node.level; k++ {
+			atLeast[k]++
+		}
+	}
+
+	if sl.level < 5 {
+		t.Fatalf("skip list level = %d, want >= 5 for %d elements", sl.level, n)
+	}
+
+	// 层级 >= k+1 的期望比例为 p^k，允许 5 个标准差的偏差
+	for k := 1; k <= 4; k++ {
+		want := math.Pow(sl.p, float64(k))
+		got := float64(atLeast[k]) / n
+		tolerance := 5 * math.Sqrt(want*(1-want)/n)
+		if math.Abs(got-want) > tolerance {
+			t.Errorf("fraction of nodes with level >= %d = %.5f, want about %.5f", k+1, got, want)
+		}
+	}
+}
+
+// newBenchSkipList 构建包含 n 个成员的跳表，分数乱序插入
+func newBenchSkipList(n int) *SkipList {
+	sl := NewSkipList()
+	for _, i := range rand.Perm(n) {
+		sl.Insert("m"+strconv.Itoa(i), big.NewRat(int64(i%1000), 1))
+	}
+	return sl
+}
+
+// TestGetByRankMatchesAll 测试基于 span 的 GetByRank/GetRank 与全量遍历结果一致，
+// 包括穿插删除与分数更新之后
+func TestGetByRankMatchesAll(t *testing.T) {
+	const n = 50000
+	sl := newBenchSkipList(n)
+
+	checkRanks := func(stage string) {
+		t.Helper()
+		all := sl.All()
+		if len(all) != sl.Len() {
+			t.Fatalf("%s: All() returned %d items, Len() = %d", stage, len(all), sl.Len())
+		}
+		size := len(all)
+		ranks := []int{1, 2, size - 1, size}
+		for i := 0; i < 1000; i++ {
+			ranks = append(ranks, rand.IntN(size)+1)
+		}
+		for _, k := range ranks {
+			member, score, ok := sl.GetByRank(k)
+			want := all[k-1]
+			if !ok || member != want.Member || score.Cmp(want.Score) != 0 {
+				t.Fatalf("%s: GetByRank(%d) = %s, %v, want %s", stage, k, member, ok, want.Member)
+			}
+			if rank := sl.GetRank(member, score); rank != k {
+				t.Fatalf("%s: GetRank(%s) = %d, want %d", stage, member, rank, k)
+			}
+		}
+		if _, _, ok := sl.GetByRank(0); ok {
+			t.Errorf("%s: GetByRank(0) should return false", stage)
+		}
+		if _, _, ok := sl.GetByRank(size + 1); ok {
+			t.Errorf("%s: GetByRank(size+1) should return false", stage)
+		}
+	}
+
+	checkRanks("after inserts")
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 2000; i++ {
+			member := "m" + strconv.Itoa(rand.IntN(n))
+			if i%2 == 0 {
+				sl.DeleteByMember(member)
+			} else {
+				sl.Insert(member, big.NewRat(int64(rand.IntN(1000)), 1))
+			}
+		}
+		checkRanks("after deletes and re-scores round " + strconv.Itoa(round))
+	}
+}
+
+// TestScanByHashBatches 测试不同批次大小下完整遍历恰好返回每个成员一次，且批次按哈希升序
+func TestScanByHashBatches(t *testing.T) {
+	const n = 1000
+	sl := newBenchSkipList(n)
+
+	for _, count := range []int{1, 7, 100, n, 2 * n} {
+		seen := make(map[string]bool, n)
+		var cursor, last uint64
+		for {
+			next, batch := sl.scanByHash(cursor, count)
+			if len(batch) > count {
+				t.Fatalf("count %d: batch has %d members", count, len(batch))
+			}
+			for _, sm := range batch {
+				h := memberHash(sm.Member)
+				if h < cursor || h < last {
+					t.Fatalf("count %d: member %s out of hash order", count, sm.Member)
+				}
+				if seen[sm.Member] {
+					t.Fatalf("count %d: member %s returned twice", count, sm.Member)
+				}
+				seen[sm.Member] = true
+				last = h
+			}
+			if next == 0 {
+				break
+			}
+			cursor = next
+		}
+		if len(seen) != n {
+			t.Errorf("count %d: scan returned %d members, want %d", count, len(seen), n)
+		}
+	}
+}
+
+// BenchmarkScanByHash 基准测试以小批次完整遍历 10 万成员
+func BenchmarkScanByHash(b *testing.B) {
+	sl := newBenchSkipList(100000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var cursor uint64
+		for {
+			next, _ := sl.scanByHash(cursor, 1000)
+			if next == 0 {
+				break
+			}
+			cursor = next
+		}
+	}
+}
+
+// getByRankLinear 沿最底层逐个遍历定位排名，作为 span 优化前的对照实现
+func getByRankLinear(sl *SkipList, rank int) *skipNode {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+
+	node := sl.head.forward[0]
+	for i := 1; node != nil && i < rank; i++ {
+		node = node.forward[0]
+	}
+	return node
+}
+
+// BenchmarkGetByRankSpan 基准测试基于 span 的排名定位
+func BenchmarkGetByRankSpan(b *testing.B) {
+	sl := newBenchSkipList(50000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sl.GetByRank(i%50000 + 1)
+	}
+}
+
+// BenchmarkGetByRankLinear 基准测试线性遍历的排名定位（对照组）
+func BenchmarkGetByRankLinear(b *testing.B) {
+	sl := newBenchSkipList(50000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		getByRankLinear(sl, i%50000+1)
+	}
+}
+
+// checkSkipListInvariants 校验跳表结构与 memberMap 索引的一致性（调用者需保证无并发写）
+func checkSkipListInvariants(t *testing.T, sl *SkipList) {
+	t.Helper()
+
+	inList := make(map[*skipNode]bool, sl.length)
+	pos := map[*skipNode]int{sl.head: 0}
+	var prev *skipNode
+	count := 0
+	for node := sl.head.forward[0]; node != nil; node = node.forward[0] {
+		if node.backward != prev {
+			t.Fatalf("node %s has wrong backward pointer", node.member)
+		}
+		if prev != nil {
+			cmp := compare(prev.score, node.score)
+			if cmp > 0 || (cmp == 0 && !sl.memberLess(prev.member, node.member)) {
+				t.Fatalf("nodes out of order: %s before %s", prev.member, node.member)
+			}
+		}
+		inList[node] = true
+		prev = node
+		count++
+		pos[node] = count
+	}
+	for i := 0; i < sl.level; i++ {
+		for node := sl.head; node.forward[i] != nil; node = node.forward[i] {
+			if pos[node]+node.span[i] != pos[node.forward[i]] {
+				t.Fatalf("level %d span from position %d is %d, next node is at %d",
+					i, pos[node], node.span[i], pos[node.forward[i]])
+			}
+		}
+	}
+	if sl.tail != prev {
+		t.Fatal("tail does not point to the last node")
+	}
+	if count != sl.length {
+		t.Fatalf("list has %d nodes, length = %d", count, sl.length)
+	}
+	if len(sl.memberMap) != sl.length {
+		t.Fatalf("memberMap has %d entries, length = %d", len(sl.memberMap), sl.length)
+	}
+	for member, node := range sl.memberMap {
+		if !inList[node] {
+			t.Fatalf("memberMap[%s] points to a stale node", member)
+		}
+		if sl.key(node.member) != member {
+			t.Fatalf("memberMap[%s] points to node %s", member, node.member)
+		}
+	}
+}
+
+// TestMemberMapConsistency 测试交替插入、删除、更新同一成员时索引不会指向过期节点
+func TestMemberMapConsistency(t *testing.T) {
+	sl := NewSkipList()
+	members := []string{"a", "b", "c", "d"}
+
+	for i := 0; i < 2000; i++ {
+		member := members[rand.IntN(len(members))]
+		score := big.NewRat(int64(rand.IntN(10)), 1)
+		switch rand.IntN(4) {
+		case 0:
+			sl.Insert(member, score)
+		case 1:
+			sl.DeleteByMember(member)
+		case 2:
+			sl.IncrementBy(member, score)
+		case 3:
+			if old, ok := sl.GetScore(member); ok {
+				sl.Delete(member, old)
+			}
+		}
+		checkSkipListInvariants(t, sl)
+
+		// 索引中的分数必须与最新写入一致
+		for _, sm := range sl.All() {
+			if node := sl.memberMap[sm.Member]; compare(node.score, sm.Score) != 0 {
+				t.Fatalf("memberMap[%s] score %v, list score %v", sm.Member, node.score, sm.Score)
+			}
+		}
+	}
+
+	sl.Insert("a", big.NewRat(1, 1))
+	sl.Clear()
+	checkSkipListInvariants(t, sl)
+	if _, ok := sl.GetScore("a"); ok {
+		t.Error("GetScore after Clear should return false")
+	}
+}
+
+// TestAllReturnsCopies 测试公开的 All 返回分数副本，而 allShared 直接引用节点分数
+func TestAllReturnsCopies(t *testing.T) {
+	sl := NewSkipList()
+	sl.Insert("a", big.NewRat(1, 1))
+	sl.Insert("b", big.NewRat(2, 1))
+
+	all := sl.All()
+	all[0].Score.SetInt64(100)
+	if score, _ := sl.GetScore("a"); score.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("modifying All() result changed the stored score to %v", score)
+	}
+
+	shared := sl.allShared()
+	if len(shared) != 2 || shared[0].Member != "a" || shared[1].Member != "b" {
+		t.Fatalf("allShared = %v, want [a b]", shared)
+	}
+	if shared[0].Score != sl.memberMap["a"].score {
+		t.Error("allShared should reference node scores without copying")
+	}
+
+	// 更新分数会替换节点，已取得的共享指针保持原值
+	sl.IncrementBy("a", big.NewRat(5, 1))
+	if shared[0].Score.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("shared score changed to %v after update", shared[0].Score)
+	}
+}
+
+// BenchmarkCloneAll 基准测试通过 All 复制后重新插入的克隆方式
+func BenchmarkCloneAll(b *testing.B) {
+	sl := newBenchSkipList(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		clone := NewSkipList()
+		for _, sm := range sl.All() {
+			clone.insertInternal(sm.Member, sm.Score)
+		}
+	}
+}
+
+// BenchmarkCloneShared 基准测试通过 allShared 直接重新插入的克隆方式
+func BenchmarkCloneShared(b *testing.B) {
+	sl := newBenchSkipList(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		clone := NewSkipList()
+		sl.mu.RLock()
+		members := sl.allShared()
+		sl.mu.RUnlock()
+		for _, sm := range members {
+			clone.insertInternal(sm.Member, sm.Score)
+		}
+	}
+}
+
+// TestBulkRemoveKeepsInvariants 测试批量删除后跳表结构（包括跨度）保持一致
+func TestBulkRemoveKeepsInvariants(t *testing.T) {
+	for round := 0; round < 50; round++ {
+		sl := NewSkipList()
+		for i := 0; i < 200; i++ {
+			sl.Insert("m"+strconv.Itoa(i), big.NewRat(int64(rand.IntN(50)), 1))
+		}
+
+		min := big.NewRat(int64(rand.IntN(50)), 1)
+		max := new(big.Rat).Add(min, big.NewRat(int64(rand.IntN(20)), 1))
+		want := sl.CountByScore(min, max)
+		if got := sl.RemoveByScore(min, max); got != want {
+			t.Fatalf("RemoveByScore removed %d, want %d", got, want)
+		}
+		checkSkipListInvariants(t, sl)
+		if sl.CountByScore(min, max) != 0 {
+			t.Fatal("members left inside removed score range")
+		}
+
+		start := rand.IntN(sl.Len()+1) + 1
+		stop := start + rand.IntN(30)
+		before := sl.Len()
+		removed := sl.RemoveByRank(start, stop)
+		checkSkipListInvariants(t, sl)
+		if sl.Len() != before-removed {
+			t.Fatalf("Len = %d after removing %d of %d", sl.Len(), removed, before)
+		}
+
+		for _, sm := range sl.All() {
+			if !sl.Delete(sm.Member, sm.Score) {
+				t.Fatalf("Delete(%s) failed", sm.Member)
+			}
+		}
+		checkSkipListInvariants(t, sl)
+	}
+}
+
+// BenchmarkRemoveByScoreBulk 基准测试批量按分数删除
+func BenchmarkRemoveByScoreBulk(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		sl := newBenchSkipList(1000)
+		b.StartTimer()
+		sl.RemoveByScore(big.NewRat(0, 1), big.NewRat(1000, 1))
+	}
+}
+
+// BenchmarkRemoveByRankBulk 基准测试批量按排名删除
+func BenchmarkRemoveByRankBulk(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		sl := newBenchSkipList(1000)
+		b.StartTimer()
+		sl.RemoveByRank(1, 1000)
+	}
+}
+
+// BenchmarkDelete 基准测试逐个删除
+func BenchmarkDelete(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		sl := newBenchSkipList(1000)
+		members := sl.All()
+		b.StartTimer()
+		for _, sm := range members {
+			sl.Delete(sm.Member, sm.Score)
+		}
+	}
+}
+
+// TestCompareMixedScores 测试整数与分数混合时 compare 与 big.Rat.Cmp 结果一致
+func TestCompareMixedScores(t *testing.T) {
+	scores := []*big.Rat{
+		big.NewRat(-3, 1), big.NewRat(-5, 2), big.NewRat(0, 1), big.NewRat(1, 3),
+		big.NewRat(1, 1), big.NewRat(2, 2), big.NewRat(7, 2), big.NewRat(4, 1),
+		new(big.Rat).SetFrac(new(big.Int).Lsh(big.NewInt(1), 100), big.NewInt(1)),
+		new(big.Rat).SetFrac(new(big.Int).Lsh(big.NewInt(1), 100), big.NewInt(3)),
+	}
+	for _, a := range scores {
+		for _, b := range scores {
+			if got, want := compare(a, b), a.Cmp(b); got != want {
+				t.Errorf("compare(%v, %v) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	sl := NewSkipList()
+	for i := 0; i < 500; i++ {
+		score := big.NewRat(int64(rand.IntN(100)), 1)
+		if i%3 == 0 {
+			score = big.NewRat(int64(rand.IntN(400)), 4)
+		}
+		sl.Insert("m"+strconv.Itoa(i), score)
+	}
+	checkSkipListInvariants(t, sl)
+	all := sl.All()
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Score.Cmp(all[i].Score) > 0 {
+			t.Fatalf("order broken at %d: %v > %v", i, all[i-1].Score, all[i].Score)
+		}
+	}
+}
+
+// BenchmarkCompareInteger 基准测试整数分数比较
+func BenchmarkCompareInteger(b *testing.B) {
+	x, y := big.NewRat(12345, 1), big.NewRat(12346, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		compare(x, y)
+	}
+}
+
+// BenchmarkInsertIntegerScores 基准测试整数分数的插入与查询
+func BenchmarkInsertIntegerScores(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sl := newBenchSkipList(1000)
+		for j := 0; j < 1000; j++ {
+			member := "m" + strconv.Itoa(j)
+			score, _ := sl.GetScore(member)
+			sl.GetRank(member, score)
+		}
+	}
+}
+
+// TestNewSkipListWithConfig 测试自定义最大层级与晋升概率时跳表行为正确
+func TestNewSkipListWithConfig(t *testing.T) {
+	sl := NewSkipListWithConfig(8, 0.5)
+	const n = 5000
+	for _, i := range rand.Perm(n) {
+		sl.Insert("m"+strconv.Itoa(i), big.NewRat(int64(i), 1))
+	}
+	checkSkipListInvariants(t, sl)
+
+	stats := sl.Stats()
+	if stats.MaxLevel != 8 || stats.Level > 8 {
+		t.Errorf("MaxLevel = %d, Level = %d, want max 8", stats.MaxLevel, stats.Level)
+	}
+	// p = 0.5 时 5000 个成员足以填满 8 层
+	if stats.Level != 8 {
+		t.Errorf("Level = %d, want 8", stats.Level)
+	}
+	if stats.AvgLevel < 1.8 || stats.AvgLevel > 2.1 {
+		t.Errorf("AvgLevel = %.3f, want about 2 for p = 0.5", stats.AvgLevel)
+	}
+
+	for i := 0; i < n; i += 97 {
+		member := "m" + strconv.Itoa(i)
+		if rank := sl.GetRank(member, big.NewRat(int64(i), 1)); rank != i+1 {
+			t.Fatalf("GetRank(%s) = %d, want %d", member, rank, i+1)
+		}
+	}
+	if removed := sl.RemoveByRank(1, n/2); removed != n/2 {
+		t.Errorf("RemoveByRank removed %d, want %d", removed, n/2)
+	}
+	sl.DeleteByMember("m4999")
+	checkSkipListInvariants(t, sl)
+	if got := sl.Range(1, 1, false); len(got) != 1 || got[0].Member != "m2500" {
+		t.Errorf("first member = %v, want m2500", got)
+	}
+}
+
+// TestNewSkipListWithConfigValidation 测试非法配置会 panic
+func TestNewSkipListWithConfigValidation(t *testing.T) {
+	invalid := []struct {
+		maxLevel int
+		p        float64
+	}{
+		{0, 0.25}, {65, 0.25}, {16, 0}, {16, 1}, {16, -0.5}, {16, math.NaN()},
+	}
+	for _, tc := range invalid {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewSkipListWithConfig(%d, %v) should panic", tc.maxLevel, tc.p)
+				}
+			}()
+			NewSkipListWithConfig(tc.maxLevel, tc.p)
+		}()
+	}
+
+	sl := NewSkipListWithConfig(1, 0.9)
+	for i := 0; i < 100; i++ {
+		sl.Insert(strconv.Itoa(i), big.NewRat(int64(i), 1))
+	}
+	checkSkipListInvariants(t, sl)
+	if sl.level != 1 {
+		t.Errorf("level = %d, want 1 with maxLevel 1", sl.level)
+	}
+}
+
+// TestInRankRange 测试 1000 个成员的集合中排名范围判断（排名从1开始，闭区间）
+func TestInRankRange(t *testing.T) {
+	sl := NewSkipList()
+	const n = 1000
+	for _, i := range rand.Perm(n) {
+		sl.Insert("m"+strconv.Itoa(i), big.NewRat(int64(i), 1))
+	}
+
+	// 成员 mi 的排名为 i+1
+	cases := []struct {
+		member      string
+		start, stop int
+		want        bool
+	}{
+		{"m0", 1, 1, true},
+		{"m0", 2, n, false},
+		{"m999", 1000, 1000, true},
+		{"m999", 1, 999, false},
+		{"m499", 500, 600, true},
+		{"m499", 400, 500, true},
+		{"m499", 501, 600, false},
+		{"m499", 1, 499, false},
+		{"m750", 1, n, true},
+		{"missing", 1, n, false},
+	}
+	for _, tc := range cases {
+		if got := sl.InRankRange(tc.member, nil, tc.start, tc.stop); got != tc.want {
+			t.Errorf("InRankRange(%s, %d, %d) = %v, want %v", tc.member, tc.start, tc.stop, got, tc.want)
+		}
+	}
+	for i := 0; i < n; i += 37 {
+		member := "m" + strconv.Itoa(i)
+		if !sl.InRankRange(member, nil, i+1, i+1) || sl.InRankRange(member, nil, i+2, n) {
+			t.Fatalf("InRankRange(%s) disagrees with rank %d", member, i+1)
+		}
+	}
+}
+
+// TestNewSkipListWithTieBreak 测试自定义并列规则只改变分数相同成员之间的顺序
+func TestNewSkipListWithTieBreak(t *testing.T) {
+	sl := NewSkipListWithTieBreak(defaultMaxLevel, defaultP, func(a, b string) bool { return a > b })
+	members := []string{"a", "b", "c", "d", "e", "f"}
+	for _, i := range rand.Perm(len(members)) {
+		// a、b、c 分数为 1，d、e、f 分数为 2
+		sl.Insert(members[i], big.NewRat(int64(i/3+1), 1))
+	}
+	checkSkipListInvariants(t, sl)
+
+	var got []string
+	for _, sm := range sl.Range(1, sl.Len(), false) {
+		got = append(got, sm.Member)
+	}
+	if want := []string{"c", "b", "a", "f", "e", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Range = %v, want %v", got, want)
+	}
+	for rank, member := range got {
+		score, _ := sl.GetScore(member)
+		if r := sl.GetRank(member, score); r != rank+1 {
+			t.Errorf("GetRank(%s) = %d, want %d", member, r, rank+1)
+		}
+	}
+
+	sl.DeleteByMember("b")
+	sl.Insert("a", big.NewRat(2, 1))
+	checkSkipListInvariants(t, sl)
+	got = got[:0]
+	for _, sm := range sl.Range(1, sl.Len(), false) {
+		got = append(got, sm.Member)
+	}
+	if want := []string{"c", "f", "e", "d", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Range after updates = %v, want %v", got, want)
+	}
+}
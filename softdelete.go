@@ -0,0 +1,78 @@
+package csort
+
+import "math/big"
+
+// deleteMember 删除成员（调用者必须持有集合写锁）
+// soft 为 true 时记录墓碑，以便之后通过 ZUndelete 恢复
+func (set *ZSet) deleteMember(member string, soft bool) bool {
+	if !soft {
+		return set.sl.deleteByMemberInternal(member)
+	}
+
+	score, ok := set.sl.getScoreInternal(member)
+	if !ok {
+		return false
+	}
+	set.sl.deleteByMemberInternal(member)
+	if set.tombstones == nil {
+		set.tombstones = make(map[string]*big.Rat)
+	}
+	set.tombstones[set.sl.key(member)] = score
+	return true
+}
+
+// ==================== ZUndelete ====================
+
+// ZUndelete 恢复被软删除的成员，恢复为删除前的分数
+// 成员没有墓碑、已被压缩清除，或删除后又以同名成员重新写入时返回 false
+func (c *CacheZSort) ZUndelete(key, member string) bool {
+	set := c.lockExistingZSet(key)
+	if set == nil {
+		return false
+	}
+
+	score, ok := set.tombstones[set.sl.key(member)]
+	if !ok {
+		set.sl.mu.Unlock()
+		return false
+	}
+	if _, exists := set.sl.memberMap[set.sl.key(member)]; exists {
+		set.sl.mu.Unlock()
+		return false
+	}
+	delete(set.tombstones, set.sl.key(member))
+	node := set.sl.insertInternal(member, score)
+
+	var events []Event
+	if c.events.active() {
+		if ev, ok := memberEvent(key, member, nil, node); ok {
+			events = append(events, ev)
+		}
+	}
+	wake := c.notifier.hasWaiters()
+	set.sl.mu.Unlock()
+
+	c.notifier.notify(key, wake)
+	c.events.publish(events)
+	return true
+}
+
+// ==================== ZCompactDeleted ====================
+
+// ZCompactDeleted 彻底清除 key 中所有软删除的墓碑，返回清除的数量
+// 清除后这些成员无法再恢复
+func (c *CacheZSort) ZCompactDeleted(key string) int {
+	set := c.lockExistingZSet(key)
+	if set == nil {
+		return 0
+	}
+
+	count := len(set.tombstones)
+	set.tombstones = nil
+	set.sl.mu.Unlock()
+
+	if count > 0 {
+		c.removeIfEmpty(key, set)
+	}
+	return count
+}
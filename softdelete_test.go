@@ -0,0 +1,88 @@
+package csort
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestWithSoftDelete 测试软删除、恢复与压缩
+func TestWithSoftDelete(t *testing.T) {
+	cache := New(WithSoftDelete())
+
+	cache.ZAddInt64("test", "a", 10)
+	cache.ZAddInt64("test", "b", 20)
+	cache.ZAddInt64("test", "c", 30)
+
+	if !cache.ZRem("test", "b") {
+		t.Fatal("ZRem(b) should succeed")
+	}
+
+	// 软删除的成员不出现在查询中
+	if _, ok := cache.ZScore("test", "b"); ok {
+		t.Error("ZScore should not find a soft-deleted member")
+	}
+	if _, ok := cache.ZRank("test", "b"); ok {
+		t.Error("ZRank should not find a soft-deleted member")
+	}
+	if rank, _ := cache.ZRank("test", "c"); rank != 1 {
+		t.Errorf("ZRank(c) = %d, want 1", rank)
+	}
+	if got := cache.ZRange("test", 0, -1, false); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("ZRange = %v, want [a c]", got)
+	}
+	if card, _ := cache.ZCard("test"); card != 2 {
+		t.Errorf("ZCard = %d, want 2", card)
+	}
+
+	// 恢复
+	if !cache.ZUndelete("test", "b") {
+		t.Fatal("ZUndelete(b) should succeed")
+	}
+	if score, ok := cache.ZScore("test", "b"); !ok || score.Cmp(big.NewRat(20, 1)) != 0 {
+		t.Errorf("ZScore(b) after undelete = %v, %v, want 20, true", score, ok)
+	}
+	if rank, _ := cache.ZRank("test", "b"); rank != 1 {
+		t.Errorf("ZRank(b) after undelete = %d, want 1", rank)
+	}
+	if cache.ZUndelete("test", "b") {
+		t.Error("ZUndelete of a live member should return false")
+	}
+
+	// 删除后重新写入同名成员，不能再恢复旧分数
+	cache.ZRemMultiple("test", []string{"a", "c"})
+	cache.ZAddInt64("test", "a", 99)
+	if cache.ZUndelete("test", "a") {
+		t.Error("ZUndelete should not overwrite a re-added member")
+	}
+
+	// 压缩后无法恢复
+	if n := cache.ZCompactDeleted("test"); n != 2 {
+		t.Errorf("ZCompactDeleted = %d, want 2", n)
+	}
+	if cache.ZUndelete("test", "c") {
+		t.Error("ZUndelete after compaction should return false")
+	}
+
+	// 未开启软删除时 ZUndelete 无效
+	plain := New()
+	plain.ZAddInt64("test", "a", 1)
+	plain.ZRem("test", "a")
+	if plain.ZUndelete("test", "a") {
+		t.Error("ZUndelete without WithSoftDelete should return false")
+	}
+}
+
+// TestWithSoftDeleteAutoCleanup 测试软删除与自动清理组合时墓碑会保留 key
+func TestWithSoftDeleteAutoCleanup(t *testing.T) {
+	cache := New(WithSoftDelete(), WithAutoCleanup())
+
+	cache.ZAddInt64("test", "a", 1)
+	cache.ZRem("test", "a")
+	if !cache.Exists("test") {
+		t.Fatal("key with tombstones should not be cleaned up")
+	}
+	cache.ZCompactDeleted("test")
+	if cache.Exists("test") {
+		t.Error("key should be cleaned up after compaction")
+	}
+}
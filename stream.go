@@ -0,0 +1,65 @@
+package csort
+
+import (
+	"math/big"
+	"sync"
+)
+
+// ==================== ZRangeByScoreChan ====================
+
+// ZRangeByScoreChan 以通道形式流式返回分数范围内的成员（正序，闭区间）
+// 后台 goroutine 沿跳表遍历并写入容量为 buf 的通道，遍历结束或调用 cancel 后关闭通道。
+//
+// 锁的生命周期：遍历期间一直持有该有序集合的读锁，直到通道被消费完或调用 cancel 为止，
+// 期间对该 key 的写操作会被阻塞。调用方必须消费完通道或调用 cancel；
+// 消费过程中不要对同一 key 发起写操作，否则会死锁。
+// cancel 可重复调用，返回时保证后台 goroutine 已退出、读锁已释放。
+// key 不存在时返回已关闭的通道
+func (c *CacheZSort) ZRangeByScoreChan(key string, min, max *big.Rat, buf int) (<-chan ScoreMember, func()) {
+	if buf < 0 {
+		buf = 0
+	}
+	ch := make(chan ScoreMember, buf)
+
+	set := c.getZSet(key)
+	if set == nil {
+		close(ch)
+		return ch, func() {}
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	sl := set.sl
+
+	go func() {
+		defer close(exited)
+		defer close(ch)
+
+		sl.mu.RLock()
+		defer sl.mu.RUnlock()
+
+		// 利用跳表快速定位到 >= min 的第一个节点
+		node := sl.head
+		for i := sl.level - 1; i >= 0; i-- {
+			for node.forward[i] != nil && compare(node.forward[i].score, min) < 0 {
+				node = node.forward[i]
+			}
+		}
+
+		for node = node.forward[0]; node != nil && compare(node.score, max) <= 0; node = node.forward[0] {
+			sm := ScoreMember{Score: new(big.Rat).Set(node.score), Member: node.member}
+			select {
+			case ch <- sm:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+	return ch, cancel
+}
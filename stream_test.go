@@ -0,0 +1,67 @@
+package csort
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestZRangeByScoreChan 测试以通道形式流式读取分数范围
+func TestZRangeByScoreChan(t *testing.T) {
+	cache := New()
+	for i := int64(1); i <= 10; i++ {
+		cache.ZAddInt64("test", string(rune('a'+i-1)), i)
+	}
+
+	ch, cancel := cache.ZRangeByScoreChan("test", big.NewRat(3, 1), big.NewRat(7, 1), 2)
+	defer cancel()
+
+	var got []string
+	for sm := range ch {
+		got = append(got, sm.Member)
+	}
+	want := []string{"c", "d", "e", "f", "g"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	// key 不存在时通道直接关闭
+	ch, cancel = cache.ZRangeByScoreChan("missing", big.NewRat(0, 1), big.NewRat(10, 1), 0)
+	if _, ok := <-ch; ok {
+		t.Error("Expected closed channel for missing key")
+	}
+	cancel()
+}
+
+// TestZRangeByScoreChanCancel 测试中途取消后通道关闭且读锁释放
+func TestZRangeByScoreChanCancel(t *testing.T) {
+	cache := New()
+	for i := int64(1); i <= 100; i++ {
+		cache.ZAddInt64("test", string(rune('A'+i)), i)
+	}
+
+	ch, cancel := cache.ZRangeByScoreChan("test", big.NewRat(1, 1), big.NewRat(100, 1), 0)
+	for i := 0; i < 3; i++ {
+		if _, ok := <-ch; !ok {
+			t.Fatal("Channel closed too early")
+		}
+	}
+	cancel()
+	cancel() // 重复调用安全
+
+	// 取消后通道最终关闭
+	for range ch {
+	}
+
+	// 读锁已释放，写操作不会阻塞
+	if !cache.ZAddInt64("test", "new", 0) {
+		t.Error("ZAdd after cancel should succeed")
+	}
+	if card, _ := cache.ZCard("test"); card != 101 {
+		t.Errorf("Expected 101 members, got %d", card)
+	}
+}
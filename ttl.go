@@ -0,0 +1,141 @@
+package csort
+
+import "time"
+
+// expired 判断集合是否已过期
+func (c *CacheZSort) expired(set *ZSet) bool {
+	at := set.expireAt.Load()
+	return at != 0 && c.now().UnixNano() >= at
+}
+
+// dropSetLocked 将集合标记为已移除并从 sets 中删除（调用者必须持有 c.mu 写锁）
+func (c *CacheZSort) dropSetLocked(key string, set *ZSet) {
+	set.markRemoved()
+	delete(c.sets, key)
+	c.publishSetsLocked()
+}
+
+// ==================== Expire / TTL / Persist ====================
+
+// Expire 为 key 设置过期时间，过期后 key 被视为不存在（Exists、ZCard 等均按不存在处理）
+// 再次调用会覆盖原有的过期时间；ttl <= 0 时立即删除 key。key 不存在时返回 false。
+// 过期的 key 在下次写入时被新集合替换，或由 NewWithExpiry 启动的后台清理回收内存
+func (c *CacheZSort) Expire(key string, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	set, ok := c.sets[key]
+	if !ok || c.expired(set) {
+		return false
+	}
+	if ttl <= 0 {
+		c.dropSetLocked(key, set)
+		return true
+	}
+	set.expireAt.Store(c.now().Add(ttl).UnixNano())
+	return true
+}
+
+// TTL 获取 key 的剩余生存时间
+// key 不存在时返回 (0, false)；key 存在但没有设置过期时间时返回 (-1, true)
+func (c *CacheZSort) TTL(key string) (time.Duration, bool) {
+	set := c.getZSet(key)
+	if set == nil {
+		return 0, false
+	}
+	at := set.expireAt.Load()
+	if at == 0 {
+		return -1, true
+	}
+	return time.Duration(at - c.now().UnixNano()), true
+}
+
+// Persist 移除 key 的过期时间，使其永不过期
+// 只有 key 存在且原先设置了过期时间时返回 true
+func (c *CacheZSort) Persist(key string) bool {
+	set := c.getZSet(key)
+	if set == nil {
+		return false
+	}
+	return set.expireAt.Swap(0) != 0
+}
+
+// ==================== ZTouch / ZRemStaleThan ====================
+
+// ZTouch 刷新成员的最近访问时间，不改变其分数与排名，使其不会被 ZRemStaleThan 清理
+// key 或成员不存在时返回 false
+func (c *CacheZSort) ZTouch(key, member string) bool {
+	set := c.lockExistingZSet(key)
+	if set == nil {
+		return false
+	}
+	defer set.sl.mu.Unlock()
+	return set.sl.touchInternal(member)
+}
+
+// ZRemStaleThan 删除超过 maxIdle 未变更分数且未被 ZTouch 的成员，返回删除数量
+// 时间取自 WithClock 注入的时钟
+func (c *CacheZSort) ZRemStaleThan(key string, maxIdle time.Duration) int {
+	set := c.lockExistingZSet(key)
+	if set == nil {
+		return 0
+	}
+	removed := set.sl.removeIdleInternal(c.now().Add(-maxIdle))
+	set.sl.mu.Unlock()
+	if len(removed) > 0 {
+		c.removeIfEmpty(key, set)
+		c.events.publish(removeEvents(key, removed))
+	}
+	return len(removed)
+}
+
+// ==================== NewWithExpiry ====================
+
+// NewWithExpiry 创建新的 CacheZSort 实例，并启动每隔 interval 回收过期 key 的后台协程
+// 不使用后台清理时过期 key 仍会被惰性地视为不存在，只是内存要等到下次写入该 key 时才释放；
+// 不再使用实例时应调用 Close 停止后台协程。interval <= 0 时不启动后台清理
+func NewWithExpiry(interval time.Duration, opts ...Option) *CacheZSort {
+	c := New(opts...)
+	if interval <= 0 {
+		return c
+	}
+
+	c.stopSweeper = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.sweepExpired()
+			case <-c.stopSweeper:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// Close 停止后台过期清理协程，可重复调用；未启动后台清理时为空操作
+func (c *CacheZSort) Close() {
+	c.closeOnce.Do(func() {
+		if c.stopSweeper != nil {
+			close(c.stopSweeper)
+		}
+	})
+}
+
+// sweepExpired 删除所有已过期的 key，返回删除的数量
+func (c *CacheZSort) sweepExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for key, set := range c.sets {
+		if c.expired(set) {
+			c.dropSetLocked(key, set)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,161 @@
+package csort
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestExpireLazy 测试过期后 key 被惰性视为不存在
+func TestExpireLazy(t *testing.T) {
+	clock := newFakeClock()
+	cache := New(WithClock(clock.Now))
+	cache.ZAddInt64("session", "a", 1)
+	cache.ZAddInt64("session", "b", 2)
+
+	if cache.Expire("missing", time.Second) {
+		t.Error("Expire on missing key should return false")
+	}
+	if !cache.Expire("session", 10*time.Second) {
+		t.Fatal("Expire should succeed")
+	}
+
+	clock.Advance(9 * time.Second)
+	if card, ok := cache.ZCard("session"); !ok || card != 2 {
+		t.Errorf("ZCard before expiry = %d, %v, want 2, true", card, ok)
+	}
+
+	clock.Advance(time.Second)
+	if cache.Exists("session") {
+		t.Error("expired key should not exist")
+	}
+	if _, ok := cache.ZCard("session"); ok {
+		t.Error("ZCard of expired key should return false")
+	}
+	if _, ok := cache.ZScore("session", "a"); ok {
+		t.Error("ZScore of expired key should return false")
+	}
+	if keys := cache.Keys(); len(keys) != 0 {
+		t.Errorf("Keys = %v, want none", keys)
+	}
+	if cache.Del("session") != 0 {
+		t.Error("Del of expired key should not count it")
+	}
+
+	// 过期后写入创建全新的集合，不带过期时间
+	cache.ZAddInt64("again", "x", 1)
+	cache.Expire("again", time.Second)
+	clock.Advance(time.Second)
+	cache.ZAddInt64("again", "y", 2)
+	if card, _ := cache.ZCard("again"); card != 1 {
+		t.Errorf("ZCard after re-create = %d, want 1", card)
+	}
+	if ttl, _ := cache.TTL("again"); ttl != -1 {
+		t.Errorf("TTL of re-created key = %v, want -1", ttl)
+	}
+
+	// ttl <= 0 立即删除
+	if !cache.Expire("again", 0) || cache.Exists("again") {
+		t.Error("Expire with ttl 0 should delete the key")
+	}
+}
+
+// TestTTLAndPersist 测试剩余时间查询与移除过期时间
+func TestTTLAndPersist(t *testing.T) {
+	clock := newFakeClock()
+	cache := New(WithClock(clock.Now))
+	cache.ZAddInt64("test", "a", 1)
+
+	if _, ok := cache.TTL("missing"); ok {
+		t.Error("TTL of missing key should return false")
+	}
+	if ttl, ok := cache.TTL("test"); !ok || ttl != -1 {
+		t.Errorf("TTL without expiry = %v, %v, want -1, true", ttl, ok)
+	}
+	if cache.Persist("test") {
+		t.Error("Persist without expiry should return false")
+	}
+
+	cache.Expire("test", time.Minute)
+	clock.Advance(20 * time.Second)
+	if ttl, ok := cache.TTL("test"); !ok || ttl != 40*time.Second {
+		t.Errorf("TTL = %v, %v, want 40s, true", ttl, ok)
+	}
+
+	if !cache.Persist("test") {
+		t.Error("Persist should remove the expiry")
+	}
+	clock.Advance(time.Hour)
+	if !cache.Exists("test") {
+		t.Error("persisted key should not expire")
+	}
+}
+
+// TestNewWithExpirySweeper 测试后台清理协程回收过期 key
+func TestNewWithExpirySweeper(t *testing.T) {
+	cache := NewWithExpiry(5 * time.Millisecond)
+	defer cache.Close()
+
+	cache.ZAddInt64("short", "a", 1)
+	cache.ZAddInt64("long", "a", 1)
+	cache.Expire("short", 10*time.Millisecond)
+	cache.Expire("long", time.Hour)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		cache.mu.RLock()
+		_, present := cache.sets["short"]
+		cache.mu.RUnlock()
+		if !present {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("sweeper did not free the expired key")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !cache.Exists("long") {
+		t.Error("sweeper removed a key that has not expired")
+	}
+
+	cache.Close()
+	cache.Close() // 重复调用安全
+}
+
+// TestZTouchPreventsStalePurge 测试 ZTouch 刷新访问时间后成员不会被 ZRemStaleThan 清理
+func TestZTouchPreventsStalePurge(t *testing.T) {
+	clock := newFakeClock()
+	cache := New(WithClock(clock.Now))
+	cache.ZAddInt64("sessions", "active", 10)
+	cache.ZAddInt64("sessions", "idle", 20)
+
+	clock.Advance(50 * time.Second)
+	if !cache.ZTouch("sessions", "active") {
+		t.Fatal("ZTouch should return true for an existing member")
+	}
+	if cache.ZTouch("sessions", "missing") || cache.ZTouch("nokey", "active") {
+		t.Error("ZTouch should return false for missing members or keys")
+	}
+
+	clock.Advance(30 * time.Second)
+	if removed := cache.ZRemStaleThan("sessions", time.Minute); removed != 1 {
+		t.Fatalf("ZRemStaleThan removed %d, want 1", removed)
+	}
+	if _, ok := cache.ZScore("sessions", "idle"); ok {
+		t.Error("idle member should have been purged")
+	}
+
+	// ZTouch 不改变分数与排名
+	score, changedAt, ok := cache.ZScoreAt("sessions", "active")
+	if !ok || score.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Fatalf("active = %v, %v, want 10, true", score, ok)
+	}
+	if !changedAt.Equal(clock.Now().Add(-80 * time.Second)) {
+		t.Errorf("ZTouch should not change the score change time, got %v", changedAt)
+	}
+
+	clock.Advance(time.Minute)
+	if removed := cache.ZRemStaleThan("sessions", time.Minute); removed != 1 {
+		t.Errorf("ZRemStaleThan removed %d after the touch expired, want 1", removed)
+	}
+}
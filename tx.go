@@ -0,0 +1,192 @@
+package csort
+
+import (
+	"math/big"
+	"sort"
+)
+
+// ==================== WithLock ====================
+
+// Tx 在 WithLock 回调内对已锁定的集合进行读写
+// 只能访问调用 WithLock 时列出的 key，其他 key 的操作返回 false；Tx 不得在回调之外使用
+type Tx struct {
+	c       *CacheZSort
+	sets    map[string]*ZSet
+	events  []Event
+	written map[string]bool
+}
+
+// WithLock 同时锁定 keys 对应的全部集合并执行 fn，fn 中通过 tx 进行的读写对其他调用者是原子的
+// 先取得全部集合，再按集合编号升序依次加写锁（与 SnapshotKeys 等多 key 操作的加锁顺序一致，
+// 不受 Rename 移动集合的影响），不存在的 key 会被创建，回调结束后仍为空的集合按 WithAutoCleanup 的配置清理。
+// fn panic 时同样会释放全部锁。只有列出的 key 可以安全访问：
+// fn 中不得直接调用 CacheZSort 的方法读写这些 key，否则会死锁。
+// 需要新建 key 但 key 数量已达上限（WithMaxKeysStrict）时，该 key 在 tx 中不可用。
+// Tx 的写入会触发订阅事件，但不调用写穿透回调
+func (c *CacheZSort) WithLock(keys []string, fn func(tx *Tx)) {
+	unique := make([]string, 0, len(keys))
+	seen := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if _, dup := seen[key]; !dup {
+			seen[key] = struct{}{}
+			unique = append(unique, key)
+		}
+	}
+
+	tx := &Tx{c: c, written: make(map[string]bool)}
+	var wake bool
+	func() {
+		locked := c.lockZSets(unique)
+		defer unlockZSets(locked)
+
+		tx.sets = make(map[string]*ZSet, len(locked))
+		for i, key := range unique {
+			if locked[i] != nil {
+				tx.sets[key] = locked[i]
+			}
+		}
+		fn(tx)
+		wake = c.notifier.hasWaiters()
+	}()
+
+	for key, set := range tx.sets {
+		if tx.written[key] {
+			c.notifier.notify(key, wake)
+		}
+		c.removeIfEmpty(key, set)
+	}
+	c.events.publish(tx.events)
+}
+
+// lockZSets 获取（必要时创建）并锁定多个集合，返回与 keys 一一对应的集合，无法创建的 key 对应位置为 nil
+// 先在不持有任何集合锁的情况下取得全部集合，再按集合编号升序加锁，避免与持有全局锁后再锁集合的操作死锁；
+// 加锁后发现集合已被移除或过期时全部释放并重试
+func (c *CacheZSort) lockZSets(keys []string) []*ZSet {
+	for {
+		sets, ok := c.resolveZSets(keys)
+		if !ok {
+			continue
+		}
+
+		ordered := orderedZSets(sets)
+		valid := true
+		n := 0
+		for ; n < len(ordered); n++ {
+			set := ordered[n]
+			set.sl.mu.Lock()
+			if set.removed || c.expired(set) {
+				valid = false
+				n++
+				break
+			}
+		}
+		if valid {
+			return sets
+		}
+		unlockZSets(ordered[:n])
+	}
+}
+
+// unlockZSets 释放 lockZSets 获取的写锁，跳过 nil
+func unlockZSets(sets []*ZSet) {
+	for _, set := range sets {
+		if set != nil {
+			set.sl.mu.Unlock()
+		}
+	}
+}
+
+// rlockZSets 按集合编号升序获取多个集合的读锁，返回释放这些读锁的函数
+// 同一集合不得出现两次
+func rlockZSets(sets map[string]*ZSet) (runlock func()) {
+	list := make([]*ZSet, 0, len(sets))
+	for _, set := range sets {
+		list = append(list, set)
+	}
+	ordered := orderedZSets(list)
+	for _, set := range ordered {
+		set.sl.mu.RLock()
+	}
+	return func() {
+		for _, set := range ordered {
+			set.sl.mu.RUnlock()
+		}
+	}
+}
+
+// orderedZSets 返回按集合编号升序排列的非 nil 集合，同时锁定多个集合时一律按此顺序加锁
+// 编号在创建时分配且不随 Rename 改变，因此无论集合当前位于哪个 key，各方的加锁顺序都一致
+func orderedZSets(sets []*ZSet) []*ZSet {
+	ordered := make([]*ZSet, 0, len(sets))
+	for _, set := range sets {
+		if set != nil {
+			ordered = append(ordered, set)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].id < ordered[j].id })
+	return ordered
+}
+
+// resolveZSets 依次获取（必要时创建）多个 key 对应的集合
+// 两次查找之间集合可能被 Rename 移到另一个列出的 key 下，同一集合出现两次时返回 false，由调用者重试
+func (c *CacheZSort) resolveZSets(keys []string) ([]*ZSet, bool) {
+	sets := make([]*ZSet, len(keys))
+	seen := make(map[*ZSet]struct{}, len(keys))
+	for i, key := range keys {
+		set, err := c.getOrCreateZSet(key)
+		if err != nil {
+			continue
+		}
+		if _, dup := seen[set]; dup {
+			return nil, false
+		}
+		seen[set] = struct{}{}
+		sets[i] = set
+	}
+	return sets, true
+}
+
+// ZAdd 添加成员或更新其分数，key 未在 WithLock 中列出或违反 WithImmutableScores 时返回 false
+func (tx *Tx) ZAdd(key, member string, score *big.Rat) bool {
+	set := tx.sets[key]
+	if set == nil {
+		return false
+	}
+	if err := tx.c.checkImmutable(set, member, score); err != nil {
+		return false
+	}
+	prev := set.sl.memberMap[set.sl.key(member)]
+	node := set.sl.insertInternal(member, score)
+	if tx.c.events.active() {
+		if ev, ok := memberEvent(key, member, prev, node); ok {
+			tx.events = append(tx.events, ev)
+		}
+	}
+	tx.written[key] = true
+	return true
+}
+
+// ZRem 删除成员，成员不存在或 key 未在 WithLock 中列出时返回 false
+func (tx *Tx) ZRem(key, member string) bool {
+	set := tx.sets[key]
+	if set == nil {
+		return false
+	}
+	prev := set.sl.memberMap[set.sl.key(member)]
+	if !set.deleteMember(member, tx.c.softDelete) {
+		return false
+	}
+	if tx.c.events.active() {
+		tx.events = append(tx.events, removeEvents(key, []ScoreMember{{Score: prev.score, Member: member}})...)
+	}
+	return true
+}
+
+// ZScore 获取成员分数的副本，成员不存在或 key 未在 WithLock 中列出时返回 false
+func (tx *Tx) ZScore(key, member string) (*big.Rat, bool) {
+	set := tx.sets[key]
+	if set == nil {
+		return nil, false
+	}
+	return set.sl.getScoreInternal(member)
+}
@@ -0,0 +1,216 @@
+package csort
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWithLock 测试在同一把锁内读写多个集合
+func TestWithLock(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "x", 5)
+
+	cache.WithLock([]string{"b", "a", "a"}, func(tx *Tx) {
+		score, ok := tx.ZScore("a", "x")
+		if !ok || score.Cmp(big.NewRat(5, 1)) != 0 {
+			t.Errorf("tx.ZScore = %v, %v, want 5", score, ok)
+		}
+		if !tx.ZRem("a", "x") || !tx.ZAdd("b", "x", score) {
+			t.Error("moving x should succeed")
+		}
+		if tx.ZRem("a", "x") {
+			t.Error("removing x twice should fail")
+		}
+		if tx.ZAdd("unlisted", "y", big.NewRat(1, 1)) {
+			t.Error("keys not passed to WithLock should be unavailable")
+		}
+		if _, ok := tx.ZScore("unlisted", "y"); ok {
+			t.Error("ZScore on an unlisted key should fail")
+		}
+	})
+
+	if _, ok := cache.ZScore("a", "x"); ok {
+		t.Error("x should have left a")
+	}
+	if score, ok := cache.ZScore("b", "x"); !ok || score.Cmp(big.NewRat(5, 1)) != 0 {
+		t.Errorf("b x = %v, %v, want 5", score, ok)
+	}
+	if cache.Exists("unlisted") {
+		t.Error("unlisted key should not be created")
+	}
+}
+
+// TestWithLockMoveIsAtomic 测试成员在两个集合之间反复移动时，观察者总能看到它恰好在其中一个集合
+func TestWithLockMoveIsAtomic(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "token", 1)
+
+	var (
+		wg   sync.WaitGroup
+		stop atomic.Bool
+	)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				cache.WithLock([]string{"a", "b"}, func(tx *Tx) {
+					from, to := "a", "b"
+					if _, ok := tx.ZScore("b", "token"); ok {
+						from, to = "b", "a"
+					}
+					score, _ := tx.ZScore(from, "token")
+					tx.ZRem(from, "token")
+					tx.ZAdd(to, "token", score)
+				})
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for !stop.Load() {
+			snap := cache.SnapshotKeys([]string{"a", "b"})
+			count := len(snap["a"]) + len(snap["b"])
+			if count != 1 {
+				t.Errorf("token observed in %d sets, want exactly 1", count)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	stop.Store(true)
+	<-done
+
+	snap := cache.SnapshotKeys([]string{"a", "b"})
+	if len(snap["a"])+len(snap["b"]) != 1 {
+		t.Errorf("final snapshot = %v, want token in exactly one set", snap)
+	}
+}
+
+// TestWithLockPanicReleasesLocks 测试回调 panic 时释放全部集合锁
+func TestWithLockPanicReleasesLocks(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "m", 1)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic in the callback should propagate")
+			}
+		}()
+		cache.WithLock([]string{"a", "b"}, func(tx *Tx) {
+			tx.ZAdd("b", "m", big.NewRat(2, 1))
+			panic("boom")
+		})
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.ZAddInt64("a", "n", 3)
+		cache.ZAddInt64("b", "n", 4)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("locks were not released after the callback panicked")
+	}
+}
+
+// TestWithLockConcurrentRename 测试 WithLock 与来回重命名并发执行时不会死锁，成员也不会丢失
+func TestWithLockConcurrentRename(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "token", 1)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			if !cache.RenameNX("a", "b") {
+				cache.RenameNX("b", "a")
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			cache.WithLock([]string{"a", "b"}, func(tx *Tx) {
+				for _, key := range []string{"a", "b"} {
+					if score, ok := tx.ZScore(key, "token"); ok {
+						tx.ZAdd(key, "token", new(big.Rat).Add(score, big.NewRat(1, 1)))
+					}
+				}
+			})
+		}
+	}()
+	wg.Wait()
+
+	snap := cache.SnapshotKeys([]string{"a", "b"})
+	if len(snap["a"])+len(snap["b"]) != 1 {
+		t.Errorf("final snapshot = %v, want token in exactly one set", snap)
+	}
+}
+
+// TestWithLockOverlappingRename 测试两个有重叠 key 的 WithLock 与重命名并发执行时不会死锁
+// 集合 X 在第一个事务取得它之后从 a 移到 z，按 key 名加锁时两个事务对 X 与 b 的加锁顺序相反：
+// 测试先持有两个集合的锁，让两个事务分别在重命名前后取得集合并排队，再依次放行
+func TestWithLockOverlappingRename(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "token", 0)
+	cache.ZAddInt64("b", "other", 0)
+	x, b := cache.getZSet("a"), cache.getZSet("b")
+
+	x.sl.mu.Lock()
+	b.sl.mu.Lock()
+
+	increment := func(keys []string) func(tx *Tx) {
+		return func(tx *Tx) {
+			for _, key := range keys {
+				if score, ok := tx.ZScore(key, "token"); ok {
+					tx.ZAdd(key, "token", new(big.Rat).Add(score, big.NewRat(1, 1)))
+				}
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		cache.WithLock([]string{"a", "b"}, increment([]string{"a", "b"}))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if !cache.Rename("a", "z") {
+		t.Fatal("Rename(a, z) should succeed")
+	}
+	go func() {
+		defer wg.Done()
+		cache.WithLock([]string{"b", "z"}, increment([]string{"b", "z"}))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	b.sl.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	x.sl.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WithLock deadlocked against Rename")
+	}
+
+	// 第一个事务重命名前取得集合，写入视为发生在重命名之前；第二个事务在 z 下看到它
+	if score, _ := cache.ZScore("z", "token"); score == nil || score.Cmp(big.NewRat(2, 1)) != 0 {
+		t.Errorf("token score = %v, want 2", score)
+	}
+}
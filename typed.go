@@ -0,0 +1,133 @@
+package csort
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Codec 在成员类型 K 与内部字符串成员之间转换
+// Encode 必须是单射（不同的 K 编码为不同的字符串），Decode 是其逆运算
+type Codec[K comparable] interface {
+	Encode(member K) string
+	Decode(member string) (K, error)
+}
+
+// Int64Codec 以十进制字符串编码 int64 成员
+type Int64Codec struct{}
+
+// Encode 将 int64 编码为十进制字符串
+func (Int64Codec) Encode(member int64) string { return strconv.FormatInt(member, 10) }
+
+// Decode 将十进制字符串解码为 int64
+func (Int64Codec) Decode(member string) (int64, error) { return strconv.ParseInt(member, 10, 64) }
+
+// StringCodec 原样使用字符串成员
+type StringCodec struct{}
+
+// Encode 原样返回成员
+func (StringCodec) Encode(member string) string { return member }
+
+// Decode 原样返回成员
+func (StringCodec) Decode(member string) (string, error) { return member, nil }
+
+// TypedScoreMember 表示一个分数与类型化成员
+type TypedScoreMember[K comparable] struct {
+	Score  *big.Rat
+	Member K
+}
+
+// TypedZSet 基于 CacheZSort 的类型化封装，成员以 K 类型读写
+// 内部仍以字符串存储成员，编码与解码由 Codec 完成；分数相同时按编码后的字符串排序
+type TypedZSet[K comparable] struct {
+	cache *CacheZSort
+	codec Codec[K]
+}
+
+// NewTypedZSet 使用指定的编解码器创建类型化封装
+func NewTypedZSet[K comparable](cache *CacheZSort, codec Codec[K]) *TypedZSet[K] {
+	return &TypedZSet[K]{cache: cache, codec: codec}
+}
+
+// NewInt64ZSet 创建以 int64 为成员类型的封装
+func NewInt64ZSet(cache *CacheZSort) *TypedZSet[int64] {
+	return NewTypedZSet[int64](cache, Int64Codec{})
+}
+
+// NewStringZSet 创建以 string 为成员类型的封装
+func NewStringZSet(cache *CacheZSort) *TypedZSet[string] {
+	return NewTypedZSet[string](cache, StringCodec{})
+}
+
+// Cache 返回底层的 CacheZSort
+func (t *TypedZSet[K]) Cache() *CacheZSort {
+	return t.cache
+}
+
+// ZAdd 添加成员或更新其分数
+func (t *TypedZSet[K]) ZAdd(key string, member K, score *big.Rat) bool {
+	return t.cache.ZAdd(key, t.codec.Encode(member), score)
+}
+
+// ZRem 删除成员
+func (t *TypedZSet[K]) ZRem(key string, member K) bool {
+	return t.cache.ZRem(key, t.codec.Encode(member))
+}
+
+// ZScore 获取成员的分数
+func (t *TypedZSet[K]) ZScore(key string, member K) (*big.Rat, bool) {
+	return t.cache.ZScore(key, t.codec.Encode(member))
+}
+
+// ZIncrBy 增加成员的分数，返回本次增加后的新分数（不受之后其他写入的影响）
+func (t *TypedZSet[K]) ZIncrBy(key string, member K, increment *big.Rat) (*big.Rat, bool) {
+	return t.cache.zincrBy(key, t.codec.Encode(member), increment)
+}
+
+// ZRank 获取成员的正序排名（从0开始）
+func (t *TypedZSet[K]) ZRank(key string, member K) (int, bool) {
+	return t.cache.ZRank(key, t.codec.Encode(member))
+}
+
+// ZRevRank 获取成员的倒序排名（从0开始）
+func (t *TypedZSet[K]) ZRevRank(key string, member K) (int, bool) {
+	return t.cache.ZRevRank(key, t.codec.Encode(member))
+}
+
+// ZRange 获取指定排名范围的成员（正序，从0开始，闭区间，支持负数索引）
+// 存在无法解码的成员时返回错误
+func (t *TypedZSet[K]) ZRange(key string, start, stop int) ([]TypedScoreMember[K], error) {
+	return t.decode(t.cache.zrange(key, start, stop, false))
+}
+
+// ZRevRange 获取指定排名范围的成员（倒序，从0开始，闭区间，支持负数索引）
+// 存在无法解码的成员时返回错误
+func (t *TypedZSet[K]) ZRevRange(key string, start, stop int) ([]TypedScoreMember[K], error) {
+	return t.decode(t.cache.zrange(key, start, stop, true))
+}
+
+// ZRangeByScore 获取分数范围内的成员（正序，闭区间）
+// 存在无法解码的成员时返回错误
+func (t *TypedZSet[K]) ZRangeByScore(key string, min, max *big.Rat) ([]TypedScoreMember[K], error) {
+	set := t.cache.getZSet(key)
+	if set == nil {
+		return nil, nil
+	}
+	return t.decode(set.sl.RangeByScore(min, max, false))
+}
+
+// decode 将字符串成员解码为 K
+func (t *TypedZSet[K]) decode(result []ScoreMember) ([]TypedScoreMember[K], error) {
+	if result == nil {
+		return nil, nil
+	}
+	typed := make([]TypedScoreMember[K], 0, len(result))
+	for _, sm := range result {
+		member, err := t.codec.Decode(sm.Member)
+		if err != nil {
+			return nil, fmt.Errorf("decode member %q: %w", sm.Member, err)
+		}
+		typed = append(typed, TypedScoreMember[K]{Score: sm.Score, Member: member})
+	}
+	return typed, nil
+}
@@ -0,0 +1,108 @@
+package csort
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// TestInt64ZSet 测试以 int64 为成员类型读写
+func TestInt64ZSet(t *testing.T) {
+	users := NewInt64ZSet(New())
+	users.ZAdd("lb", 1001, big.NewRat(30, 1))
+	users.ZAdd("lb", 42, big.NewRat(10, 1))
+	users.ZAdd("lb", -7, big.NewRat(20, 1))
+
+	got, err := users.ZRange("lb", 0, -1)
+	if err != nil {
+		t.Fatalf("ZRange error: %v", err)
+	}
+	want := []int64{42, -7, 1001}
+	if len(got) != len(want) {
+		t.Fatalf("ZRange = %v, want %v", got, want)
+	}
+	for i, id := range want {
+		if got[i].Member != id {
+			t.Errorf("ZRange[%d] = %d, want %d", i, got[i].Member, id)
+		}
+	}
+	if got[2].Score.Cmp(big.NewRat(30, 1)) != 0 {
+		t.Errorf("score of 1001 = %v, want 30", got[2].Score)
+	}
+
+	if rank, ok := users.ZRank("lb", -7); !ok || rank != 1 {
+		t.Errorf("ZRank(-7) = %d, %v, want 1, true", rank, ok)
+	}
+	if rank, ok := users.ZRevRank("lb", 1001); !ok || rank != 0 {
+		t.Errorf("ZRevRank(1001) = %d, %v, want 0, true", rank, ok)
+	}
+	if score, ok := users.ZIncrBy("lb", 42, big.NewRat(100, 1)); !ok || score.Cmp(big.NewRat(110, 1)) != 0 {
+		t.Errorf("ZIncrBy(42) = %v, %v, want 110, true", score, ok)
+	}
+	top, _ := users.ZRevRange("lb", 0, 0)
+	if len(top) != 1 || top[0].Member != 42 {
+		t.Errorf("ZRevRange top = %v, want [42]", top)
+	}
+	byScore, _ := users.ZRangeByScore("lb", big.NewRat(0, 1), big.NewRat(50, 1))
+	if len(byScore) != 2 || byScore[0].Member != -7 || byScore[1].Member != 1001 {
+		t.Errorf("ZRangeByScore = %v, want [-7 1001]", byScore)
+	}
+
+	if !users.ZRem("lb", -7) {
+		t.Error("ZRem(-7) should succeed")
+	}
+	if _, ok := users.ZScore("lb", -7); ok {
+		t.Error("-7 should be gone")
+	}
+
+	// 底层缓存中存在无法解码的成员时返回错误
+	users.Cache().ZAddInt64("lb", "not-a-number", 1)
+	if _, err := users.ZRange("lb", 0, -1); err == nil {
+		t.Error("ZRange should fail on members that cannot be decoded")
+	}
+}
+
+// TestTypedZIncrByConcurrent 测试并发自增时每次返回的都是本次写入得到的分数
+func TestTypedZIncrByConcurrent(t *testing.T) {
+	users := NewInt64ZSet(New())
+	const n = 200
+
+	scores := make(chan int64, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			score, ok := users.ZIncrBy("lb", 1, big.NewRat(1, 1))
+			if !ok {
+				t.Error("ZIncrBy should succeed")
+				return
+			}
+			scores <- score.Num().Int64()
+		}()
+	}
+	wg.Wait()
+	close(scores)
+
+	seen := make(map[int64]bool, n)
+	for score := range scores {
+		if score < 1 || score > n || seen[score] {
+			t.Fatalf("ZIncrBy returned %d twice or out of range", score)
+		}
+		seen[score] = true
+	}
+}
+
+// TestStringZSet 测试 string 成员类型的封装
+func TestStringZSet(t *testing.T) {
+	names := NewStringZSet(New())
+	names.ZAdd("lb", "alice", big.NewRat(2, 1))
+	names.ZAdd("lb", "bob", big.NewRat(1, 1))
+	got, err := names.ZRange("lb", 0, -1)
+	if err != nil || len(got) != 2 || got[0].Member != "bob" || got[1].Member != "alice" {
+		t.Errorf("ZRange = %v, %v, want [bob alice]", got, err)
+	}
+	if got, _ := names.ZRange("missing", 0, -1); got != nil {
+		t.Errorf("missing key = %v, want nil", got)
+	}
+}
@@ -0,0 +1,89 @@
+package csort
+
+import "math/big"
+
+// ==================== SetWriteThrough ====================
+
+// SetWriteThrough 设置写穿透回调，传入 nil 表示取消
+// ZAdd 系列方法（ZAdd、ZAddString、ZAddFloat64、ZAddInt64）与 ZIncrBy 在更新内存后
+// 同步调用 fn；fn 返回错误时回滚本次内存修改（分数与附带数据），ZAdd 返回 false、ZAddString 返回该错误。
+// fn 在集合锁之外调用，可以安全地读取缓存，因此回调执行期间其他读取方可能看到尚未确认的分数；
+// 唤醒阻塞弹出与发布订阅事件则推迟到 fn 成功之后。若回调执行期间该成员又被其他写操作写入
+// （包括写入相同的分数），则不再回滚，以免覆盖该写入
+func (c *CacheZSort) SetWriteThrough(fn func(key, member string, score *big.Rat) error) {
+	if fn == nil {
+		c.writeThrough.Store(nil)
+		return
+	}
+	c.writeThrough.Store(&fn)
+}
+
+// hasWriteThrough 是否设置了写穿透回调
+func (c *CacheZSort) hasWriteThrough() bool {
+	return c.writeThrough.Load() != nil
+}
+
+// rollbackScore 记录写入前成员的状态以及本次写入的节点，用于回滚
+type rollbackScore struct {
+	member  string // 写入前的成员名（不区分大小写模式下的显示形式）
+	score   *big.Rat
+	value   []byte
+	existed bool
+
+	node  *skipNode // 本次写入后成员所在的节点
+	stamp uint64    // 本次写入后节点的写入序号
+}
+
+// scoreForRollback 在写入前记录成员的当前分数与附带数据（调用者必须持有集合写锁）
+// need 为 false 时不做任何记录
+func (set *ZSet) scoreForRollback(member string, need bool) *rollbackScore {
+	if !need {
+		return nil
+	}
+	node, existed := set.sl.memberMap[set.sl.key(member)]
+	if !existed {
+		return &rollbackScore{}
+	}
+	return &rollbackScore{
+		member:  node.member,
+		score:   new(big.Rat).Set(node.score),
+		value:   node.value,
+		existed: true,
+	}
+}
+
+// wrote 在写入后记录成员所在的节点及其写入序号（调用者必须持有集合写锁），r 为 nil 时忽略
+func (r *rollbackScore) wrote(node *skipNode) {
+	if r != nil && node != nil {
+		r.node, r.stamp = node, node.stamp
+	}
+}
+
+// applyWriteThrough 调用写穿透回调，失败时回滚本次写入（调用者不得持有集合锁）
+// 只有成员自本次写入后未被再次写入（节点与写入序号均未变化）时才回滚，
+// 回滚同时恢复分数、附带数据与成员名的显示形式
+func (c *CacheZSort) applyWriteThrough(key, member string, score *big.Rat, set *ZSet, old *rollbackScore) error {
+	fn := c.writeThrough.Load()
+	if fn == nil || old == nil {
+		return nil
+	}
+
+	err := (*fn)(key, member, new(big.Rat).Set(score))
+	if err == nil {
+		return nil
+	}
+
+	set.sl.mu.Lock()
+	defer set.sl.mu.Unlock()
+
+	current, ok := set.sl.memberMap[set.sl.key(member)]
+	if !ok || current != old.node || current.stamp != old.stamp {
+		return err
+	}
+	if old.existed {
+		set.sl.insertInternal(old.member, old.score).value = old.value
+	} else {
+		set.sl.deleteByMemberInternal(member)
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package csort
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// TestSetWriteThrough 测试写穿透回调及失败回滚
+func TestSetWriteThrough(t *testing.T) {
+	cache := New()
+	errStore := errors.New("store unavailable")
+
+	var stored []string
+	fail := false
+	cache.SetWriteThrough(func(key, member string, score *big.Rat) error {
+		// 回调中可以安全读取缓存
+		cache.ZScore(key, member)
+		if fail {
+			return errStore
+		}
+		stored = append(stored, key+"/"+member+"="+score.RatString())
+		return nil
+	})
+
+	cache.ZAddInt64("test", "a", 10)
+	cache.ZIncrBy("test", "a", big.NewRat(5, 1))
+	if len(stored) != 2 || stored[0] != "test/a=10" || stored[1] != "test/a=15" {
+		t.Fatalf("write-through calls = %v, want [test/a=10 test/a=15]", stored)
+	}
+
+	fail = true
+
+	// 更新已有成员失败，回滚为原分数
+	if cache.ZAddInt64("test", "a", 100) {
+		t.Error("ZAdd should return false when write-through fails")
+	}
+	if score, _ := cache.ZScore("test", "a"); score.Cmp(big.NewRat(15, 1)) != 0 {
+		t.Errorf("ZScore(a) after failed ZAdd = %v, want 15", score)
+	}
+
+	// 新成员写入失败，回滚为不存在
+	if ok, err := cache.ZAddString("test", "b", "1"); ok || !errors.Is(err, errStore) {
+		t.Errorf("ZAddString = %v, %v, want false, errStore", ok, err)
+	}
+	if _, ok := cache.ZScore("test", "b"); ok {
+		t.Error("member b should be rolled back")
+	}
+
+	// ZIncrBy 失败回滚
+	if _, ok := cache.ZIncrBy("test", "a", big.NewRat(1, 1)); ok {
+		t.Error("ZIncrBy should return false when write-through fails")
+	}
+	if score, _ := cache.ZScore("test", "a"); score.Cmp(big.NewRat(15, 1)) != 0 {
+		t.Errorf("ZScore(a) after failed ZIncrBy = %v, want 15", score)
+	}
+	if card, _ := cache.ZCard("test"); card != 1 {
+		t.Errorf("ZCard = %d, want 1", card)
+	}
+
+	// 取消回调
+	cache.SetWriteThrough(nil)
+	if !cache.ZAddInt64("test", "b", 1) {
+		t.Error("ZAdd should succeed after removing write-through")
+	}
+}
+
+// TestWriteThroughRollbackValue 测试回滚同时恢复附带数据
+func TestWriteThroughRollbackValue(t *testing.T) {
+	cache := New()
+	cache.ZAddWithValue("test", "a", big.NewRat(1, 1), []byte("v1"))
+
+	cache.SetWriteThrough(func(string, string, *big.Rat) error { return errors.New("down") })
+	if cache.ZAddWithValue("test", "a", big.NewRat(2, 1), []byte("v2")) {
+		t.Error("ZAddWithValue should fail when write-through fails")
+	}
+	if cache.ZAddWithValue("test", "a", big.NewRat(1, 1), []byte("v3")) {
+		t.Error("same-score ZAddWithValue should fail when write-through fails")
+	}
+	if score, _ := cache.ZScore("test", "a"); score.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("score after rollback = %v, want 1", score)
+	}
+	if value, _ := cache.ZGetValue("test", "a"); string(value) != "v1" {
+		t.Errorf("value after rollback = %q, want v1", value)
+	}
+}
+
+// TestWriteThroughKeepsConcurrentSameScoreWrite 测试回调期间其他写入方写入相同分数时不回滚
+func TestWriteThroughKeepsConcurrentSameScoreWrite(t *testing.T) {
+	cache := New()
+	nested := false
+	cache.SetWriteThrough(func(key, member string, score *big.Rat) error {
+		if nested {
+			return nil
+		}
+		// 模拟回调执行期间另一写入方成功写入了相同的分数
+		nested = true
+		if !cache.ZAdd(key, member, score) {
+			t.Error("nested ZAdd should succeed")
+		}
+		return errors.New("down")
+	})
+
+	if cache.ZAddInt64("test", "a", 5) {
+		t.Error("outer ZAdd should fail")
+	}
+	if score, ok := cache.ZScore("test", "a"); !ok || score.Cmp(big.NewRat(5, 1)) != 0 {
+		t.Errorf("ZScore(a) = %v, %v, want the concurrent write of 5 to survive", score, ok)
+	}
+}
+
+// TestWriteThroughFailureDoesNotWakePop 测试写穿透失败的写入不会唤醒阻塞弹出
+func TestWriteThroughFailureDoesNotWakePop(t *testing.T) {
+	cache := New()
+	cache.SetWriteThrough(func(string, string, *big.Rat) error {
+		time.Sleep(50 * time.Millisecond)
+		return errors.New("down")
+	})
+
+	done := make(chan bool)
+	go func() {
+		_, _, ok := cache.BZPopMin(context.Background(), []string{"q"}, 300*time.Millisecond)
+		done <- ok
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cache.ZAddInt64("q", "job", 1)
+
+	if <-done {
+		t.Error("BZPopMin should not pop a member whose write was rolled back")
+	}
+	if _, ok := cache.ZScore("q", "job"); ok {
+		t.Error("job should have been rolled back")
+	}
+}
@@ -1,8 +1,17 @@
 package csort
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"math/big"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TestNew 测试创建实例
@@ -479,6 +488,2630 @@ func TestGetNextMember(t *testing.T) {
 	}
 }
 
+// TestZScanBackFrom 测试以成员为锚点向前分页
+func TestZScanBackFrom(t *testing.T) {
+	cache := New()
+
+	for i, m := range []string{"a", "b", "c", "d", "e"} {
+		cache.ZAddInt64("test", m, int64(i+1))
+	}
+
+	// 从中间成员 d 向前取 2 个
+	result := cache.ZScanBackFrom("test", "d", 2, true)
+	if len(result) != 2 {
+		t.Fatalf("ZScanBackFrom(d, 2) returned %d items, want 2", len(result))
+	}
+	if result[0].Member != "c" || result[1].Member != "b" {
+		t.Errorf("ZScanBackFrom(d, 2) = [%s %s], want [c b]", result[0].Member, result[1].Member)
+	}
+	if result[0].Score.Cmp(big.NewRat(3, 1)) != 0 {
+		t.Errorf("ZScanBackFrom(d, 2) first score = %v, want 3", result[0].Score)
+	}
+
+	// 接近表头时截断
+	result = cache.ZScanBackFrom("test", "b", 10, false)
+	if len(result) != 1 || result[0].Member != "a" {
+		t.Errorf("ZScanBackFrom(b, 10) = %v, want [a]", result)
+	}
+	if result[0].Score != nil {
+		t.Error("ZScanBackFrom without scores should leave Score nil")
+	}
+
+	// 锚点为第一个成员
+	if result := cache.ZScanBackFrom("test", "a", 3, true); len(result) != 0 {
+		t.Errorf("ZScanBackFrom(a, 3) returned %d items, want 0", len(result))
+	}
+
+	// 不存在的成员和 key
+	if result := cache.ZScanBackFrom("test", "nonexistent", 3, true); result != nil {
+		t.Error("ZScanBackFrom on non-existent member should return nil")
+	}
+	if result := cache.ZScanBackFrom("nonexistent", "a", 3, true); result != nil {
+		t.Error("ZScanBackFrom on non-existent key should return nil")
+	}
+}
+
+// TestSnapshotKeys 测试多 key 一致性快照
+func TestSnapshotKeys(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("daily", "m", 0)
+	cache.ZAddInt64("weekly", "m", 0)
+
+	// 写入方总是先更新 daily 再更新 weekly，
+	// 因此任一时刻都满足 weekly <= daily <= weekly+1
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := int64(1); ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			cache.ZAddInt64("daily", "m", i)
+			cache.ZAddInt64("weekly", "m", i)
+		}
+	}()
+
+	for i := 0; i < 2000; i++ {
+		snap := cache.SnapshotKeys([]string{"weekly", "daily", "missing", "daily"})
+		if len(snap) != 2 {
+			t.Fatalf("SnapshotKeys returned %d keys, want 2", len(snap))
+		}
+		daily := snap["daily"][0].Score
+		weekly := snap["weekly"][0].Score
+		diff := new(big.Rat).Sub(daily, weekly)
+		if diff.Sign() < 0 || diff.Cmp(big.NewRat(1, 1)) > 0 {
+			t.Fatalf("inconsistent snapshot: daily=%s weekly=%s", daily.RatString(), weekly.RatString())
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// TestZTrend 测试跨多个快照读取成员分数轨迹
+func TestZTrend(t *testing.T) {
+	cache := New()
+
+	cache.ZAddInt64("day1", "alice", 10)
+	cache.ZAddInt64("day2", "bob", 5)
+	cache.ZAddInt64("day3", "alice", 30)
+	cache.ZAddInt64("day3", "bob", 7)
+
+	trend := cache.ZTrend([]string{"day1", "day2", "day3", "day4"}, "alice")
+	if len(trend) != 4 {
+		t.Fatalf("ZTrend returned %d items, want 4", len(trend))
+	}
+	if trend[0] == nil || trend[0].Cmp(big.NewRat(10, 1)) != 0 {
+		t.Errorf("trend[0] = %v, want 10", trend[0])
+	}
+	if trend[1] != nil {
+		t.Errorf("trend[1] = %v, want nil (member absent)", trend[1])
+	}
+	if trend[2] == nil || trend[2].Cmp(big.NewRat(30, 1)) != 0 {
+		t.Errorf("trend[2] = %v, want 30", trend[2])
+	}
+	if trend[3] != nil {
+		t.Errorf("trend[3] = %v, want nil (key absent)", trend[3])
+	}
+}
+
+// TestZAddMultipleString 测试字符串批量添加的全有或全无语义
+func TestZAddMultipleString(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "existing", 1)
+
+	count, err := cache.ZAddMultipleString("test", map[string]string{
+		"a": "1.5",
+		"b": "2/3",
+	})
+	if err != nil || count != 2 {
+		t.Fatalf("ZAddMultipleString = %d, %v, want 2, nil", count, err)
+	}
+
+	// 一个非法分数导致整批失败
+	count, err = cache.ZAddMultipleString("test", map[string]string{
+		"c":   "3",
+		"bad": "not-a-number",
+		"a":   "100",
+	})
+	if !errors.Is(err, ErrInvalidScore) {
+		t.Fatalf("ZAddMultipleString err = %v, want ErrInvalidScore", err)
+	}
+	if !strings.Contains(err.Error(), `"bad"`) {
+		t.Errorf("error %q should name the offending member", err)
+	}
+	if count != 0 {
+		t.Errorf("ZAddMultipleString count = %d, want 0", count)
+	}
+
+	card, _ := cache.ZCard("test")
+	if card != 3 {
+		t.Errorf("ZCard = %d, want 3 (batch must not be applied)", card)
+	}
+	if score, _ := cache.ZScore("test", "a"); score.Cmp(big.NewRat(3, 2)) != 0 {
+		t.Errorf("ZScore(a) = %v, want 3/2 (unchanged)", score)
+	}
+
+	// 失败的批量写入不应创建新 key
+	if _, err := cache.ZAddMultipleString("fresh", map[string]string{"x": "?"}); err == nil {
+		t.Fatal("ZAddMultipleString should fail")
+	}
+	if cache.Exists("fresh") {
+		t.Error("failed batch should not create the key")
+	}
+}
+
+// fakeClock 可控时钟，用于测试时间相关的行为
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now 返回当前时间
+func (fc *fakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+// Advance 将时钟向前拨动 d
+func (fc *fakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = fc.now.Add(d)
+}
+
+// TestZScoreAt 测试分数变更时间
+func TestZScoreAt(t *testing.T) {
+	clock := newFakeClock()
+	cache := New(WithClock(clock.Now))
+
+	start := clock.Now()
+	cache.ZAddInt64("test", "a", 10)
+
+	score, changedAt, ok := cache.ZScoreAt("test", "a")
+	if !ok || score.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Fatalf("ZScoreAt(a) = %v, %v, want 10, true", score, ok)
+	}
+	if !changedAt.Equal(start) {
+		t.Errorf("changedAt = %v, want %v", changedAt, start)
+	}
+
+	// 读操作和相同分数的 ZAdd 不更新时间
+	clock.Advance(time.Minute)
+	cache.ZScore("test", "a")
+	cache.ZRange("test", 0, -1, true)
+	cache.ZAddInt64("test", "a", 10)
+	if _, changedAt, _ = cache.ZScoreAt("test", "a"); !changedAt.Equal(start) {
+		t.Errorf("changedAt after reads = %v, want %v", changedAt, start)
+	}
+
+	// 分数变化的 ZAdd 更新时间
+	cache.ZAddInt64("test", "a", 20)
+	updated := clock.Now()
+	if _, changedAt, _ = cache.ZScoreAt("test", "a"); !changedAt.Equal(updated) {
+		t.Errorf("changedAt after ZAdd = %v, want %v", changedAt, updated)
+	}
+
+	// ZIncrBy 更新时间
+	clock.Advance(time.Minute)
+	cache.ZIncrBy("test", "a", big.NewRat(1, 1))
+	if _, changedAt, _ = cache.ZScoreAt("test", "a"); !changedAt.Equal(clock.Now()) {
+		t.Errorf("changedAt after ZIncrBy = %v, want %v", changedAt, clock.Now())
+	}
+
+	if _, _, ok := cache.ZScoreAt("test", "nonexistent"); ok {
+		t.Error("ZScoreAt should return false for non-existent member")
+	}
+	if _, _, ok := cache.ZScoreAt("nonexistent", "a"); ok {
+		t.Error("ZScoreAt should return false for non-existent key")
+	}
+}
+
+// TestZMemberAtCumulative 测试累计分数阈值查询
+func TestZMemberAtCumulative(t *testing.T) {
+	cache := New()
+
+	cache.ZAddInt64("test", "a", 10)
+	cache.ZAddInt64("test", "b", 20)
+	cache.ZAddInt64("test", "c", 30)
+
+	// 正序累计和：10, 30, 60
+	cases := []struct {
+		threshold int64
+		reverse   bool
+		want      string
+		ok        bool
+	}{
+		{5, false, "a", true},
+		{10, false, "a", true}, // 恰好等于累计和
+		{11, false, "b", true}, // 位于两个累计和之间
+		{30, false, "b", true}, // 恰好等于累计和
+		{31, false, "c", true}, // 位于两个累计和之间
+		{60, false, "c", true}, // 恰好等于总和
+		{61, false, "", false}, // 超过总和
+		{30, true, "c", true},  // 倒序累计和：30, 50, 60
+		{31, true, "b", true},
+		{50, true, "b", true},
+		{51, true, "a", true},
+	}
+	for _, tc := range cases {
+		sm, ok := cache.ZMemberAtCumulative("test", big.NewRat(tc.threshold, 1), tc.reverse)
+		if ok != tc.ok || sm.Member != tc.want {
+			t.Errorf("ZMemberAtCumulative(%d, reverse=%v) = %s, %v, want %s, %v",
+				tc.threshold, tc.reverse, sm.Member, ok, tc.want, tc.ok)
+		}
+	}
+
+	if _, ok := cache.ZMemberAtCumulative("nonexistent", big.NewRat(1, 1), false); ok {
+		t.Error("ZMemberAtCumulative on non-existent key should return false")
+	}
+}
+
+// TestZScanRanked 测试带排名的分批遍历
+func TestZScanRanked(t *testing.T) {
+	cache := New()
+
+	const n = 25
+	for i := 0; i < n; i++ {
+		cache.ZAddInt64("test", fmt.Sprintf("m%02d", i), int64(i*10))
+	}
+
+	var all []RankedMember
+	cursor := uint64(0)
+	pages := 0
+	for {
+		next, members := cache.ZScanRanked("test", cursor, 10)
+		all = append(all, members...)
+		pages++
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	if pages != 3 {
+		t.Errorf("scan took %d pages, want 3", pages)
+	}
+	if len(all) != n {
+		t.Fatalf("scan returned %d members, want %d", len(all), n)
+	}
+	for i, rm := range all {
+		if rm.Rank != i {
+			t.Errorf("member %d has rank %d, want contiguous rank %d", i, rm.Rank, i)
+		}
+		if rank, _ := cache.ZRank("test", rm.Member); rank != rm.Rank {
+			t.Errorf("%s: scan rank %d, ZRank %d", rm.Member, rm.Rank, rank)
+		}
+		if rm.Score.Cmp(big.NewRat(int64(i*10), 1)) != 0 {
+			t.Errorf("%s: score %v, want %d", rm.Member, rm.Score, i*10)
+		}
+	}
+
+	if next, members := cache.ZScanRanked("nonexistent", 0, 10); next != 0 || members != nil {
+		t.Error("ZScanRanked on non-existent key should return 0, nil")
+	}
+}
+
+// TestZMScore 测试批量获取分数
+func TestZMScore(t *testing.T) {
+	cache := New()
+
+	cache.ZAdd("test", "a", big.NewRat(10, 1))
+	cache.ZAdd("test", "b", big.NewRat(1, 3))
+
+	scores := cache.ZMScore("test", "a", "missing", "b")
+	if len(scores) != 3 {
+		t.Fatalf("ZMScore returned %d items, want 3", len(scores))
+	}
+	if scores[0] == nil || scores[0].Cmp(big.NewRat(10, 1)) != 0 {
+		t.Errorf("ZMScore[0] = %v, want 10", scores[0])
+	}
+	if scores[1] != nil {
+		t.Errorf("ZMScore[1] = %v, want nil", scores[1])
+	}
+	if scores[2] == nil || scores[2].Cmp(big.NewRat(1, 3)) != 0 {
+		t.Errorf("ZMScore[2] = %v, want 1/3", scores[2])
+	}
+
+	strs := cache.ZMScoreString("test", 2, "b", "missing", "a")
+	want := []string{"0.33", "", "10.00"}
+	for i := range want {
+		if strs[i] != want[i] {
+			t.Errorf("ZMScoreString[%d] = %q, want %q", i, strs[i], want[i])
+		}
+	}
+
+	// 不存在的 key
+	scores = cache.ZMScore("nonexistent", "a", "b")
+	if len(scores) != 2 || scores[0] != nil || scores[1] != nil {
+		t.Errorf("ZMScore on non-existent key = %v, want [nil nil]", scores)
+	}
+	strs = cache.ZMScoreString("nonexistent", 2, "a")
+	if len(strs) != 1 || strs[0] != "" {
+		t.Errorf("ZMScoreString on non-existent key = %q, want [\"\"]", strs)
+	}
+
+	// 不传成员
+	if scores := cache.ZMScore("test"); len(scores) != 0 {
+		t.Errorf("ZMScore without members returned %d items, want 0", len(scores))
+	}
+}
+
+// TestZSubsetRanked 测试按排名返回成员子集
+func TestZSubsetRanked(t *testing.T) {
+	cache := New()
+
+	for i, m := range []string{"a", "b", "c", "d", "e", "f"} {
+		cache.ZAddInt64("test", m, int64(i*10))
+	}
+
+	result := cache.ZSubsetRanked("test", []string{"e", "ghost", "b", "d", "b"})
+	want := []RankedMember{
+		{Rank: 1, Member: "b", Score: big.NewRat(10, 1)},
+		{Rank: 3, Member: "d", Score: big.NewRat(30, 1)},
+		{Rank: 4, Member: "e", Score: big.NewRat(40, 1)},
+	}
+	if len(result) != len(want) {
+		t.Fatalf("ZSubsetRanked returned %d items, want %d", len(result), len(want))
+	}
+	for i := range want {
+		if result[i].Member != want[i].Member || result[i].Rank != want[i].Rank || result[i].Score.Cmp(want[i].Score) != 0 {
+			t.Errorf("result[%d] = %+v, want %+v", i, result[i], want[i])
+		}
+	}
+
+	if result := cache.ZSubsetRanked("nonexistent", []string{"a"}); result != nil {
+		t.Error("ZSubsetRanked on non-existent key should return nil")
+	}
+}
+
+// TestZCardConcurrent 测试并发写入时无锁读取的成员数量保持一致
+func TestZCardConcurrent(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "seed", 0)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				member := fmt.Sprintf("g%d-%d", g, i%50)
+				if i%3 == 2 {
+					cache.ZRem("test", member)
+				} else {
+					cache.ZAddInt64("test", member, int64(i))
+				}
+			}
+		}(g)
+	}
+
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if card, _ := cache.ZCard("test"); card < 1 || card > 201 {
+				t.Errorf("ZCard = %d, out of range [1, 201]", card)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+
+	card, _ := cache.ZCard("test")
+	if all := cache.ZRange("test", 0, -1, false); card != len(all) {
+		t.Errorf("ZCard = %d, but ZRange returned %d members", card, len(all))
+	}
+}
+
+// TestZRangeByLex 测试字典序范围查询
+func TestZRangeByLex(t *testing.T) {
+	cache := New()
+	for _, m := range []string{"d", "a", "f", "c", "e", "b"} {
+		cache.ZAddInt64("test", m, 0)
+	}
+
+	cases := []struct {
+		min, max      string
+		offset, count int
+		want          []string
+	}{
+		{"[b", "(e", 0, 0, []string{"b", "c", "d"}},
+		{"(b", "[e", 0, 0, []string{"c", "d", "e"}},
+		{"-", "+", 0, 0, []string{"a", "b", "c", "d", "e", "f"}},
+		{"-", "(c", 0, 0, []string{"a", "b"}},
+		{"[e", "+", 0, 0, []string{"e", "f"}},
+		{"[bb", "[dd", 0, 0, []string{"c", "d"}},
+		{"-", "+", 1, 2, []string{"b", "c"}},
+		{"(d", "(e", 0, 0, nil},
+	}
+	for _, tc := range cases {
+		got := cache.ZRangeByLex("test", tc.min, tc.max, tc.offset, tc.count)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("ZRangeByLex(%s, %s, %d, %d) = %v, want %v", tc.min, tc.max, tc.offset, tc.count, got, tc.want)
+		}
+	}
+
+	// 非法边界
+	for _, bounds := range [][2]string{{"b", "+"}, {"+", "[c"}, {"-", "-"}} {
+		if got := cache.ZRangeByLex("test", bounds[0], bounds[1], 0, 0); got != nil {
+			t.Errorf("ZRangeByLex(%s, %s) = %v, want nil", bounds[0], bounds[1], got)
+		}
+	}
+
+	// SkipList 层直接调用
+	got := cache.getZSet("test").sl.RangeByLex("", "c", true, true)
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("SkipList.RangeByLex = %v, want [a b c]", got)
+	}
+}
+
+// TestZRevRangeByLex 测试按字典序倒序获取区间
+func TestZRevRangeByLex(t *testing.T) {
+	cache := New()
+	for _, m := range []string{"d", "a", "f", "c", "e", "b"} {
+		cache.ZAddInt64("test", m, 0)
+	}
+
+	cases := []struct {
+		max, min      string
+		offset, count int
+		want          []string
+	}{
+		{"(e", "[b", 0, 0, []string{"d", "c", "b"}},
+		{"[e", "(b", 0, 0, []string{"e", "d", "c"}},
+		{"+", "-", 0, 0, []string{"f", "e", "d", "c", "b", "a"}},
+		{"(c", "-", 0, 0, []string{"b", "a"}},
+		{"+", "[e", 0, 0, []string{"f", "e"}},
+		{"[dd", "[bb", 0, 0, []string{"d", "c"}},
+		{"+", "-", 1, 2, []string{"e", "d"}},
+		{"(e", "(d", 0, 0, nil},
+		{"[a", "-", 0, 0, []string{"a"}},
+	}
+	for _, tc := range cases {
+		got := cache.ZRevRangeByLex("test", tc.max, tc.min, tc.offset, tc.count)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("ZRevRangeByLex(%s, %s, %d, %d) = %v, want %v", tc.max, tc.min, tc.offset, tc.count, got, tc.want)
+		}
+	}
+
+	// 非法边界（max 在前）
+	for _, bounds := range [][2]string{{"+", "b"}, {"[c", "+"}, {"-", "-"}} {
+		if got := cache.ZRevRangeByLex("test", bounds[0], bounds[1], 0, 0); got != nil {
+			t.Errorf("ZRevRangeByLex(%s, %s) = %v, want nil", bounds[0], bounds[1], got)
+		}
+	}
+	if got := cache.ZRevRangeByLex("missing", "+", "-", 0, 0); got != nil {
+		t.Errorf("missing key = %v, want nil", got)
+	}
+}
+
+// TestZMergeMaxMin 测试批量保留极值分数
+func TestZMergeMaxMin(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("high", "a", 10)
+	cache.ZAddInt64("high", "b", 20)
+	cache.ZAddInt64("high", "c", 30)
+
+	n := cache.ZMergeMax("high", map[string]*big.Rat{
+		"a": big.NewRat(15, 1), // 更高，更新
+		"b": big.NewRat(5, 1),  // 更低，保持不变
+		"c": big.NewRat(30, 1), // 相等，保持不变
+		"d": big.NewRat(1, 1),  // 不存在，创建
+	})
+	if n != 2 {
+		t.Errorf("ZMergeMax = %d, want 2", n)
+	}
+	for member, want := range map[string]int64{"a": 15, "b": 20, "c": 30, "d": 1} {
+		if score, _ := cache.ZScore("high", member); score.Cmp(big.NewRat(want, 1)) != 0 {
+			t.Errorf("ZMergeMax: score(%s) = %v, want %d", member, score, want)
+		}
+	}
+
+	cache.ZAddInt64("low", "a", 10)
+	cache.ZAddInt64("low", "b", 20)
+
+	n = cache.ZMergeMin("low", map[string]*big.Rat{
+		"a": big.NewRat(15, 1), // 更高，保持不变
+		"b": big.NewRat(5, 1),  // 更低，更新
+		"c": big.NewRat(99, 1), // 不存在，创建
+	})
+	if n != 2 {
+		t.Errorf("ZMergeMin = %d, want 2", n)
+	}
+	for member, want := range map[string]int64{"a": 10, "b": 5, "c": 99} {
+		if score, _ := cache.ZScore("low", member); score.Cmp(big.NewRat(want, 1)) != 0 {
+			t.Errorf("ZMergeMin: score(%s) = %v, want %d", member, score, want)
+		}
+	}
+	if rank, _ := cache.ZRank("low", "b"); rank != 0 {
+		t.Errorf("ZMergeMin: ZRank(b) = %d, want 0", rank)
+	}
+}
+
+// TestZMergeImmutable 测试只追加模式下合并不修改已存在成员
+func TestZMergeImmutable(t *testing.T) {
+	cache := New(WithImmutableScores())
+	cache.ZAddInt64("k", "a", 10)
+
+	n := cache.ZMergeMax("k", map[string]*big.Rat{"a": big.NewRat(20, 1), "b": big.NewRat(1, 1)})
+	if n != 1 {
+		t.Errorf("ZMergeMax = %d, want 1 (only b is new)", n)
+	}
+	if score, _ := cache.ZScore("k", "a"); score.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Errorf("score(a) = %v, want 10", score)
+	}
+}
+
+// TestZRandMember 测试随机返回成员
+func TestZRandMember(t *testing.T) {
+	cache := New()
+	for i := int64(0); i < 20; i++ {
+		cache.ZAddInt64("test", fmt.Sprintf("m%d", i), i)
+	}
+	exists := func(member interface{}) bool {
+		_, ok := cache.ZScore("test", member.(string))
+		return ok
+	}
+
+	// 正数：互不重复
+	for _, count := range []int{1, 5, 20, 50} {
+		got := cache.ZRandMember("test", count, false)
+		want := count
+		if want > 20 {
+			want = 20
+		}
+		if len(got) != want {
+			t.Errorf("ZRandMember(%d) returned %d members, want %d", count, len(got), want)
+		}
+		seen := make(map[interface{}]bool)
+		for _, m := range got {
+			if seen[m] {
+				t.Errorf("ZRandMember(%d) returned duplicate %v", count, m)
+			}
+			seen[m] = true
+			if !exists(m) {
+				t.Errorf("ZRandMember(%d) returned unknown member %v", count, m)
+			}
+		}
+	}
+
+	// 负数：恰好 abs(count) 个，允许重复
+	got := cache.ZRandMember("test", -100, false)
+	if len(got) != 100 {
+		t.Errorf("ZRandMember(-100) returned %d members, want 100", len(got))
+	}
+	for _, m := range got {
+		if !exists(m) {
+			t.Errorf("ZRandMember(-100) returned unknown member %v", m)
+		}
+	}
+
+	// 零：空结果
+	if got := cache.ZRandMember("test", 0, false); len(got) != 0 {
+		t.Errorf("ZRandMember(0) = %v, want empty", got)
+	}
+
+	// 带分数
+	got = cache.ZRandMember("test", 3, true)
+	if len(got) != 6 {
+		t.Fatalf("ZRandMember(3, withScores) returned %d items, want 6", len(got))
+	}
+	for i := 0; i < len(got); i += 2 {
+		score, _ := cache.ZScoreString("test", got[i].(string))
+		if got[i+1] != score {
+			t.Errorf("score of %v = %v, want %s", got[i], got[i+1], score)
+		}
+	}
+
+	if got := cache.ZRandMember("missing", 3, false); got != nil {
+		t.Errorf("ZRandMember on missing key = %v, want nil", got)
+	}
+}
+
+// TestZScan 测试按哈希游标增量遍历
+func TestZScan(t *testing.T) {
+	cache := New()
+	for i := 0; i < 100; i++ {
+		cache.ZAddInt64("test", fmt.Sprintf("m%d", i), int64(i))
+	}
+
+	// match 为空：完整遍历恰好访问每个成员一次
+	seen := make(map[string]int)
+	var cursor uint64
+	batches := 0
+	for {
+		next, batch := cache.ZScan("test", cursor, "", 7)
+		for _, sm := range batch {
+			seen[sm.Member]++
+		}
+		batches++
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 100 {
+		t.Errorf("ZScan visited %d members, want 100", len(seen))
+	}
+	for member, n := range seen {
+		if n != 1 {
+			t.Errorf("ZScan visited %s %d times", member, n)
+		}
+	}
+	if batches < 100/7 {
+		t.Errorf("ZScan took %d batches, want at least %d", batches, 100/7)
+	}
+
+	// match 过滤
+	matched := make(map[string]bool)
+	cursor = 0
+	for {
+		next, batch := cache.ZScan("test", cursor, "m1?", 10)
+		for _, sm := range batch {
+			matched[sm.Member] = true
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if len(matched) != 10 {
+		t.Errorf("ZScan with match m1? found %d members, want 10: %v", len(matched), matched)
+	}
+
+	if next, batch := cache.ZScan("missing", 0, "", 10); next != 0 || batch != nil {
+		t.Errorf("ZScan on missing key = %d, %v", next, batch)
+	}
+}
+
+// TestZScanWithMutation 测试遍历期间增删成员、修改分数时游标保持稳定
+func TestZScanWithMutation(t *testing.T) {
+	cache := New()
+	for i := 0; i < 100; i++ {
+		cache.ZAddInt64("test", fmt.Sprintf("stable%d", i), int64(i))
+	}
+	for i := 0; i < 20; i++ {
+		cache.ZAddInt64("test", fmt.Sprintf("doomed%d", i), int64(i))
+	}
+
+	seen := make(map[string]int)
+	var cursor uint64
+	round := 0
+	for {
+		next, batch := cache.ZScan("test", cursor, "", 5)
+		for _, sm := range batch {
+			seen[sm.Member]++
+		}
+
+		// 每批之后：删除一个成员、新增一个成员，并打乱已有成员的分数
+		if round < 20 {
+			cache.ZRem("test", fmt.Sprintf("doomed%d", round))
+		}
+		cache.ZAddInt64("test", fmt.Sprintf("added%d", round), int64(-round))
+		cache.ZIncrBy("test", fmt.Sprintf("stable%d", round%100), big.NewRat(1000, 1))
+		round++
+
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	// 全程存在的成员恰好访问一次
+	for i := 0; i < 100; i++ {
+		member := fmt.Sprintf("stable%d", i)
+		if seen[member] != 1 {
+			t.Errorf("%s visited %d times, want 1", member, seen[member])
+		}
+	}
+	// 中途增删的成员至多访问一次
+	for member, n := range seen {
+		if n > 1 {
+			t.Errorf("%s visited %d times", member, n)
+		}
+	}
+}
+
+// TestZMPop 测试从第一个非空集合批量弹出
+func TestZMPop(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("full", "a", 1)
+	cache.ZAddInt64("full", "b", 2)
+	cache.ZAddInt64("full", "c", 3)
+	cache.ZAddInt64("other", "x", 1)
+
+	// 跳过不存在和已清空的 key
+	cache.ZAddInt64("empty", "tmp", 1)
+	cache.ZRem("empty", "tmp")
+
+	key, popped := cache.ZMPop([]string{"missing", "empty", "full", "other"}, true, 2)
+	if key != "full" || len(popped) != 2 || popped[0].Member != "a" || popped[1].Member != "b" {
+		t.Fatalf("ZMPop(min) = %s, %v, want full, [a b]", key, popped)
+	}
+
+	// count 大于基数时弹出全部
+	key, popped = cache.ZMPop([]string{"missing", "full", "other"}, false, 10)
+	if key != "full" || len(popped) != 1 || popped[0].Member != "c" {
+		t.Fatalf("ZMPop(max) = %s, %v, want full, [c]", key, popped)
+	}
+
+	key, popped = cache.ZMPop([]string{"full", "other"}, false, 10)
+	if key != "other" || len(popped) != 1 || popped[0].Member != "x" {
+		t.Fatalf("ZMPop = %s, %v, want other, [x]", key, popped)
+	}
+
+	// 全部为空
+	if key, popped := cache.ZMPop([]string{"missing", "empty", "full", "other"}, true, 1); key != "" || popped != nil {
+		t.Errorf("ZMPop on empty keys = %q, %v, want empty key and nil", key, popped)
+	}
+}
+
+// TestZRangeByScoreWithBounds 测试分数范围查询同时返回实际边界
+func TestZRangeByScoreWithBounds(t *testing.T) {
+	cache := New()
+	cache.ZAdd("test", "a", big.NewRat(5, 2))
+	cache.ZAddInt64("test", "b", 4)
+	cache.ZAddInt64("test", "c", 7)
+	cache.ZAddInt64("test", "d", 50)
+
+	// 请求范围比数据更宽
+	members, lo, hi := cache.ZRangeByScoreWithBounds("test", big.NewRat(0, 1), big.NewRat(10, 1))
+	if len(members) != 3 || members[0].Member != "a" || members[2].Member != "c" {
+		t.Fatalf("members = %v, want [a b c]", members)
+	}
+	if lo.Cmp(big.NewRat(5, 2)) != 0 || hi.Cmp(big.NewRat(7, 1)) != 0 {
+		t.Errorf("bounds = %v, %v, want 5/2, 7", lo, hi)
+	}
+
+	// 返回的边界是副本
+	lo.SetInt64(-1)
+	if members[0].Score.Cmp(big.NewRat(5, 2)) != 0 {
+		t.Error("modifying actualMin should not affect members")
+	}
+
+	// 范围内没有成员
+	members, lo, hi = cache.ZRangeByScoreWithBounds("test", big.NewRat(8, 1), big.NewRat(9, 1))
+	if members != nil || lo != nil || hi != nil {
+		t.Errorf("empty range = %v, %v, %v, want nils", members, lo, hi)
+	}
+}
+
+// TestZScoreRat 测试精确有理数分数的读取与还原
+func TestZScoreRat(t *testing.T) {
+	cache := New()
+	cache.ZAdd("test", "third", big.NewRat(1, 3))
+	cache.ZAddInt64("test", "int", 42)
+	cache.ZIncrBy("test", "inc", big.NewRat(1, 7))
+	cache.ZIncrBy("test", "inc", big.NewRat(1, 7))
+
+	cases := map[string]string{"third": "1/3", "int": "42", "inc": "2/7"}
+	for member, want := range cases {
+		got, ok := cache.ZScoreRat("test", member)
+		if !ok || got != want {
+			t.Errorf("ZScoreRat(%s) = %s, %v, want %s", member, got, ok, want)
+			continue
+		}
+
+		// 往返还原
+		parsed, ok := new(big.Rat).SetString(got)
+		score, _ := cache.ZScore("test", member)
+		if !ok || parsed.Cmp(score) != 0 {
+			t.Errorf("round trip of %s = %v, want %v", got, parsed, score)
+		}
+	}
+
+	// ZScoreString 仍然是舍入后的小数
+	if s, _ := cache.ZScoreString("test", "third"); s != "0.33333333333333333333" {
+		t.Errorf("ZScoreString(third) = %s", s)
+	}
+	if _, ok := cache.ZScoreRat("test", "missing"); ok {
+		t.Error("ZScoreRat of missing member should return false")
+	}
+}
+
+// TestZIncrByMembers 测试批量为多个成员增加相同分数
+func TestZIncrByMembers(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "a", 1)
+	cache.ZAddInt64("test", "b", 2)
+	cache.ZAddInt64("test", "untouched", 3)
+
+	n := cache.ZIncrByMembers("test", []string{"a", "b", "new", "a"}, big.NewRat(10, 1))
+	if n != 3 {
+		t.Errorf("ZIncrByMembers = %d, want 3", n)
+	}
+	for member, want := range map[string]int64{"a": 11, "b": 12, "new": 10, "untouched": 3} {
+		if score, _ := cache.ZScore("test", member); score.Cmp(big.NewRat(want, 1)) != 0 {
+			t.Errorf("score(%s) = %v, want %d", member, score, want)
+		}
+	}
+	if rank, _ := cache.ZRank("test", "untouched"); rank != 0 {
+		t.Errorf("ZRank(untouched) = %d, want 0", rank)
+	}
+
+	if n := cache.ZIncrByMembers("test", nil, big.NewRat(1, 1)); n != 0 {
+		t.Errorf("ZIncrByMembers with no members = %d, want 0", n)
+	}
+}
+
+// TestZLexRank 测试分数相同集合中的字典序排名
+func TestZLexRank(t *testing.T) {
+	cache := New()
+	words := []string{"banana", "apple", "cherry", "apricot", "blueberry"}
+	for _, w := range words {
+		cache.ZAddInt64("index", w, 0)
+	}
+
+	want := map[string]int{"apple": 0, "apricot": 1, "banana": 2, "blueberry": 3, "cherry": 4}
+	for member, rank := range want {
+		got, ok := cache.ZLexRank("index", member)
+		if !ok || got != rank {
+			t.Errorf("ZLexRank(%s) = %d, %v, want %d", member, got, ok, rank)
+		}
+		if zrank, _ := cache.ZRank("index", member); zrank != got {
+			t.Errorf("ZLexRank(%s) = %d, ZRank = %d", member, got, zrank)
+		}
+	}
+
+	for _, member := range []string{"", "aa", "zzz", "b"} {
+		if _, ok := cache.ZLexRank("index", member); ok {
+			t.Errorf("ZLexRank(%q) should not find a missing member", member)
+		}
+	}
+	if _, ok := cache.ZLexRank("missing", "apple"); ok {
+		t.Error("ZLexRank on missing key should return false")
+	}
+}
+
+// TestZDrainSnapshot 测试原子取出并清空
+func TestZDrainSnapshot(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "b", 2)
+	cache.ZAddInt64("test", "a", 1)
+
+	got := cache.ZDrainSnapshot("test")
+	if len(got) != 2 || got[0].Member != "a" || got[1].Member != "b" {
+		t.Errorf("ZDrainSnapshot = %v, want [a b]", got)
+	}
+	if cache.Exists("test") {
+		t.Error("key should not exist after drain")
+	}
+	if got := cache.ZDrainSnapshot("test"); got != nil {
+		t.Errorf("second drain = %v, want nil", got)
+	}
+}
+
+// TestZDrainSnapshotConcurrent 测试并发写入期间反复取出，计数既不重复也不丢失
+func TestZDrainSnapshotConcurrent(t *testing.T) {
+	cache := New()
+	const writers, perWriter = 8, 2000
+	members := []string{"x", "y", "z"}
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				cache.ZIncrBy("counts", members[(w+i)%len(members)], big.NewRat(1, 1))
+			}
+		}(w)
+	}
+
+	total := new(big.Rat)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for drained := false; !drained; {
+		select {
+		case <-done:
+			drained = true
+		default:
+		}
+		for _, sm := range cache.ZDrainSnapshot("counts") {
+			total.Add(total, sm.Score)
+		}
+	}
+
+	if want := big.NewRat(writers*perWriter, 1); total.Cmp(want) != 0 {
+		t.Errorf("drained total = %v, want %v", total, want)
+	}
+}
+
+// TestZRemAfterDrainDetach 测试 ZRem 在取得集合后、加锁前集合被 ZDrainSnapshot 取出时，
+// 不会作用于已脱离 key 的集合并误报成功
+func TestZRemAfterDrainDetach(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("jobs", "a", 1)
+	set := cache.getZSet("jobs")
+
+	// 持有集合锁，让 ZRem 停在取得集合之后、加锁之前
+	set.sl.mu.Lock()
+	result := make(chan bool)
+	go func() {
+		result <- cache.ZRem("jobs", "a")
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// 按 ZDrainSnapshot 的方式在集合锁内将其移出 sets，被取出的成员视为已交给调用方
+	set.removed = true
+	cache.mu.Lock()
+	delete(cache.sets, "jobs")
+	cache.publishSetsLocked()
+	cache.mu.Unlock()
+	set.sl.mu.Unlock()
+
+	if <-result {
+		t.Error("ZRem should not report removing a member from a drained set")
+	}
+	if card := set.sl.Len(); card != 1 {
+		t.Errorf("drained set has %d members, want 1 (untouched by ZRem)", card)
+	}
+}
+
+// TestZNearestByScore 测试按分数距离查找最近的成员
+func TestZNearestByScore(t *testing.T) {
+	cache := New()
+	// 两个价格簇：10 附近与 50 附近
+	for member, score := range map[string]int64{"a": 8, "b": 10, "c": 12, "x": 47, "y": 50, "z": 53} {
+		cache.ZAddInt64("prices", member, score)
+	}
+
+	members := func(result []ScoreMember) string {
+		names := make([]string, len(result))
+		for i, sm := range result {
+			names[i] = sm.Member
+		}
+		return strings.Join(names, ",")
+	}
+
+	// target 位于两簇之间，偏向低簇
+	if got := members(cache.ZNearestByScore("prices", big.NewRat(25, 1), 4)); got != "c,b,a,x" {
+		t.Errorf("nearest 4 to 25 = %s, want c,b,a,x", got)
+	}
+	// 偏向高簇
+	if got := members(cache.ZNearestByScore("prices", big.NewRat(35, 1), 3)); got != "x,y,z" {
+		t.Errorf("nearest 3 to 35 = %s, want x,y,z", got)
+	}
+	// 距离相同时分数较低者在前
+	if got := members(cache.ZNearestByScore("prices", big.NewRat(11, 1), 2)); got != "b,c" {
+		t.Errorf("nearest 2 to 11 = %s, want b,c", got)
+	}
+	// 命中已有分数、超出两端、n 大于基数
+	if got := members(cache.ZNearestByScore("prices", big.NewRat(50, 1), 1)); got != "y" {
+		t.Errorf("nearest 1 to 50 = %s, want y", got)
+	}
+	if got := members(cache.ZNearestByScore("prices", big.NewRat(-100, 1), 2)); got != "a,b" {
+		t.Errorf("nearest 2 to -100 = %s, want a,b", got)
+	}
+	if got := members(cache.ZNearestByScore("prices", big.NewRat(100, 1), 10)); got != "z,y,x,c,b,a" {
+		t.Errorf("nearest 10 to 100 = %s, want z,y,x,c,b,a", got)
+	}
+
+	// 结果按距离非递减
+	result := cache.ZNearestByScore("prices", big.NewRat(30, 1), 6)
+	target := big.NewRat(30, 1)
+	prev := new(big.Rat)
+	for _, sm := range result {
+		dist := new(big.Rat).Sub(sm.Score, target)
+		dist.Abs(dist)
+		if dist.Cmp(prev) < 0 {
+			t.Errorf("results not in distance order: %v", result)
+		}
+		prev = dist
+	}
+
+	if got := cache.ZNearestByScore("missing", target, 3); got != nil {
+		t.Errorf("ZNearestByScore on missing key = %v, want nil", got)
+	}
+}
+
+// TestZAddWithValue 测试成员附带数据的写入、读取与保留
+func TestZAddWithValue(t *testing.T) {
+	cache := New()
+
+	payload := []byte("Alice|2024-01-01")
+	if !cache.ZAddWithValue("board", "alice", big.NewRat(100, 1), payload) {
+		t.Fatal("ZAddWithValue should succeed")
+	}
+	payload[0] = 'X' // 修改调用方的切片不影响存储的数据
+	cache.ZAddInt64("board", "bob", 50)
+
+	if v, ok := cache.ZGetValue("board", "alice"); !ok || string(v) != "Alice|2024-01-01" {
+		t.Errorf("ZGetValue(alice) = %q, %v", v, ok)
+	}
+	if v, ok := cache.ZGetValue("board", "bob"); !ok || v != nil {
+		t.Errorf("ZGetValue(bob) = %q, %v, want nil, true", v, ok)
+	}
+
+	// 仅更新分数时保留附带数据
+	cache.ZAddInt64("board", "alice", 10)
+	cache.ZIncrBy("board", "alice", big.NewRat(5, 1))
+	if v, _ := cache.ZGetValue("board", "alice"); string(v) != "Alice|2024-01-01" {
+		t.Errorf("value after score updates = %q", v)
+	}
+
+	// 同分数重复写入可替换附带数据
+	cache.ZAddWithValue("board", "alice", big.NewRat(15, 1), []byte("Alice v2"))
+	if v, _ := cache.ZGetValue("board", "alice"); string(v) != "Alice v2" {
+		t.Errorf("value after ZAddWithValue = %q", v)
+	}
+
+	// Range 与 All 返回附带数据
+	all := cache.getZSet("board").sl.All()
+	if len(all) != 2 || all[0].Member != "alice" || string(all[0].Value) != "Alice v2" || all[1].Value != nil {
+		t.Errorf("All = %+v", all)
+	}
+	ranged := cache.getZSet("board").sl.Range(1, 1, false)
+	if string(ranged[0].Value) != "Alice v2" {
+		t.Errorf("Range value = %q", ranged[0].Value)
+	}
+
+	// 删除后数据一并清除
+	cache.ZRem("board", "alice")
+	if _, ok := cache.ZGetValue("board", "alice"); ok {
+		t.Error("ZGetValue after ZRem should return false")
+	}
+	cache.ZAddInt64("board", "alice", 1)
+	if v, _ := cache.ZGetValue("board", "alice"); v != nil {
+		t.Errorf("re-added member value = %q, want nil", v)
+	}
+}
+
+// TestZOpCounts 测试累计新增与删除计数
+func TestZOpCounts(t *testing.T) {
+	cache := New()
+	check := func(step string, wantIns, wantDel int64) {
+		t.Helper()
+		if ins, del := cache.ZOpCounts("test"); ins != wantIns || del != wantDel {
+			t.Errorf("%s: ZOpCounts = %d, %d, want %d, %d", step, ins, del, wantIns, wantDel)
+		}
+	}
+
+	cache.ZAddInt64("test", "a", 1)
+	cache.ZAddInt64("test", "b", 2)
+	cache.ZAddInt64("test", "c", 3)
+	cache.ZAddInt64("test", "d", 4)
+	check("adds", 4, 0)
+
+	// 分数更新不计入
+	cache.ZAddInt64("test", "a", 10)
+	cache.ZAddInt64("test", "a", 10)
+	cache.ZIncrBy("test", "b", big.NewRat(5, 1))
+	check("updates", 4, 0)
+
+	// ZIncrBy 创建新成员计入新增
+	cache.ZIncrBy("test", "e", big.NewRat(1, 1))
+	check("incr new", 5, 0)
+
+	cache.ZRem("test", "c")
+	cache.ZRem("test", "missing")
+	cache.ZPopMin("test", 1)
+	cache.ZRemRangeByScore("test", big.NewRat(7, 1), big.NewRat(7, 1))
+	check("removes", 5, 3)
+
+	if ins, del := cache.ZOpCounts("missing"); ins != 0 || del != 0 {
+		t.Errorf("ZOpCounts(missing) = %d, %d", ins, del)
+	}
+}
+
+// TestForEach 测试通过迭代器遍历求和与提前结束
+func TestForEach(t *testing.T) {
+	cache := New()
+	for i := int64(1); i <= 100; i++ {
+		cache.ZAdd("test", fmt.Sprintf("m%03d", i), big.NewRat(i, 2))
+	}
+
+	sum := new(big.Rat)
+	count := 0
+	cache.ForEach("test", false, func(member string, score *big.Rat) bool {
+		sum.Add(sum, score)
+		count++
+		return true
+	})
+	if count != 100 || sum.Cmp(big.NewRat(5050, 2)) != 0 {
+		t.Errorf("ForEach visited %d members, sum %v, want 100, 2525", count, sum)
+	}
+
+	// 倒序遍历并在 3 个成员后停止
+	var got []string
+	cache.ForEach("test", true, func(member string, score *big.Rat) bool {
+		got = append(got, member)
+		score.SetInt64(0) // 修改副本不影响存储的分数
+		return len(got) < 3
+	})
+	if strings.Join(got, ",") != "m100,m099,m098" {
+		t.Errorf("ForEach reverse = %v, want [m100 m099 m098]", got)
+	}
+	if score, _ := cache.ZScore("test", "m100"); score.Cmp(big.NewRat(50, 1)) != 0 {
+		t.Errorf("stored score changed to %v", score)
+	}
+
+	called := false
+	cache.ForEach("missing", false, func(string, *big.Rat) bool {
+		called = true
+		return true
+	})
+	if called {
+		t.Error("ForEach on missing key should not call fn")
+	}
+}
+
+// TestCanonicalString 测试内容相同的集合输出完全一致
+func TestCanonicalString(t *testing.T) {
+	cache := New()
+	entries := []struct {
+		member string
+		score  *big.Rat
+	}{
+		{"carol", big.NewRat(1, 3)},
+		{"alice", big.NewRat(5, 2)},
+		{"bob", big.NewRat(5, 2)},
+		{"a=b", big.NewRat(-7, 1)},
+		{"dave", big.NewRat(100, 1)},
+	}
+	for _, e := range entries {
+		cache.ZAdd("first", e.member, e.score)
+	}
+	// 反序插入，并经过一次分数更新
+	for i := len(entries) - 1; i >= 0; i-- {
+		cache.ZAddInt64("second", entries[i].member, 999)
+		cache.ZAdd("second", entries[i].member, entries[i].score)
+	}
+
+	want := "\"a=b\"=-7\ncarol=1/3\nalice=2.5\nbob=2.5\ndave=100\n"
+	if got := cache.CanonicalString("first"); got != want {
+		t.Errorf("CanonicalString(first) = %q, want %q", got, want)
+	}
+	if cache.CanonicalString("first") != cache.CanonicalString("second") {
+		t.Error("equal sets should produce identical canonical strings")
+	}
+
+	cache.ZAddInt64("second", "dave", 101)
+	if cache.CanonicalString("first") == cache.CanonicalString("second") {
+		t.Error("different sets should produce different canonical strings")
+	}
+	if got := cache.CanonicalString("missing"); got != "" {
+		t.Errorf("CanonicalString(missing) = %q, want empty", got)
+	}
+}
+
+// TestConcurrentSameMember 测试多协程对同一成员并发 ZAdd/ZRem/ZScore（配合 -race 运行）
+func TestConcurrentSameMember(t *testing.T) {
+	cache := New()
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				switch (g + i) % 5 {
+				case 0:
+					cache.ZAddInt64("hot", "m", int64(i))
+				case 1:
+					cache.ZRem("hot", "m")
+				case 2:
+					if score, ok := cache.ZScore("hot", "m"); ok && score == nil {
+						t.Error("ZScore returned ok with nil score")
+					}
+				case 3:
+					cache.ZIncrBy("hot", "m", big.NewRat(1, 1))
+				case 4:
+					cache.ZRank("hot", "m")
+					cache.ZRange("hot", 0, -1, true)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	set := cache.getZSet("hot")
+	if set == nil {
+		return
+	}
+	checkSkipListInvariants(t, set.sl)
+	if card, _ := cache.ZCard("hot"); card > 1 {
+		t.Errorf("ZCard = %d, want at most 1", card)
+	}
+}
+
+// TestZPopTierLimited 测试按分数档弹出且受数量上限约束
+func TestZPopTierLimited(t *testing.T) {
+	cache := New()
+	for i := 0; i < 100; i++ {
+		cache.ZAddInt64("tier", fmt.Sprintf("m%03d", i), 1)
+	}
+	cache.ZAddInt64("tier", "high1", 9)
+	cache.ZAddInt64("tier", "high2", 9)
+
+	popped := cache.ZPopMinTierLimited("tier", 10)
+	if len(popped) != 10 {
+		t.Fatalf("popped %d members, want 10", len(popped))
+	}
+	for i, sm := range popped {
+		if want := fmt.Sprintf("m%03d", i); sm.Member != want {
+			t.Errorf("popped[%d] = %s, want %s", i, sm.Member, want)
+		}
+	}
+	if card, _ := cache.ZCard("tier"); card != 92 {
+		t.Errorf("ZCard = %d, want 92", card)
+	}
+	if _, ok := cache.ZScore("tier", "m010"); !ok {
+		t.Error("m010 should remain")
+	}
+
+	// 档内成员少于上限时只弹出该档
+	top := cache.ZPopMaxTierLimited("tier", 10)
+	if len(top) != 2 || top[0].Member != "high1" || top[1].Member != "high2" {
+		t.Errorf("ZPopMaxTierLimited = %v, want [high1 high2]", top)
+	}
+	top = cache.ZPopMaxTierLimited("tier", 5)
+	if len(top) != 5 || top[0].Member != "m010" || top[4].Member != "m014" {
+		t.Errorf("ZPopMaxTierLimited = %v, want m010..m014", top)
+	}
+
+	if got := cache.ZPopMinTierLimited("tier", 0); got != nil {
+		t.Errorf("maxCount 0 should return nil, got %v", got)
+	}
+	if got := cache.ZPopMinTierLimited("missing", 3); got != nil {
+		t.Errorf("missing key should return nil, got %v", got)
+	}
+}
+
+// TestZRemConcurrentUpdate 测试并发更新分数与删除同一成员时删除不会因分数变化而落空（配合 -race 运行）
+func TestZRemConcurrentUpdate(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("race", "other", 0)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				cache.ZAddInt64("race", "m", int64(g*1000+i))
+			}
+		}(g)
+	}
+
+	for i := 0; i < 2000; i++ {
+		if i%2 == 0 {
+			cache.ZRem("race", "m")
+		} else {
+			cache.ZRemMultiple("race", []string{"m", "absent"})
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	// 写入停止后一次删除必须成功移除成员
+	cache.ZRem("race", "m")
+	if _, ok := cache.ZScore("race", "m"); ok {
+		t.Error("member should be gone after ZRem")
+	}
+	if card, _ := cache.ZCard("race"); card != 1 {
+		t.Errorf("ZCard = %d, want 1", card)
+	}
+	checkSkipListInvariants(t, cache.getZSet("race").sl)
+}
+
+// TestZRangeByScoreEx 测试开区间与无界的分数范围查询
+func TestZRangeByScoreEx(t *testing.T) {
+	cache := New()
+	for i := 1; i <= 5; i++ {
+		cache.ZAddInt64("test", fmt.Sprintf("m%d", i), int64(i*10))
+	}
+
+	cases := []struct {
+		min, max string
+		want     []interface{}
+	}{
+		{"(10", "30", []interface{}{"m2", "m3"}},
+		{"(10", "(40", []interface{}{"m2", "m3"}},
+		{"-inf", "+inf", []interface{}{"m1", "m2", "m3", "m4", "m5"}},
+		{"(30", "+inf", []interface{}{"m4", "m5"}},
+		{"-inf", "(20", []interface{}{"m1"}},
+		{"20", "20", []interface{}{"m2"}},
+		{"(20", "(20", []interface{}{}},
+		{"+inf", "-inf", []interface{}{}},
+	}
+	for _, tc := range cases {
+		got, err := cache.ZRangeByScoreEx("test", tc.min, tc.max, false, 0, 0)
+		if err != nil {
+			t.Fatalf("ZRangeByScoreEx(%s, %s) error: %v", tc.min, tc.max, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("ZRangeByScoreEx(%s, %s) = %v, want %v", tc.min, tc.max, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("ZRangeByScoreEx(%s, %s) = %v, want %v", tc.min, tc.max, got, tc.want)
+				break
+			}
+		}
+	}
+
+	withScores, _ := cache.ZRangeByScoreEx("test", "(10", "+inf", true, 1, 1)
+	if len(withScores) != 2 || withScores[0] != "m3" || withScores[1] != cache.formatScore(big.NewRat(30, 1)) {
+		t.Errorf("withScores with offset = %v, want [m3 30]", withScores)
+	}
+
+	for _, bad := range []string{"abc", "(", "[10", ""} {
+		if _, err := cache.ZRangeByScoreEx("test", bad, "+inf", false, 0, 0); !errors.Is(err, ErrInvalidScore) {
+			t.Errorf("min %q error = %v, want ErrInvalidScore", bad, err)
+		}
+	}
+}
+
+// TestZGroupBy 测试按成员名前缀分组并保持组内分数顺序
+func TestZGroupBy(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("lb", "eu:alice", 30)
+	cache.ZAddInt64("lb", "us:bob", 10)
+	cache.ZAddInt64("lb", "eu:carol", 5)
+	cache.ZAddInt64("lb", "us:dave", 50)
+	cache.ZAddInt64("lb", "ap:erin", 20)
+
+	groups := cache.ZGroupBy("lb", func(m ScoreMember) string {
+		region, _, _ := strings.Cut(m.Member, ":")
+		return region
+	})
+
+	want := map[string][]string{
+		"eu": {"eu:carol", "eu:alice"},
+		"us": {"us:bob", "us:dave"},
+		"ap": {"ap:erin"},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(want))
+	}
+	for region, members := range want {
+		got := groups[region]
+		if len(got) != len(members) {
+			t.Errorf("group %s = %v, want %v", region, got, members)
+			continue
+		}
+		for i, m := range members {
+			if got[i].Member != m {
+				t.Errorf("group %s[%d] = %s, want %s", region, i, got[i].Member, m)
+			}
+		}
+	}
+	if groups["us"][1].Score.Cmp(big.NewRat(50, 1)) != 0 {
+		t.Errorf("us:dave score = %v, want 50", groups["us"][1].Score)
+	}
+
+	if cache.ZGroupBy("missing", func(ScoreMember) string { return "" }) != nil {
+		t.Error("ZGroupBy on missing key should return nil")
+	}
+}
+
+// TestZScoreGroups 测试按分数统计成员数量
+func TestZScoreGroups(t *testing.T) {
+	cache := New()
+	for i, score := range []int64{10, 10, 20, 30, 30, 30} {
+		cache.ZAddInt64("test", fmt.Sprintf("m%d", i), score)
+	}
+
+	groups := cache.ZScoreGroups("test")
+	wantScores := []int64{10, 20, 30}
+	wantCounts := []int{2, 1, 3}
+	if len(groups) != len(wantScores) {
+		t.Fatalf("got %d groups, want %d: %v", len(groups), len(wantScores), groups)
+	}
+	for i, g := range groups {
+		if g.Score.Cmp(big.NewRat(wantScores[i], 1)) != 0 || g.Count != wantCounts[i] {
+			t.Errorf("groups[%d] = {%v %d}, want {%d %d}", i, g.Score, g.Count, wantScores[i], wantCounts[i])
+		}
+	}
+
+	if cache.ZScoreGroups("missing") != nil {
+		t.Error("ZScoreGroups on missing key should return nil")
+	}
+}
+
+// TestZAddRankChanged 测试写入后报告排名是否改变
+func TestZAddRankChanged(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("lb", "a", 10)
+	cache.ZAddInt64("lb", "b", 20)
+
+	// 新增成员
+	changed, rank := cache.ZAddRankChanged("lb", "c", big.NewRat(30, 1))
+	if !changed || rank != 2 {
+		t.Errorf("new insert = (%v, %d), want (true, 2)", changed, rank)
+	}
+
+	// 分数不变
+	changed, rank = cache.ZAddRankChanged("lb", "c", big.NewRat(30, 1))
+	if changed || rank != 2 {
+		t.Errorf("unchanged score = (%v, %d), want (false, 2)", changed, rank)
+	}
+
+	// 分数改变但排名不变
+	changed, rank = cache.ZAddRankChanged("lb", "c", big.NewRat(40, 1))
+	if changed || rank != 2 {
+		t.Errorf("score change without move = (%v, %d), want (false, 2)", changed, rank)
+	}
+
+	// 分数改变且排名移动
+	changed, rank = cache.ZAddRankChanged("lb", "a", big.NewRat(35, 1))
+	if !changed || rank != 1 {
+		t.Errorf("score bump = (%v, %d), want (true, 1)", changed, rank)
+	}
+	if r, _ := cache.ZRank("lb", "a"); r != 1 {
+		t.Errorf("ZRank(a) = %d, want 1", r)
+	}
+}
+
+// TestCompetitionRanks 测试并列分数下的标准排名与密集排名
+func TestCompetitionRanks(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("lb", "a", 10)
+	cache.ZAddInt64("lb", "b", 20)
+	cache.ZAddInt64("lb", "c", 20)
+	cache.ZAddInt64("lb", "d", 30)
+	cache.ZAddInt64("lb", "e", 30)
+	cache.ZAddInt64("lb", "f", 40)
+
+	cases := []struct {
+		member                      string
+		positional, standard, dense int
+	}{
+		{"a", 1, 1, 1},
+		{"b", 2, 2, 2},
+		{"c", 3, 2, 2},
+		{"d", 4, 4, 3},
+		{"e", 5, 4, 3},
+		{"f", 6, 6, 4},
+	}
+	for _, tc := range cases {
+		if got, _ := cache.GetMemberRank("lb", tc.member); got != tc.positional {
+			t.Errorf("GetMemberRank(%s) = %d, want %d", tc.member, got, tc.positional)
+		}
+		if got, ok := cache.ZRankStandard("lb", tc.member); !ok || got != tc.standard {
+			t.Errorf("ZRankStandard(%s) = %d, %v, want %d", tc.member, got, ok, tc.standard)
+		}
+		if got, ok := cache.ZRankDense("lb", tc.member); !ok || got != tc.dense {
+			t.Errorf("ZRankDense(%s) = %d, %v, want %d", tc.member, got, ok, tc.dense)
+		}
+	}
+
+	if _, ok := cache.ZRankStandard("lb", "missing"); ok {
+		t.Error("ZRankStandard for missing member should return false")
+	}
+	if _, ok := cache.ZRankDense("missing", "a"); ok {
+		t.Error("ZRankDense for missing key should return false")
+	}
+}
+
+// TestZAddGTLT 测试仅在分数更高/更低时更新
+func TestZAddGTLT(t *testing.T) {
+	cache := New()
+
+	if !cache.ZAddGT("lb", "a", big.NewRat(10, 1)) {
+		t.Error("ZAddGT should insert a new member")
+	}
+	if !cache.ZAddGT("lb", "a", big.NewRat(20, 1)) {
+		t.Error("ZAddGT should apply a higher score")
+	}
+	if cache.ZAddGT("lb", "a", big.NewRat(5, 1)) {
+		t.Error("ZAddGT should ignore a lower score")
+	}
+	if cache.ZAddGT("lb", "a", big.NewRat(20, 1)) {
+		t.Error("ZAddGT should ignore an equal score")
+	}
+	if score, _ := cache.ZScore("lb", "a"); score.Cmp(big.NewRat(20, 1)) != 0 {
+		t.Errorf("score after ZAddGT = %v, want 20", score)
+	}
+
+	if !cache.ZAddLT("lb", "b", big.NewRat(10, 1)) {
+		t.Error("ZAddLT should insert a new member")
+	}
+	if !cache.ZAddLT("lb", "b", big.NewRat(3, 1)) {
+		t.Error("ZAddLT should apply a lower score")
+	}
+	if cache.ZAddLT("lb", "b", big.NewRat(8, 1)) {
+		t.Error("ZAddLT should ignore a higher score")
+	}
+	if cache.ZAddLT("lb", "b", big.NewRat(3, 1)) {
+		t.Error("ZAddLT should ignore an equal score")
+	}
+	if score, _ := cache.ZScore("lb", "b"); score.Cmp(big.NewRat(3, 1)) != 0 {
+		t.Errorf("score after ZAddLT = %v, want 3", score)
+	}
+}
+
+// TestZAddGTConcurrent 测试并发 ZAddGT 最终保留最大分数
+func TestZAddGTConcurrent(t *testing.T) {
+	cache := New()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				cache.ZAddGT("lb", "m", big.NewRat(int64(i*8+g), 1))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if score, _ := cache.ZScore("lb", "m"); score.Cmp(big.NewRat(199*8+7, 1)) != 0 {
+		t.Errorf("score = %v, want %d", score, 199*8+7)
+	}
+}
+
+// TestZAddNXXX 测试 NX/XX 条件写入
+func TestZAddNXXX(t *testing.T) {
+	cache := New()
+
+	if cache.ZAddXX("test", "a", big.NewRat(1, 1)) {
+		t.Error("ZAddXX on a missing key should not take effect")
+	}
+	if cache.Exists("test") {
+		t.Error("ZAddXX should not create the key")
+	}
+
+	if !cache.ZAddNX("test", "a", big.NewRat(1, 1)) {
+		t.Error("ZAddNX should add an absent member")
+	}
+	if cache.ZAddNX("test", "a", big.NewRat(5, 1)) {
+		t.Error("ZAddNX should ignore an existing member")
+	}
+	if cache.ZAddNX("test", "a", big.NewRat(1, 1)) {
+		t.Error("ZAddNX should ignore an existing member with the same score")
+	}
+	if score, _ := cache.ZScore("test", "a"); score.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("score after ZAddNX = %v, want 1", score)
+	}
+
+	if cache.ZAddXX("test", "b", big.NewRat(2, 1)) {
+		t.Error("ZAddXX should ignore an absent member")
+	}
+	if _, ok := cache.ZScore("test", "b"); ok {
+		t.Error("ZAddXX should not add b")
+	}
+	if !cache.ZAddXX("test", "a", big.NewRat(7, 1)) {
+		t.Error("ZAddXX should update an existing member")
+	}
+	if !cache.ZAddXX("test", "a", big.NewRat(7, 1)) {
+		t.Error("ZAddXX with an equal score should still report the member as existing")
+	}
+	if score, _ := cache.ZScore("test", "a"); score.Cmp(big.NewRat(7, 1)) != 0 {
+		t.Errorf("score after ZAddXX = %v, want 7", score)
+	}
+	if card, _ := cache.ZCard("test"); card != 1 {
+		t.Errorf("ZCard = %d, want 1", card)
+	}
+}
+
+// TestZScoresByPrefix 测试按成员名前缀批量获取分数
+func TestZScoresByPrefix(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "team:a:alice", 10)
+	cache.ZAddInt64("test", "team:a:bob", 20)
+	cache.ZAddInt64("test", "team:b:carol", 30)
+	cache.ZAddInt64("test", "solo:dave", 40)
+
+	got := cache.ZScoresByPrefix("test", "team:a:")
+	want := map[string]int64{"team:a:alice": 10, "team:a:bob": 20}
+	if len(got) != len(want) {
+		t.Fatalf("ZScoresByPrefix = %v, want %v", got, want)
+	}
+	for member, score := range want {
+		if got[member] == nil || got[member].Cmp(big.NewRat(score, 1)) != 0 {
+			t.Errorf("score[%s] = %v, want %d", member, got[member], score)
+		}
+	}
+
+	if got := cache.ZScoresByPrefix("test", "team:"); len(got) != 3 {
+		t.Errorf("prefix team: matched %d members, want 3", len(got))
+	}
+	if got := cache.ZScoresByPrefix("test", "none:"); got == nil || len(got) != 0 {
+		t.Errorf("unmatched prefix = %v, want empty map", got)
+	}
+	if cache.ZScoresByPrefix("missing", "") != nil {
+		t.Error("missing key should return nil")
+	}
+}
+
+// TestZAddCH 测试批量添加返回变化的成员数
+func TestZAddCH(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "same", 1)
+	cache.ZAddInt64("test", "changed", 2)
+
+	got := cache.ZAddCH("test", map[string]*big.Rat{
+		"same":    big.NewRat(1, 1),
+		"changed": big.NewRat(5, 1),
+		"new1":    big.NewRat(3, 1),
+		"new2":    big.NewRat(4, 1),
+	})
+	if got != 3 {
+		t.Errorf("ZAddCH = %d, want 3", got)
+	}
+	if card, _ := cache.ZCard("test"); card != 4 {
+		t.Errorf("ZCard = %d, want 4", card)
+	}
+	if score, _ := cache.ZScore("test", "changed"); score.Cmp(big.NewRat(5, 1)) != 0 {
+		t.Errorf("changed score = %v, want 5", score)
+	}
+
+	// 全部相同时没有变化
+	if got := cache.ZAddCH("test", map[string]*big.Rat{"same": big.NewRat(1, 1)}); got != 0 {
+		t.Errorf("ZAddCH with identical scores = %d, want 0", got)
+	}
+}
+
+// TestZRangeByScoreProfiled 测试窄范围查询在大集合上只访问有限节点
+func TestZRangeByScoreProfiled(t *testing.T) {
+	cache := New()
+	const n = 100000
+	members := make(map[string]*big.Rat, n)
+	for i := 0; i < n; i++ {
+		members[fmt.Sprintf("m%d", i)] = big.NewRat(int64(i), 1)
+	}
+	cache.ZAddMultiple("big", members)
+
+	result, stats := cache.ZRangeByScoreProfiled("big", big.NewRat(50000, 1), big.NewRat(50009, 1))
+	if len(result) != 10 || result[0].Member != "m50000" || result[9].Member != "m50009" {
+		t.Fatalf("result = %v, want m50000..m50009", result)
+	}
+	if stats.LevelsDescended == 0 {
+		t.Error("LevelsDescended should be reported")
+	}
+	// 期望复杂度约为 O(log n + k)，远小于全表扫描
+	if stats.NodesVisited > 1000 {
+		t.Errorf("NodesVisited = %d, want a bounded scan for a narrow range", stats.NodesVisited)
+	}
+
+	if result, stats := cache.ZRangeByScoreProfiled("missing", big.NewRat(0, 1), big.NewRat(1, 1)); result != nil || stats.NodesVisited != 0 {
+		t.Errorf("missing key = %v, %+v, want nil and zero stats", result, stats)
+	}
+}
+
+// TestZRankMulti 测试批量获取排名与逐个 ZRank 一致
+func TestZRankMulti(t *testing.T) {
+	cache := New()
+	const n = 10000
+	members := make(map[string]*big.Rat, n)
+	for i := 0; i < n; i++ {
+		members[fmt.Sprintf("m%d", i)] = big.NewRat(int64(rand.IntN(1000)), 1)
+	}
+	cache.ZAddMultiple("lb", members)
+
+	query := make([]string, 0, 52)
+	for i := 0; i < 50; i++ {
+		query = append(query, fmt.Sprintf("m%d", rand.IntN(n)))
+	}
+	query = append(query, "absent1", "absent2")
+
+	ranks := cache.ZRankMulti("lb", query)
+	for _, member := range query {
+		want, ok := cache.ZRank("lb", member)
+		got, found := ranks[member]
+		if ok != found || (ok && got != want) {
+			t.Errorf("ZRankMulti[%s] = %d, %v, want %d, %v", member, got, found, want, ok)
+		}
+	}
+	if _, ok := ranks["absent1"]; ok {
+		t.Error("absent members should be omitted")
+	}
+	if cache.ZRankMulti("missing", query) != nil {
+		t.Error("missing key should return nil")
+	}
+}
+
+// TestZRangeAround 测试获取成员前后相邻的排名窗口
+func TestZRangeAround(t *testing.T) {
+	cache := New()
+	for i := 0; i < 10; i++ {
+		cache.ZAddInt64("lb", fmt.Sprintf("p%d", i), int64(i*10))
+	}
+
+	cases := []struct {
+		member        string
+		before, after int
+		want          []interface{}
+		rank          int
+	}{
+		{"p5", 2, 2, []interface{}{"p3", "p4", "p5", "p6", "p7"}, 5},
+		{"p0", 2, 2, []interface{}{"p0", "p1", "p2"}, 0},
+		{"p9", 2, 2, []interface{}{"p7", "p8", "p9"}, 9},
+		{"p1", 3, 1, []interface{}{"p0", "p1", "p2"}, 1},
+		{"p4", 0, 0, []interface{}{"p4"}, 4},
+	}
+	for _, tc := range cases {
+		got, rank, ok := cache.ZRangeAround("lb", tc.member, tc.before, tc.after, false)
+		if !ok || rank != tc.rank {
+			t.Errorf("ZRangeAround(%s) rank = %d, %v, want %d, true", tc.member, rank, ok, tc.rank)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("ZRangeAround(%s, %d, %d) = %v, want %v", tc.member, tc.before, tc.after, got, tc.want)
+		}
+	}
+
+	withScores, _, _ := cache.ZRangeAround("lb", "p9", 1, 1, true)
+	if len(withScores) != 4 || withScores[0] != "p8" || withScores[3] != cache.formatScore(big.NewRat(90, 1)) {
+		t.Errorf("withScores = %v, want [p8 80 p9 90]", withScores)
+	}
+
+	if _, rank, ok := cache.ZRangeAround("lb", "missing", 1, 1, false); ok || rank != -1 {
+		t.Errorf("missing member = %d, %v, want -1, false", rank, ok)
+	}
+}
+
+// TestZPopByScore 测试按分数阈值弹出到期成员
+func TestZPopByScore(t *testing.T) {
+	cache := New()
+	for i := 1; i <= 5; i++ {
+		cache.ZAddInt64("queue", fmt.Sprintf("job%d", i), int64(i*10))
+	}
+
+	got := cache.ZPopByScore("queue", big.NewRat(30, 1), 2)
+	if len(got) != 2 || got[0].Member != "job1" || got[1].Member != "job2" {
+		t.Errorf("limited pop = %v, want [job1 job2]", got)
+	}
+	got = cache.ZPopByScore("queue", big.NewRat(30, 1), 0)
+	if len(got) != 1 || got[0].Member != "job3" {
+		t.Errorf("unlimited pop = %v, want [job3]", got)
+	}
+	if got := cache.ZPopByScore("queue", big.NewRat(30, 1), 0); got != nil {
+		t.Errorf("pop with nothing due = %v, want nil", got)
+	}
+	if card, _ := cache.ZCard("queue"); card != 2 {
+		t.Errorf("ZCard = %d, want 2", card)
+	}
+}
+
+// TestZPopByScoreConcurrent 测试两个消费者并发弹出时不会重复且不遗漏到期成员
+func TestZPopByScoreConcurrent(t *testing.T) {
+	cache := New()
+	const n = 2000
+	for i := 0; i < n; i++ {
+		cache.ZAddInt64("queue", fmt.Sprintf("job%d", i), int64(i))
+	}
+	due := big.NewRat(n/2, 1) // job0..job1000 到期
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	var wg sync.WaitGroup
+	for w := 0; w < 2; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				batch := cache.ZPopByScore("queue", due, 7)
+				if len(batch) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, sm := range batch {
+					seen[sm.Member]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != n/2+1 {
+		t.Errorf("popped %d distinct members, want %d", len(seen), n/2+1)
+	}
+	for member, times := range seen {
+		if times != 1 {
+			t.Errorf("member %s popped %d times", member, times)
+		}
+	}
+	if left := cache.ZCount("queue", big.NewRat(0, 1), due); left != 0 {
+		t.Errorf("%d due members left after popping", left)
+	}
+}
+
+// TestMemUsage 测试内存估算随成员增减单调变化
+func TestMemUsage(t *testing.T) {
+	cache := New()
+	if got := cache.ZMemUsage("lb"); got != 0 {
+		t.Errorf("ZMemUsage on missing key = %d, want 0", got)
+	}
+
+	prev := int64(0)
+	for i := 0; i < 200; i++ {
+		cache.ZAddInt64("lb", fmt.Sprintf("member%d", i), int64(i))
+		usage := cache.ZMemUsage("lb")
+		if usage <= prev {
+			t.Fatalf("usage after %d adds = %d, want > %d", i+1, usage, prev)
+		}
+		prev = usage
+	}
+
+	// 更大的分数与更长的成员名占用更多内存
+	cache.ZAddInt64("small", "a", 1)
+	cache.ZAdd("big", strings.Repeat("a", 100), new(big.Rat).SetFrac(new(big.Int).Lsh(big.NewInt(1), 512), big.NewInt(3)))
+	if cache.ZMemUsage("big") <= cache.ZMemUsage("small") {
+		t.Error("longer member and larger score should use more memory")
+	}
+
+	cache.ZAddInt64("other", "x", 1)
+	if total := cache.MemUsage(); total < cache.ZMemUsage("lb")+cache.ZMemUsage("other") {
+		t.Errorf("MemUsage = %d, want at least the sum of its keys", total)
+	}
+
+	for i := 0; i < 200; i += 2 {
+		cache.ZRem("lb", fmt.Sprintf("member%d", i))
+		usage := cache.ZMemUsage("lb")
+		if usage >= prev {
+			t.Fatalf("usage after removal = %d, want < %d", usage, prev)
+		}
+		prev = usage
+	}
+}
+
+// TestZStats 测试跳表统计信息反映非退化的层级分布
+func TestZStats(t *testing.T) {
+	cache := New()
+	const n = 50000
+	members := make(map[string]*big.Rat, n)
+	for i := 0; i < n; i++ {
+		members[strconv.Itoa(i)] = big.NewRat(int64(i), 1)
+	}
+	cache.ZAddMultiple("big", members)
+
+	stats, ok := cache.ZStats("big")
+	if !ok {
+		t.Fatal("ZStats should find the key")
+	}
+	if stats.Length != n || stats.LevelCounts[0] != n {
+		t.Errorf("Length = %d, LevelCounts[0] = %d, want %d", stats.Length, stats.LevelCounts[0], n)
+	}
+	if stats.Level < 5 || len(stats.LevelCounts) != stats.Level {
+		t.Errorf("Level = %d with %d level counts, want >= 5 levels", stats.Level, len(stats.LevelCounts))
+	}
+	for i := 1; i < len(stats.LevelCounts); i++ {
+		if stats.LevelCounts[i] > stats.LevelCounts[i-1] {
+			t.Errorf("LevelCounts not decreasing: %v", stats.LevelCounts)
+			break
+		}
+	}
+	// p = 0.25 时平均层级约为 1/(1-p) ≈ 1.33
+	if stats.AvgLevel < 1.2 || stats.AvgLevel > 1.5 {
+		t.Errorf("AvgLevel = %.3f, want about 1.33", stats.AvgLevel)
+	}
+
+	if _, ok := cache.ZStats("missing"); ok {
+		t.Error("ZStats on a missing key should return false")
+	}
+}
+
+// TestZAddBatch 测试按顺序批量添加，重复成员以最后一次为准
+func TestZAddBatch(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "existing", 1)
+
+	added := cache.ZAddBatch("test", []ScoreMember{
+		{Member: "a", Score: big.NewRat(5, 1)},
+		{Member: "existing", Score: big.NewRat(7, 1)},
+		{Member: "b", Score: big.NewRat(2, 1)},
+		{Member: "a", Score: big.NewRat(9, 1)},
+	})
+	if added != 2 {
+		t.Errorf("ZAddBatch added %d, want 2", added)
+	}
+	if score, _ := cache.ZScore("test", "a"); score.Cmp(big.NewRat(9, 1)) != 0 {
+		t.Errorf("a = %v, want 9 (last writer wins)", score)
+	}
+	if score, _ := cache.ZScore("test", "existing"); score.Cmp(big.NewRat(7, 1)) != 0 {
+		t.Errorf("existing = %v, want 7", score)
+	}
+	if card, _ := cache.ZCard("test"); card != 3 {
+		t.Errorf("ZCard = %d, want 3", card)
+	}
+
+	// 直接写入 All 的结果以复制数据（含附带数据）
+	cache.ZAddWithValue("test", "b", big.NewRat(2, 1), []byte("v"))
+	if added := cache.ZAddBatch("copy", cache.getZSet("test").sl.All()); added != 3 {
+		t.Errorf("copy added %d, want 3", added)
+	}
+	if value, _ := cache.ZGetValue("copy", "b"); string(value) != "v" {
+		t.Errorf("copied value = %q, want v", value)
+	}
+	if added := cache.ZAddBatch("test", nil); added != 0 {
+		t.Errorf("empty batch added %d, want 0", added)
+	}
+}
+
+// TestZWeightedSample 测试加权抽样的频率与分数成比例
+func TestZWeightedSample(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "a", 1)
+	cache.ZAddInt64("test", "b", 3)
+	cache.ZAddInt64("test", "c", 6)
+	cache.ZAddInt64("test", "zero", 0)
+
+	const draws = 100000
+	got, err := cache.ZWeightedSample("test", draws)
+	if err != nil {
+		t.Fatalf("ZWeightedSample error: %v", err)
+	}
+	if len(got) != draws {
+		t.Fatalf("ZWeightedSample returned %d members, want %d", len(got), draws)
+	}
+	counts := make(map[string]int)
+	for _, m := range got {
+		counts[m]++
+	}
+	if counts["zero"] != 0 {
+		t.Errorf("zero-score member drawn %d times", counts["zero"])
+	}
+	for member, weight := range map[string]float64{"a": 0.1, "b": 0.3, "c": 0.6} {
+		freq := float64(counts[member]) / draws
+		if math.Abs(freq-weight) > 0.02 {
+			t.Errorf("frequency of %s = %.3f, want about %.1f", member, freq, weight)
+		}
+	}
+
+	if got, err := cache.ZWeightedSample("missing", 5); got != nil || err != nil {
+		t.Errorf("missing key = %v, %v, want nil, nil", got, err)
+	}
+	cache.ZAddInt64("test", "neg", -1)
+	if _, err := cache.ZWeightedSample("test", 5); !errors.Is(err, ErrNegativeScore) {
+		t.Errorf("negative score error = %v, want ErrNegativeScore", err)
+	}
+}
+
+// TestZUpdateScore 测试在写锁内原子改写分数
+func TestZUpdateScore(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "a", 10)
+	cache.ZAddInt64("test", "b", 3)
+
+	halve := func(old *big.Rat, exists bool) (*big.Rat, bool) {
+		if !exists {
+			return nil, false
+		}
+		return new(big.Rat).Quo(old, big.NewRat(2, 1)), true
+	}
+	if score, ok := cache.ZUpdateScore("test", "a", halve); !ok || score.Cmp(big.NewRat(5, 1)) != 0 {
+		t.Errorf("halve a = %v, %v, want 5, true", score, ok)
+	}
+	if score, ok := cache.ZUpdateScore("test", "b", halve); !ok || score.Cmp(big.NewRat(3, 2)) != 0 {
+		t.Errorf("halve b = %v, %v, want 3/2, true", score, ok)
+	}
+	if score, ok := cache.ZUpdateScore("test", "missing", halve); ok || score != nil {
+		t.Errorf("halve missing = %v, %v, want nil, false", score, ok)
+	}
+
+	// 低于阈值时删除
+	threshold := big.NewRat(2, 1)
+	dropBelow := func(old *big.Rat, exists bool) (*big.Rat, bool) {
+		if !exists || old.Cmp(threshold) >= 0 {
+			return nil, false
+		}
+		return nil, true
+	}
+	if score, ok := cache.ZUpdateScore("test", "a", dropBelow); !ok || score.Cmp(big.NewRat(5, 1)) != 0 {
+		t.Errorf("dropBelow a = %v, %v, want 5, true (unchanged)", score, ok)
+	}
+	if score, ok := cache.ZUpdateScore("test", "b", dropBelow); ok || score != nil {
+		t.Errorf("dropBelow b = %v, %v, want nil, false", score, ok)
+	}
+	if _, ok := cache.ZScore("test", "b"); ok {
+		t.Error("b should have been removed")
+	}
+
+	// 不存在的 key 在放弃写入或删除不存在的成员后不会被创建
+	calls := 0
+	if score, ok := cache.ZUpdateScore("nokey", "x", func(*big.Rat, bool) (*big.Rat, bool) {
+		calls++
+		return nil, false
+	}); ok || score != nil {
+		t.Errorf("no-op on missing key = %v, %v, want nil, false", score, ok)
+	}
+	cache.ZUpdateScore("nokey", "x", dropBelow)
+	cache.ZUpdateScore("nokey", "x", func(*big.Rat, bool) (*big.Rat, bool) { return nil, true })
+	if calls != 1 || cache.Exists("nokey") {
+		t.Errorf("calls = %d, Exists(nokey) = %v, want 1, false", calls, cache.Exists("nokey"))
+	}
+	calls = 0
+	if score, ok := cache.ZUpdateScore("new", "x", func(*big.Rat, bool) (*big.Rat, bool) {
+		calls++
+		return big.NewRat(7, 1), true
+	}); !ok || score.Cmp(big.NewRat(7, 1)) != 0 || calls != 1 {
+		t.Errorf("create = %v, %v after %d calls, want 7, true after 1 call", score, ok, calls)
+	}
+}
+
+// TestZUpdateScoreConcurrent 测试并发改写同一成员时不会丢失更新
+func TestZUpdateScoreConcurrent(t *testing.T) {
+	cache := New()
+	const workers, perWorker = 8, 200
+	increment := func(old *big.Rat, exists bool) (*big.Rat, bool) {
+		if !exists {
+			return big.NewRat(1, 1), true
+		}
+		return new(big.Rat).Add(old, big.NewRat(1, 1)), true
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				cache.ZUpdateScore("test", "counter", increment)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if score, _ := cache.ZScore("test", "counter"); score.Cmp(big.NewRat(workers*perWorker, 1)) != 0 {
+		t.Errorf("counter = %v, want %d", score, workers*perWorker)
+	}
+}
+
+// TestZRescale 测试将分数线性映射到目标范围
+func TestZRescale(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "low", 10)
+	cache.ZAddInt64("test", "mid", 40)
+	cache.ZAddInt64("test", "high", 110)
+	cache.ZAddInt64("test", "tie", 40)
+
+	if !cache.ZRescale("test", big.NewRat(0, 1), big.NewRat(1, 1)) {
+		t.Fatal("ZRescale should succeed")
+	}
+	want := map[string]*big.Rat{
+		"low":  big.NewRat(0, 1),
+		"mid":  big.NewRat(3, 10),
+		"tie":  big.NewRat(3, 10),
+		"high": big.NewRat(1, 1),
+	}
+	for member, w := range want {
+		if score, _ := cache.ZScore("test", member); score.Cmp(w) != 0 {
+			t.Errorf("%s = %v, want %v", member, score, w)
+		}
+	}
+	got := cache.ZRange("test", 0, -1, false)
+	if fmt.Sprint(got) != "[low mid tie high]" {
+		t.Errorf("order after rescale = %v, want [low mid tie high]", got)
+	}
+	checkSkipListInvariants(t, cache.getZSet("test").sl)
+
+	// 所有分数相等时映射为 newMin
+	cache.ZAddInt64("flat", "a", 5)
+	cache.ZAddInt64("flat", "b", 5)
+	cache.ZRescale("flat", big.NewRat(-1, 1), big.NewRat(1, 1))
+	for _, member := range []string{"a", "b"} {
+		if score, _ := cache.ZScore("flat", member); score.Cmp(big.NewRat(-1, 1)) != 0 {
+			t.Errorf("flat %s = %v, want -1", member, score)
+		}
+	}
+
+	// 目标范围退化为一个点时按成员名重新排列
+	cache.ZAddInt64("point", "z", 1)
+	cache.ZAddInt64("point", "a", 2)
+	cache.ZRescale("point", big.NewRat(7, 1), big.NewRat(7, 1))
+	if got := cache.ZRange("point", 0, -1, false); fmt.Sprint(got) != "[a z]" {
+		t.Errorf("order after collapsing = %v, want [a z]", got)
+	}
+	checkSkipListInvariants(t, cache.getZSet("point").sl)
+
+	if cache.ZRescale("test", big.NewRat(1, 1), big.NewRat(0, 1)) {
+		t.Error("ZRescale with newMin > newMax should fail")
+	}
+	if cache.ZRescale("missing", big.NewRat(0, 1), big.NewRat(1, 1)) {
+		t.Error("ZRescale on a missing key should fail")
+	}
+}
+
+// TestZTopK 测试 ZTopK、ZBottomK 与 ZRevRange、ZRange 的结果一致
+func TestZTopK(t *testing.T) {
+	cache := New()
+	for i := 0; i < 100; i++ {
+		cache.ZAddInt64("test", "m"+strconv.Itoa(i), int64(i%37))
+	}
+
+	for _, k := range []int{1, 10, 100, 150} {
+		for _, withScores := range []bool{false, true} {
+			if got, want := cache.ZTopK("test", k, withScores), cache.ZRevRange("test", 0, k-1, withScores); !reflect.DeepEqual(got, want) {
+				t.Errorf("ZTopK(%d, %v) = %v, want %v", k, withScores, got, want)
+			}
+			if got, want := cache.ZBottomK("test", k, withScores), cache.ZRange("test", 0, k-1, withScores); !reflect.DeepEqual(got, want) {
+				t.Errorf("ZBottomK(%d, %v) = %v, want %v", k, withScores, got, want)
+			}
+		}
+	}
+	if got := cache.ZTopK("test", 0, false); got != nil {
+		t.Errorf("ZTopK(0) = %v, want nil", got)
+	}
+	if got := cache.ZBottomK("missing", 5, false); got != nil {
+		t.Errorf("ZBottomK on a missing key = %v, want nil", got)
+	}
+}
+
+// TestZIncrByWrappers 测试 int64、float64 与字符串增量的便捷方法
+func TestZIncrByWrappers(t *testing.T) {
+	cache := New()
+
+	// 整数增量精确累加，超出 float64 精确表示的范围也不丢失
+	cache.ZIncrByInt64("test", "int", math.MaxInt64)
+	cache.ZIncrByInt64("test", "int", 1)
+	want, _ := new(big.Rat).SetString("9223372036854775808")
+	if score, _ := cache.ZScore("test", "int"); score.Cmp(want) != 0 {
+		t.Errorf("int = %v, want %v", score, want)
+	}
+
+	// float64 增量按其二进制值精确转换：0.1 + 0.2 的结果与 SetFloat64 逐个相加一致
+	cache.ZIncrByFloat64("test", "float", 0.1)
+	cache.ZIncrByFloat64("test", "float", 0.2)
+	exact := new(big.Rat).Add(new(big.Rat).SetFloat64(0.1), new(big.Rat).SetFloat64(0.2))
+	if score, _ := cache.ZScore("test", "float"); score.Cmp(exact) != 0 {
+		t.Errorf("float = %v, want %v", score.RatString(), exact.RatString())
+	}
+	if _, ok := cache.ZIncrByFloat64("test", "float", math.Inf(1)); ok {
+		t.Error("ZIncrByFloat64 with +Inf should fail")
+	}
+
+	if _, ok, err := cache.ZIncrByString("test", "str", "1/3"); !ok || err != nil {
+		t.Fatalf("ZIncrByString = %v, %v", ok, err)
+	}
+	cache.ZIncrByString("test", "str", "2/3")
+	if score, _ := cache.ZScore("test", "str"); score.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("str = %v, want 1", score)
+	}
+	if _, ok, err := cache.ZIncrByString("test", "str", "abc"); ok || !errors.Is(err, ErrInvalidScore) {
+		t.Errorf("ZIncrByString(abc) = %v, %v, want false, ErrInvalidScore", ok, err)
+	}
+}
+
+// TestZPopOne 测试单个弹出与 count <= 0 时 ZPopMin 的行为
+func TestZPopOne(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("test", "a", 1)
+	cache.ZAddInt64("test", "b", 2)
+	cache.ZAddInt64("test", "c", 3)
+
+	for _, count := range []int{0, -1} {
+		if got := cache.ZPopMin("test", count); got != nil {
+			t.Errorf("ZPopMin(%d) = %v, want nil", count, got)
+		}
+		if got := cache.ZPopMax("test", count); got != nil {
+			t.Errorf("ZPopMax(%d) = %v, want nil", count, got)
+		}
+	}
+	if card, _ := cache.ZCard("test"); card != 3 {
+		t.Fatalf("ZCard after zero-count pops = %d, want 3", card)
+	}
+
+	if sm, ok := cache.ZPopMinOne("test"); !ok || sm.Member != "a" || sm.Score.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("ZPopMinOne = %v, %v, want a:1", sm, ok)
+	}
+	if sm, ok := cache.ZPopMaxOne("test"); !ok || sm.Member != "c" || sm.Score.Cmp(big.NewRat(3, 1)) != 0 {
+		t.Errorf("ZPopMaxOne = %v, %v, want c:3", sm, ok)
+	}
+	if sm, ok := cache.ZPopMaxOne("test"); !ok || sm.Member != "b" {
+		t.Errorf("ZPopMaxOne = %v, %v, want b", sm, ok)
+	}
+	if _, ok := cache.ZPopMinOne("test"); ok {
+		t.Error("ZPopMinOne on an empty set should return false")
+	}
+	if _, ok := cache.ZPopMaxOne("missing"); ok {
+		t.Error("ZPopMaxOne on a missing key should return false")
+	}
+}
+
+// TestZPopOneConcurrent 测试多个协程并发单个弹出时每个成员恰好被交付一次
+func TestZPopOneConcurrent(t *testing.T) {
+	cache := New()
+	const n, workers = 2000, 8
+	for i := 0; i < n; i++ {
+		cache.ZAddInt64("test", "m"+strconv.Itoa(i), int64(i))
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		received = make(map[string]int, n)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			pop := cache.ZPopMinOne
+			if w%2 == 1 {
+				pop = cache.ZPopMaxOne
+			}
+			for {
+				sm, ok := pop("test")
+				if !ok {
+					return
+				}
+				mu.Lock()
+				received[sm.Member]++
+				mu.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if len(received) != n {
+		t.Fatalf("received %d distinct members, want %d", len(received), n)
+	}
+	for member, count := range received {
+		if count != 1 {
+			t.Errorf("member %s delivered %d times", member, count)
+		}
+	}
+}
+
+// TestZTrim 测试只保留最高或最低的 n 个成员
+func TestZTrim(t *testing.T) {
+	cache := New()
+	for i := 1; i <= 10; i++ {
+		cache.ZAddInt64("top", "m"+strconv.Itoa(i), int64(i))
+		cache.ZAddInt64("bottom", "m"+strconv.Itoa(i), int64(i))
+	}
+
+	if removed := cache.ZTrim("top", 3, true); removed != 7 {
+		t.Errorf("ZTrim(top, 3, true) removed %d, want 7", removed)
+	}
+	if got := cache.ZRange("top", 0, -1, false); fmt.Sprint(got) != "[m8 m9 m10]" {
+		t.Errorf("top after trim = %v, want [m8 m9 m10]", got)
+	}
+	if removed := cache.ZTrim("bottom", 3, false); removed != 7 {
+		t.Errorf("ZTrim(bottom, 3, false) removed %d, want 7", removed)
+	}
+	if got := cache.ZRange("bottom", 0, -1, false); fmt.Sprint(got) != "[m1 m2 m3]" {
+		t.Errorf("bottom after trim = %v, want [m1 m2 m3]", got)
+	}
+
+	// 已经不超过 n 个成员时不做修改
+	if removed := cache.ZTrim("top", 5, true); removed != 0 {
+		t.Errorf("ZTrim on a small set removed %d, want 0", removed)
+	}
+	if card, _ := cache.ZCard("top"); card != 3 {
+		t.Errorf("ZCard after no-op trim = %d, want 3", card)
+	}
+
+	if removed := cache.ZTrim("top", 0, true); removed != 3 {
+		t.Errorf("ZTrim(0) removed %d, want 3", removed)
+	}
+	if card, _ := cache.ZCard("top"); card != 0 {
+		t.Errorf("ZCard after ZTrim(0) = %d, want 0", card)
+	}
+	if removed := cache.ZTrim("missing", 1, true); removed != 0 {
+		t.Errorf("ZTrim on a missing key removed %d, want 0", removed)
+	}
+}
+
+// TestZCardMulti 测试批量获取基数与批量检查存在
+func TestZCardMulti(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "x", 1)
+	cache.ZAddInt64("a", "y", 2)
+	cache.ZAddInt64("b", "x", 1)
+
+	keys := []string{"a", "missing", "b", "other"}
+	cards := cache.ZCardMulti(keys)
+	if !reflect.DeepEqual(cards, map[string]int{"a": 2, "b": 1}) {
+		t.Errorf("ZCardMulti = %v, want map[a:2 b:1]", cards)
+	}
+	exists := cache.ExistsMulti(keys)
+	want := map[string]bool{"a": true, "missing": false, "b": true, "other": false}
+	if !reflect.DeepEqual(exists, want) {
+		t.Errorf("ExistsMulti = %v, want %v", exists, want)
+	}
+	if got := cache.ZCardMulti(nil); len(got) != 0 {
+		t.Errorf("ZCardMulti(nil) = %v, want empty", got)
+	}
+}
+
+// TestRename 测试重命名移动集合而不复制节点
+func TestRename(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("old", "a", 1)
+	cache.ZAddInt64("old", "b", 2)
+	cache.ZAddInt64("dst", "x", 9)
+	set := cache.getZSet("old")
+	node := set.sl.memberMap["a"]
+
+	if !cache.Rename("old", "dst") {
+		t.Fatal("Rename should succeed")
+	}
+	if cache.Exists("old") {
+		t.Error("old key should no longer exist")
+	}
+	if moved := cache.getZSet("dst"); moved != set || moved.sl.memberMap["a"] != node {
+		t.Error("Rename should move the *ZSet pointer without copying nodes")
+	}
+	if _, ok := cache.ZScore("dst", "x"); ok {
+		t.Error("Rename should replace the previous contents of the new key")
+	}
+
+	// 之后对旧 key 的写入创建新集合，不影响新 key
+	cache.ZAddInt64("old", "c", 3)
+	if card, _ := cache.ZCard("dst"); card != 2 {
+		t.Errorf("dst ZCard = %d, want 2", card)
+	}
+	if card, _ := cache.ZCard("old"); card != 1 {
+		t.Errorf("old ZCard = %d, want 1", card)
+	}
+
+	// oldKey 与 newKey 相同
+	if !cache.Rename("dst", "dst") {
+		t.Error("Rename to the same existing key should return true")
+	}
+	if card, _ := cache.ZCard("dst"); card != 2 {
+		t.Errorf("dst ZCard after self-rename = %d, want 2", card)
+	}
+	if cache.Rename("missing", "dst") {
+		t.Error("Rename of a missing key should fail")
+	}
+}
+
+// TestRenameNX 测试 RenameNX 不覆盖已存在的 key
+func TestRenameNX(t *testing.T) {
+	cache := New()
+	cache.ZAddInt64("a", "x", 1)
+	cache.ZAddInt64("b", "y", 2)
+
+	if cache.RenameNX("a", "b") {
+		t.Error("RenameNX should refuse to overwrite an existing key")
+	}
+	if _, ok := cache.ZScore("b", "y"); !ok {
+		t.Error("b should be unchanged after a refused RenameNX")
+	}
+	if _, ok := cache.ZScore("a", "x"); !ok {
+		t.Error("a should be unchanged after a refused RenameNX")
+	}
+	if cache.RenameNX("a", "a") {
+		t.Error("RenameNX to the same key should return false")
+	}
+	if !cache.RenameNX("a", "c") {
+		t.Fatal("RenameNX to a new key should succeed")
+	}
+	if _, ok := cache.ZScore("c", "x"); !ok || cache.Exists("a") {
+		t.Error("RenameNX should move a to c")
+	}
+}
+
+// TestRenameKeepsTTL 测试重命名保留过期时间
+func TestRenameKeepsTTL(t *testing.T) {
+	clock := newFakeClock()
+	cache := New(WithClock(clock.Now))
+	cache.ZAddInt64("a", "x", 1)
+	cache.Expire("a", time.Minute)
+
+	cache.Rename("a", "b")
+	if ttl, ok := cache.TTL("b"); !ok || ttl != time.Minute {
+		t.Errorf("TTL after rename = %v, %v, want 1m", ttl, ok)
+	}
+	clock.Advance(2 * time.Minute)
+	if cache.Exists("b") {
+		t.Error("renamed key should still expire")
+	}
+}
+
+// TestDBSize 测试 key 数量统计
+func TestDBSize(t *testing.T) {
+	clock := newFakeClock()
+	cache := New(WithClock(clock.Now))
+	if n := cache.DBSize(); n != 0 {
+		t.Errorf("DBSize of an empty cache = %d, want 0", n)
+	}
+	for i := 0; i < 50; i++ {
+		cache.ZAddInt64("key"+strconv.Itoa(i), "m", 1)
+	}
+	cache.Del("key0")
+	cache.Expire("key1", time.Second)
+	clock.Advance(time.Minute)
+	if n := cache.DBSize(); n != 48 || n != len(cache.Keys()) {
+		t.Errorf("DBSize = %d, want 48 (Keys has %d)", n, len(cache.Keys()))
+	}
+	if allocs := testing.AllocsPerRun(100, func() { cache.DBSize() }); allocs != 0 {
+		t.Errorf("DBSize allocated %.0f times, want 0", allocs)
+	}
+}
+
+// TestScanKeys 测试以游标遍历全部 key
+func TestScanKeys(t *testing.T) {
+	cache := New()
+	const n = 500
+	for i := 0; i < n; i++ {
+		prefix := "user:"
+		if i%2 == 1 {
+			prefix = "item:"
+		}
+		cache.ZAddInt64(prefix+strconv.Itoa(i), "m", 1)
+	}
+
+	scanAll := func(match string) map[string]int {
+		seen := make(map[string]int)
+		var cursor uint64
+		for {
+			next, keys := cache.ScanKeys(cursor, match, 37)
+			for _, key := range keys {
+				seen[key]++
+			}
+			if next == 0 {
+				return seen
+			}
+			cursor = next
+		}
+	}
+
+	seen := scanAll("")
+	if len(seen) != n {
+		t.Fatalf("ScanKeys returned %d distinct keys, want %d", len(seen), n)
+	}
+	for key, count := range seen {
+		if count != 1 {
+			t.Errorf("key %s returned %d times", key, count)
+		}
+	}
+
+	users := scanAll("user:*")
+	if len(users) != n/2 {
+		t.Errorf("ScanKeys(user:*) returned %d keys, want %d", len(users), n/2)
+	}
+	for key := range users {
+		if !strings.HasPrefix(key, "user:") {
+			t.Errorf("ScanKeys(user:*) returned %s", key)
+		}
+	}
+
+	// 过滤在截取批次之后进行：没有匹配时批次为空，但遍历尚未结束
+	if next, keys := cache.ScanKeys(0, "nomatch:*", 10); len(keys) != 0 || next == 0 {
+		t.Errorf("ScanKeys(nomatch:*) = %d, %v, want an empty batch with next != 0", next, keys)
+	}
+}
+
+// TestZQuantile 测试分位数查询
+func TestZQuantile(t *testing.T) {
+	cache := New()
+	for i := 1; i <= 100; i++ {
+		cache.ZAddInt64("test", "m"+strconv.Itoa(i), int64(i))
+	}
+
+	cases := []struct {
+		q    float64
+		want int64
+	}{
+		{0, 1}, {0.01, 1}, {0.07, 7}, {0.5, 50}, {0.95, 95}, {0.951, 96}, {1, 100},
+	}
+	for _, tc := range cases {
+		if score, ok := cache.ZQuantile("test", tc.q); !ok || score.Cmp(big.NewRat(tc.want, 1)) != 0 {
+			t.Errorf("ZQuantile(%v) = %v, %v, want %d", tc.q, score, ok, tc.want)
+		}
+	}
+
+	got := cache.ZQuantiles("test", []float64{0.5, -0.1, 0.95, math.NaN(), 1.5})
+	if len(got) != 5 || got[0].Cmp(big.NewRat(50, 1)) != 0 || got[2].Cmp(big.NewRat(95, 1)) != 0 {
+		t.Fatalf("ZQuantiles = %v, want [50 nil 95 nil nil]", got)
+	}
+	if got[1] != nil || got[3] != nil || got[4] != nil {
+		t.Errorf("invalid quantiles should be nil, got %v", got)
+	}
+
+	if _, ok := cache.ZQuantile("test", 1.01); ok {
+		t.Error("ZQuantile(1.01) should fail")
+	}
+	if _, ok := cache.ZQuantile("missing", 0.5); ok {
+		t.Error("ZQuantile on a missing key should fail")
+	}
+	cache.ZAddInt64("empty", "x", 1)
+	cache.ZRem("empty", "x")
+	if got := cache.ZQuantiles("empty", []float64{0.5}); got != nil {
+		t.Errorf("ZQuantiles on an empty set = %v, want nil", got)
+	}
+}
+
+// TestZHistogram 测试按区间统计成员数量
+func TestZHistogram(t *testing.T) {
+	cache := New()
+	for i := 0; i < 100; i++ {
+		cache.ZAddInt64("test", "m"+strconv.Itoa(i), int64(i))
+	}
+
+	edges := []*big.Rat{big.NewRat(25, 1), big.NewRat(50, 1), big.NewRat(75, 1)}
+	counts, err := cache.ZHistogram("test", edges)
+	if err != nil {
+		t.Fatalf("ZHistogram error: %v", err)
+	}
+	if !reflect.DeepEqual(counts, []int{25, 25, 25, 25}) {
+		t.Errorf("ZHistogram = %v, want [25 25 25 25]", counts)
+	}
+
+	// 上溢与下溢
+	counts, _ = cache.ZHistogram("test", []*big.Rat{big.NewRat(-10, 1), big.NewRat(199, 2)})
+	if !reflect.DeepEqual(counts, []int{0, 100, 0}) {
+		t.Errorf("ZHistogram with wide edges = %v, want [0 100 0]", counts)
+	}
+	counts, _ = cache.ZHistogram("test", nil)
+	if !reflect.DeepEqual(counts, []int{100}) {
+		t.Errorf("ZHistogram without edges = %v, want [100]", counts)
+	}
+	counts, _ = cache.ZHistogram("missing", edges)
+	if !reflect.DeepEqual(counts, []int{0, 0, 0, 0}) {
+		t.Errorf("ZHistogram on a missing key = %v, want all zero", counts)
+	}
+
+	for _, bad := range [][]*big.Rat{
+		{big.NewRat(50, 1), big.NewRat(25, 1)},
+		{big.NewRat(25, 1), big.NewRat(25, 1)},
+	} {
+		if _, err := cache.ZHistogram("test", bad); !errors.Is(err, ErrUnsortedEdges) {
+			t.Errorf("ZHistogram(%v) error = %v, want ErrUnsortedEdges", bad, err)
+		}
+	}
+}
+
+// TestZAggregate 测试精确的总和、最值与平均值
+func TestZAggregate(t *testing.T) {
+	cache := New()
+	scores := []string{"1/3", "0.1", "-12345678901234567890.000000000000000000001"}
+	for i, str := range scores {
+		cache.ZAddString("test", "m"+strconv.Itoa(i), str)
+	}
+
+	sum, min, max, mean, count, ok := cache.ZAggregate("test")
+	if !ok || count != 3 {
+		t.Fatalf("ZAggregate ok = %v, count = %d, want true, 3", ok, count)
+	}
+	wantSum := new(big.Rat)
+	for _, str := range scores {
+		r, _ := new(big.Rat).SetString(str)
+		wantSum.Add(wantSum, r)
+	}
+	if sum.Cmp(wantSum) != 0 {
+		t.Errorf("sum = %s, want %s", sum.RatString(), wantSum.RatString())
+	}
+	if wantMean := new(big.Rat).Quo(wantSum, big.NewRat(3, 1)); mean.Cmp(wantMean) != 0 {
+		t.Errorf("mean = %s, want %s", mean.RatString(), wantMean.RatString())
+	}
+	wantMin, _ := new(big.Rat).SetString(scores[2])
+	if min.Cmp(wantMin) != 0 || max.Cmp(big.NewRat(1, 3)) != 0 {
+		t.Errorf("min, max = %s, %s, want %s, 1/3", min.RatString(), max.RatString(), wantMin.RatString())
+	}
+
+	// 返回值是副本
+	sum.SetInt64(0)
+	if again, _, _, _, _, _ := cache.ZAggregate("test"); again.Cmp(wantSum) != 0 {
+		t.Error("modifying the returned sum should not affect later results")
+	}
+	if _, _, _, _, _, ok := cache.ZAggregate("missing"); ok {
+		t.Error("ZAggregate on a missing key should return ok = false")
+	}
+}
+
 // BenchmarkZAdd 基准测试添加操作
 func BenchmarkZAdd(b *testing.B) {
 	cache := New()
@@ -514,3 +3147,49 @@ func BenchmarkZScore(b *testing.B) {
 		cache.ZScore("bench", "member")
 	}
 }
+
+// BenchmarkZCardParallel 基准测试并发读取成员数量
+func BenchmarkZCardParallel(b *testing.B) {
+	cache := New()
+	for i := 0; i < 1000; i++ {
+		cache.ZAddInt64("bench", strconv.Itoa(i), int64(i))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			cache.ZCard("bench")
+		}
+	})
+}
+
+// BenchmarkZTopK 基准测试百万成员集合取前 10 名
+func BenchmarkZTopK(b *testing.B) {
+	cache := newTopKBenchCache()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.ZTopK("bench", 10, true)
+	}
+}
+
+// BenchmarkZRevRangeTop10 基准测试用 ZRevRange 取前 10 名，作为 ZTopK 的对照
+func BenchmarkZRevRangeTop10(b *testing.B) {
+	cache := newTopKBenchCache()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.ZRevRange("bench", 0, 9, true)
+	}
+}
+
+// newTopKBenchCache 构建包含一百万个成员的集合
+func newTopKBenchCache() *CacheZSort {
+	cache := New()
+	members := make(map[string]*big.Rat, 1000000)
+	for i := 0; i < 1000000; i++ {
+		members["m"+strconv.Itoa(i)] = big.NewRat(int64(i), 1)
+	}
+	cache.ZAddMultiple("bench", members)
+	return cache
+}